@@ -0,0 +1,55 @@
+package auth
+
+import (
+    "crypto/rand"
+    "crypto/sha256"
+    "encoding/base64"
+    "encoding/hex"
+)
+
+// API key scopes. Each scope includes the ones before it.
+const (
+    ScopeRead  = "read"
+    ScopeWrite = "write"
+    ScopeAdmin = "admin"
+)
+
+// AllScopes are the scopes of interactive users logged in with a token
+var AllScopes = []string{ScopeRead, ScopeWrite, ScopeAdmin}
+
+// apiKeyPrefix marks task API keys so they are easy to recognise in configs and leak scanners
+const apiKeyPrefix = "tk_"
+
+// GenerateAPIKey returns a new random API key along with its public prefix and the hash
+// under which it is stored. The key itself is never stored.
+func GenerateAPIKey() (key, prefix, hash string, err error) {
+    id := make([]byte, 4)
+    secret := make([]byte, 32)
+    if _, err := rand.Read(id); err != nil {
+        return "", "", "", err
+    }
+    if _, err := rand.Read(secret); err != nil {
+        return "", "", "", err
+    }
+
+    prefix = apiKeyPrefix + hex.EncodeToString(id)
+    key = prefix + "_" + base64.RawURLEncoding.EncodeToString(secret)
+    return key, prefix, HashAPIKey(key), nil
+}
+
+// HashAPIKey returns the stored form of key. Keys are long and random, so a fast hash is
+// enough and lets keys be looked up by hash.
+func HashAPIKey(key string) string {
+    sum := sha256.Sum256([]byte(key))
+    return hex.EncodeToString(sum[:])
+}
+
+// HasScope reports whether scopes grant scope, admin implying write and write implying read
+func HasScope(scopes []string, scope string) bool {
+    for _, s := range scopes {
+        if s == scope || s == ScopeAdmin || (s == ScopeWrite && scope == ScopeRead) {
+            return true
+        }
+    }
+    return false
+}
@@ -0,0 +1,194 @@
+package auth
+
+import (
+    "crypto/rand"
+    "crypto/rsa"
+    "errors"
+    "fmt"
+    "log"
+    "os"
+    "strconv"
+    "time"
+    "github.com/golang-jwt/jwt/v5"
+    "golang.org/x/crypto/bcrypt"
+)
+
+// Token kinds, recorded in the typ claim so a refresh token can't be used as an access token
+const (
+    AccessToken  = "access"
+    RefreshToken = "refresh"
+)
+
+// ErrInvalidToken is returned for tokens that are malformed, expired, signed with the
+// wrong key or of the wrong kind
+var ErrInvalidToken = errors.New("invalid token")
+
+// ErrInvalidCredentials is returned when an email and password don't match
+var ErrInvalidCredentials = errors.New("invalid email or password")
+
+// Config selects the signing algorithm, keys and token lifetimes
+type Config struct {
+    // Algorithm is HS256 or RS256
+    Algorithm string
+    // Secret is the HS256 key
+    Secret []byte
+    // PrivateKey signs RS256 tokens and PublicKey verifies them. Without a private key
+    // tokens can be verified but not issued.
+    PrivateKey *rsa.PrivateKey
+    PublicKey  *rsa.PublicKey
+    Issuer     string
+    AccessTTL  time.Duration
+    RefreshTTL time.Duration
+}
+
+// LoadKeys returns cfg with the RS256 keys read from the given PEM files; empty paths are
+// skipped. An HS256 configuration without a secret gets a generated key, so tokens don't
+// survive a restart.
+func (cfg Config) LoadKeys(privateKeyFile, publicKeyFile string) (Config, error) {
+    if privateKeyFile != "" {
+        pem, err := os.ReadFile(privateKeyFile)
+        if err != nil {
+            return cfg, err
+        }
+        if cfg.PrivateKey, err = jwt.ParseRSAPrivateKeyFromPEM(pem); err != nil {
+            return cfg, fmt.Errorf("%s: %w", privateKeyFile, err)
+        }
+    }
+    if publicKeyFile != "" {
+        pem, err := os.ReadFile(publicKeyFile)
+        if err != nil {
+            return cfg, err
+        }
+        if cfg.PublicKey, err = jwt.ParseRSAPublicKeyFromPEM(pem); err != nil {
+            return cfg, fmt.Errorf("%s: %w", publicKeyFile, err)
+        }
+    }
+
+    if cfg.Algorithm == "HS256" && len(cfg.Secret) == 0 {
+        log.Printf("No JWT secret is configured, generating a key; tokens won't survive a restart")
+        cfg.Secret = make([]byte, 32)
+        if _, err := rand.Read(cfg.Secret); err != nil {
+            return cfg, err
+        }
+    }
+    return cfg, nil
+}
+
+// Claims are the JWT claims of access and refresh tokens. The subject is the user ID.
+type Claims struct {
+    jwt.RegisteredClaims
+    UserID      int    `json:"uid"`
+    WorkspaceID int    `json:"wid"`
+    Email       string `json:"email,omitempty"`
+    Type        string `json:"typ"`
+}
+
+// TokenPair is what login and refresh hand out
+type TokenPair struct {
+    AccessToken  string
+    RefreshToken string
+    ExpiresIn    time.Duration
+}
+
+// Manager issues and verifies tokens
+type Manager struct {
+    cfg       Config
+    method    jwt.SigningMethod
+    signKey   any
+    verifyKey any
+}
+
+// NewManager checks cfg and returns a Manager using it
+func NewManager(cfg Config) (*Manager, error) {
+    m := &Manager{cfg: cfg}
+    switch cfg.Algorithm {
+    case "HS256":
+        if len(cfg.Secret) == 0 {
+            return nil, errors.New("HS256 requires a secret")
+        }
+        m.method, m.signKey, m.verifyKey = jwt.SigningMethodHS256, cfg.Secret, cfg.Secret
+    case "RS256":
+        m.method = jwt.SigningMethodRS256
+        if cfg.PrivateKey != nil {
+            m.signKey = cfg.PrivateKey
+            m.verifyKey = &cfg.PrivateKey.PublicKey
+        }
+        if cfg.PublicKey != nil {
+            m.verifyKey = cfg.PublicKey
+        }
+        if m.verifyKey == nil {
+            return nil, errors.New("RS256 requires a private or public key")
+        }
+    default:
+        return nil, fmt.Errorf("unsupported JWT algorithm %q, expected HS256 or RS256", cfg.Algorithm)
+    }
+    return m, nil
+}
+
+// Issue returns a new access and refresh token for a user of a workspace
+func (m *Manager) Issue(userID, workspaceID int, email string) (TokenPair, error) {
+    if m.signKey == nil {
+        return TokenPair{}, errors.New("no signing key configured")
+    }
+
+    access, err := m.sign(userID, workspaceID, email, AccessToken, m.cfg.AccessTTL)
+    if err != nil {
+        return TokenPair{}, err
+    }
+    refresh, err := m.sign(userID, workspaceID, email, RefreshToken, m.cfg.RefreshTTL)
+    if err != nil {
+        return TokenPair{}, err
+    }
+    return TokenPair{AccessToken: access, RefreshToken: refresh, ExpiresIn: m.cfg.AccessTTL}, nil
+}
+
+func (m *Manager) sign(userID, workspaceID int, email, kind string, ttl time.Duration) (string, error) {
+    now := time.Now()
+    claims := Claims{
+        RegisteredClaims: jwt.RegisteredClaims{
+            Issuer:    m.cfg.Issuer,
+            Subject:   strconv.Itoa(userID),
+            IssuedAt:  jwt.NewNumericDate(now),
+            ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+        },
+        UserID:      userID,
+        WorkspaceID: workspaceID,
+        Email:       email,
+        Type:        kind,
+    }
+    return jwt.NewWithClaims(m.method, claims).SignedString(m.signKey)
+}
+
+// Verify parses a token of the given kind and returns its claims
+func (m *Manager) Verify(token, kind string) (*Claims, error) {
+    claims := &Claims{}
+    _, err := jwt.ParseWithClaims(token, claims, func(*jwt.Token) (any, error) {
+        return m.verifyKey, nil
+    }, jwt.WithValidMethods([]string{m.method.Alg()}), jwt.WithIssuer(m.cfg.Issuer), jwt.WithExpirationRequired())
+    if err != nil || claims.Type != kind || claims.UserID == 0 || claims.WorkspaceID == 0 {
+        return nil, ErrInvalidToken
+    }
+    return claims, nil
+}
+
+// HashPassword returns the bcrypt hash of password
+func HashPassword(password string) (string, error) {
+    hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+    return string(hash), err
+}
+
+// dummyHash is compared against when there is no user or password, so failed logins take
+// as long whether or not the email exists
+var dummyHash, _ = bcrypt.GenerateFromPassword([]byte("dummy password"), bcrypt.DefaultCost)
+
+// CheckPassword returns ErrInvalidCredentials unless password matches hash
+func CheckPassword(hash, password string) error {
+    if hash == "" {
+        bcrypt.CompareHashAndPassword(dummyHash, []byte(password))
+        return ErrInvalidCredentials
+    }
+    if bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) != nil {
+        return ErrInvalidCredentials
+    }
+    return nil
+}
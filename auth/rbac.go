@@ -0,0 +1,51 @@
+package auth
+
+// User roles, from least to most privileged
+const (
+    RoleViewer = "viewer"
+    RoleMember = "member"
+    RoleAdmin  = "admin"
+)
+
+// Permission is an action a role may be allowed to take. Permissions ending in :own only
+// apply to resources the caller owns; the :any variant applies to all of them.
+type Permission string
+
+// Permissions checked by the handlers
+const (
+    CreateTasks    Permission = "tasks:create"
+    UpdateOwnTasks Permission = "tasks:update:own"
+    UpdateAnyTask  Permission = "tasks:update:any"
+    DeleteOwnTasks Permission = "tasks:delete:own"
+    DeleteAnyTask  Permission = "tasks:delete:any"
+    WriteTags      Permission = "tags:write"
+    DeleteTags     Permission = "tags:delete"
+    WriteProjects  Permission = "projects:write"
+    DeleteProjects Permission = "projects:delete"
+    ManageUsers    Permission = "users:manage"
+    ReadAuditLog   Permission = "audit:read"
+)
+
+// rolePermissions lists what each role may do besides reading, which every role may
+var rolePermissions = map[string][]Permission{
+    RoleViewer: {},
+    RoleMember: {CreateTasks, UpdateOwnTasks, DeleteOwnTasks, WriteTags, WriteProjects},
+    RoleAdmin: {CreateTasks, UpdateOwnTasks, UpdateAnyTask, DeleteOwnTasks, DeleteAnyTask,
+        WriteTags, DeleteTags, WriteProjects, DeleteProjects, ManageUsers, ReadAuditLog},
+}
+
+// ValidRole reports whether role is one of the known roles
+func ValidRole(role string) bool {
+    _, ok := rolePermissions[role]
+    return ok
+}
+
+// Can reports whether role grants perm
+func Can(role string, perm Permission) bool {
+    for _, p := range rolePermissions[role] {
+        if p == perm {
+            return true
+        }
+    }
+    return false
+}
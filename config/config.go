@@ -0,0 +1,325 @@
+package config
+
+import (
+    "bytes"
+    "errors"
+    "flag"
+    "fmt"
+    "io"
+    "net"
+    "net/url"
+    "os"
+    "path/filepath"
+    "strings"
+    "time"
+    "github.com/pelletier/go-toml/v2"
+    "gopkg.in/yaml.v3"
+)
+
+// FileEnv names the configuration file when the -config flag isn't given
+const FileEnv = "CONFIG_FILE"
+
+// Config is the configuration of the server and its commands. It is assembled from the
+// defaults, a YAML or TOML file, environment variables and command-line flags, each
+// overriding the ones before.
+type Config struct {
+    Server    Server    `yaml:"server" toml:"server"`
+    Database  Database  `yaml:"database" toml:"database"`
+    CORS      CORS      `yaml:"cors" toml:"cors"`
+    JWT       JWT       `yaml:"jwt" toml:"jwt"`
+    Retention Retention `yaml:"retention" toml:"retention"`
+}
+
+// Server configures the HTTP listener
+type Server struct {
+    // Addr is the host:port to listen on
+    Addr string `yaml:"addr" toml:"addr"`
+    // WorkspaceDomain lets clients pick their workspace by subdomain, e.g.
+    // acme.tasks.example.com for tasks.example.com
+    WorkspaceDomain string `yaml:"workspace_domain" toml:"workspace_domain"`
+    // OpenSignup lets anyone create a workspace with POST /workspaces. Without it only the
+    // first workspace can be created that way and operators add the others from the
+    // command line.
+    OpenSignup bool `yaml:"open_signup" toml:"open_signup"`
+    // SignupRateLimit is how many sign-ups a client IP may attempt per hour; 0 is unlimited
+    SignupRateLimit int `yaml:"signup_rate_limit" toml:"signup_rate_limit"`
+    // TrustedProxies are the proxy IPs or CIDR ranges whose X-Forwarded-For header names
+    // the client; without any the peer address is the client
+    TrustedProxies []string `yaml:"trusted_proxies" toml:"trusted_proxies"`
+}
+
+// Database selects the backend
+type Database struct {
+    // URL is a SQLite file or a postgres:// URL
+    URL string `yaml:"url" toml:"url"`
+}
+
+// CORS controls which browser origins may call the API
+type CORS struct {
+    // AllowedOrigins are the origins, such as https://app.example.com, allowed to call the
+    // API; * allows any and no origins disables CORS
+    AllowedOrigins []string `yaml:"allowed_origins" toml:"allowed_origins"`
+    // AllowCredentials lets browsers send cookies; it needs explicit origins
+    AllowCredentials bool `yaml:"allow_credentials" toml:"allow_credentials"`
+    // MaxAge is how long browsers may cache preflight responses
+    MaxAge Duration `yaml:"max_age" toml:"max_age"`
+}
+
+// JWT configures token signing
+type JWT struct {
+    // Algorithm is HS256 or RS256
+    Algorithm string `yaml:"algorithm" toml:"algorithm"`
+    // Secret is the HS256 key; without it a key is generated and tokens don't survive a
+    // restart
+    Secret string `yaml:"secret" toml:"secret"`
+    // PrivateKeyFile and PublicKeyFile are PEM files with the RS256 keys
+    PrivateKeyFile string   `yaml:"private_key_file" toml:"private_key_file"`
+    PublicKeyFile  string   `yaml:"public_key_file" toml:"public_key_file"`
+    Issuer         string   `yaml:"issuer" toml:"issuer"`
+    AccessTTL      Duration `yaml:"access_ttl" toml:"access_ttl"`
+    RefreshTTL     Duration `yaml:"refresh_ttl" toml:"refresh_ttl"`
+}
+
+// Retention controls how long deleted tasks and idempotency keys are kept
+type Retention struct {
+    // Trash is how long tasks stay in the trash; 0 keeps them forever
+    Trash Duration `yaml:"trash" toml:"trash"`
+    // IdempotencyKeys is how long idempotency keys are remembered
+    IdempotencyKeys Duration `yaml:"idempotency_keys" toml:"idempotency_keys"`
+    // PurgeInterval is how often expired data is purged
+    PurgeInterval Duration `yaml:"purge_interval" toml:"purge_interval"`
+}
+
+// Duration is a time.Duration written like 15m or 720h
+type Duration time.Duration
+
+// Std returns d as a time.Duration
+func (d Duration) Std() time.Duration {
+    return time.Duration(d)
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (d Duration) MarshalText() ([]byte, error) {
+    return []byte(time.Duration(d).String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (d *Duration) UnmarshalText(text []byte) error {
+    v, err := time.ParseDuration(string(text))
+    if err != nil {
+        return err
+    }
+    *d = Duration(v)
+    return nil
+}
+
+// Default returns the configuration used for everything that isn't set
+func Default() Config {
+    return Config{
+        Server:   Server{Addr: ":8080", SignupRateLimit: 5},
+        Database: Database{URL: "./app.db"},
+        CORS: CORS{
+            AllowedOrigins: []string{"*"},
+            MaxAge:         Duration(12 * time.Hour),
+        },
+        JWT: JWT{
+            Algorithm:  "HS256",
+            Issuer:     "task-api",
+            AccessTTL:  Duration(15 * time.Minute),
+            RefreshTTL: Duration(30 * 24 * time.Hour),
+        },
+        Retention: Retention{
+            Trash:           Duration(30 * 24 * time.Hour),
+            IdempotencyKeys: Duration(24 * time.Hour),
+            PurgeInterval:   Duration(time.Hour),
+        },
+    }
+}
+
+// Load builds the configuration from the defaults, the file named by -config or
+// CONFIG_FILE, the environment and the flags in args, in increasing precedence, and
+// validates it. It returns the arguments left after the flags.
+func Load(args []string) (Config, []string, error) {
+    type flagged struct{ key, value string }
+    var set []flagged
+    fs, file := newFlagSet(func(key, value string) {
+        set = append(set, flagged{key, value})
+    })
+    fs.SetOutput(io.Discard)
+    if err := fs.Parse(args); err != nil {
+        return Config{}, nil, err
+    }
+
+    cfg := Default()
+    if *file != "" {
+        if err := readFile(*file, &cfg); err != nil {
+            return cfg, nil, err
+        }
+    }
+
+    byKey := map[string]setting{}
+    for _, s := range settings(&cfg) {
+        byKey[s.key] = s
+        if value := os.Getenv(s.env); value != "" {
+            if err := s.value.Set(value); err != nil {
+                return cfg, nil, fmt.Errorf("%s: %w", s.env, err)
+            }
+        }
+    }
+    for _, f := range set {
+        byKey[f.key].value.Set(f.value)
+    }
+
+    if err := cfg.Validate(); err != nil {
+        return cfg, nil, err
+    }
+    return cfg, fs.Args(), nil
+}
+
+// PrintFlags writes the flags Load accepts to w
+func PrintFlags(w io.Writer) {
+    fs, _ := newFlagSet(func(key, value string) {})
+    fs.SetOutput(w)
+    fs.PrintDefaults()
+}
+
+// newFlagSet returns the flags of every setting and of the configuration file. Flags are
+// checked against a scratch configuration while parsing and handed to record, so Load can
+// apply them once the file and the environment have been read.
+func newFlagSet(record func(key, value string)) (*flag.FlagSet, *string) {
+    fs := flag.NewFlagSet("task-api", flag.ContinueOnError)
+    file := fs.String("config", os.Getenv(FileEnv), "YAML or TOML configuration `file` (env "+FileEnv+")")
+
+    scratch := Default()
+    for _, s := range settings(&scratch) {
+        s := s
+        usage := fmt.Sprintf("%s (env %s)", s.usage, s.env)
+        set := func(value string) error {
+            if err := s.value.Set(value); err != nil {
+                return err
+            }
+            record(s.key, value)
+            return nil
+        }
+        if b, ok := s.value.(interface{ IsBoolFlag() bool }); ok && b.IsBoolFlag() {
+            fs.BoolFunc(s.key, usage, set)
+        } else {
+            fs.Func(s.key, usage+withDefault(s.value.String()), set)
+        }
+    }
+    return fs, file
+}
+
+func withDefault(value string) string {
+    if value == "" {
+        return ""
+    }
+    return " (default " + value + ")"
+}
+
+// readFile decodes a .yaml, .yml or .toml file into cfg, rejecting unknown keys
+func readFile(path string, cfg *Config) error {
+    data, err := os.ReadFile(path)
+    if err != nil {
+        return err
+    }
+
+    switch strings.ToLower(filepath.Ext(path)) {
+    case ".yaml", ".yml":
+        dec := yaml.NewDecoder(bytes.NewReader(data))
+        dec.KnownFields(true)
+        if err := dec.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+            return fmt.Errorf("%s: %w", path, err)
+        }
+    case ".toml":
+        dec := toml.NewDecoder(bytes.NewReader(data))
+        dec.DisallowUnknownFields()
+        if err := dec.Decode(cfg); err != nil {
+            return fmt.Errorf("%s: %w", path, err)
+        }
+    default:
+        return fmt.Errorf("%s: unsupported configuration format, expected .yaml, .yml or .toml", path)
+    }
+    return nil
+}
+
+// Validate reports every setting that can't work, so a bad configuration stops the server
+// at startup rather than on the first request
+func (cfg Config) Validate() error {
+    var errs []error
+    invalid := func(key, format string, args ...any) {
+        errs = append(errs, fmt.Errorf(key+": "+format, args...))
+    }
+
+    if _, _, err := net.SplitHostPort(cfg.Server.Addr); err != nil {
+        invalid("server.addr", "must be host:port or :port, got %q", cfg.Server.Addr)
+    }
+    if domain := cfg.Server.WorkspaceDomain; domain != "" && (strings.HasPrefix(domain, ".") || strings.ContainsAny(domain, ":/ ")) {
+        invalid("server.workspace_domain", "must be a bare domain such as tasks.example.com, got %q", domain)
+    }
+
+    if cfg.Server.SignupRateLimit < 0 {
+        invalid("server.signup_rate_limit", "must not be negative")
+    }
+    for _, proxy := range cfg.Server.TrustedProxies {
+        if net.ParseIP(proxy) == nil {
+            if _, _, err := net.ParseCIDR(proxy); err != nil {
+                invalid("server.trusted_proxies", "%q is neither an IP address nor a CIDR range", proxy)
+            }
+        }
+    }
+
+    if cfg.Database.URL == "" {
+        invalid("database.url", "is required")
+    }
+
+    for _, origin := range cfg.CORS.AllowedOrigins {
+        if origin == "*" {
+            if len(cfg.CORS.AllowedOrigins) > 1 {
+                invalid("cors.allowed_origins", "* can't be combined with other origins")
+            }
+            if cfg.CORS.AllowCredentials {
+                invalid("cors.allow_credentials", "requires explicit origins; browsers refuse credentials for *")
+            }
+            continue
+        }
+        u, err := url.Parse(origin)
+        if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || (u.Path != "" && u.Path != "/") || u.RawQuery != "" {
+            invalid("cors.allowed_origins", "%q is not an origin such as https://app.example.com", origin)
+        }
+    }
+    if cfg.CORS.MaxAge < 0 {
+        invalid("cors.max_age", "must not be negative")
+    }
+
+    switch cfg.JWT.Algorithm {
+    case "HS256":
+    case "RS256":
+        if cfg.JWT.PrivateKeyFile == "" && cfg.JWT.PublicKeyFile == "" {
+            invalid("jwt", "RS256 requires private_key_file or public_key_file")
+        }
+    default:
+        invalid("jwt.algorithm", "must be HS256 or RS256, got %q", cfg.JWT.Algorithm)
+    }
+    if cfg.JWT.AccessTTL <= 0 {
+        invalid("jwt.access_ttl", "must be positive")
+    }
+    if cfg.JWT.RefreshTTL <= 0 {
+        invalid("jwt.refresh_ttl", "must be positive")
+    }
+
+    if cfg.Retention.Trash < 0 {
+        invalid("retention.trash", "must not be negative")
+    }
+    if cfg.Retention.IdempotencyKeys <= 0 {
+        invalid("retention.idempotency_keys", "must be positive")
+    }
+    if cfg.Retention.PurgeInterval <= 0 {
+        invalid("retention.purge_interval", "must be positive")
+    }
+
+    if len(errs) > 0 {
+        return fmt.Errorf("invalid configuration:\n%w", errors.Join(errs...))
+    }
+    return nil
+}
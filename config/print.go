@@ -0,0 +1,57 @@
+package config
+
+import (
+    "fmt"
+    "io"
+    "net/url"
+    "regexp"
+    "github.com/pelletier/go-toml/v2"
+    "gopkg.in/yaml.v3"
+)
+
+// redacted replaces secrets in printed configurations
+const redacted = "REDACTED"
+
+// passwordParam matches the password of a key=value PostgreSQL connection string
+var passwordParam = regexp.MustCompile(`(?i)(password\s*=\s*)('[^']*'|\S+)`)
+
+// Redacted returns a copy of cfg that is safe to show: secrets and database passwords are
+// replaced
+func (cfg Config) Redacted() Config {
+    out := cfg
+    out.CORS.AllowedOrigins = append([]string(nil), cfg.CORS.AllowedOrigins...)
+    for _, s := range settings(&out) {
+        if s.secret && s.value.String() != "" {
+            s.value.Set(redacted)
+        }
+    }
+    out.Database.URL = redactDSN(out.Database.URL)
+    return out
+}
+
+// redactDSN hides the password of a database URL or connection string
+func redactDSN(dsn string) string {
+    if u, err := url.Parse(dsn); err == nil && u.User != nil {
+        if _, ok := u.User.Password(); ok {
+            u.User = url.UserPassword(u.User.Username(), redacted)
+            return u.String()
+        }
+    }
+    return passwordParam.ReplaceAllString(dsn, "${1}"+redacted)
+}
+
+// Write prints cfg as yaml or toml
+func (cfg Config) Write(w io.Writer, format string) error {
+    switch format {
+    case "yaml":
+        enc := yaml.NewEncoder(w)
+        enc.SetIndent(2)
+        if err := enc.Encode(cfg); err != nil {
+            return err
+        }
+        return enc.Close()
+    case "toml":
+        return toml.NewEncoder(w).Encode(cfg)
+    }
+    return fmt.Errorf("unknown format %q, expected yaml or toml", format)
+}
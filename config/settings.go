@@ -0,0 +1,128 @@
+package config
+
+import (
+    "flag"
+    "strconv"
+    "strings"
+)
+
+// setting ties a key of the configuration file to its flag and environment variable. The
+// flag is named like the key, e.g. -server.addr.
+type setting struct {
+    key    string
+    env    string
+    usage  string
+    secret bool
+    value  flag.Value
+}
+
+// settings lists every setting of cfg. Variables the server read before keep their names,
+// so existing deployments work unchanged.
+func settings(cfg *Config) []setting {
+    return []setting{
+        {key: "server.addr", env: "SERVER_ADDR", usage: "host:port to listen on", value: stringValue{&cfg.Server.Addr}},
+        {key: "server.workspace_domain", env: "WORKSPACE_DOMAIN", usage: "domain whose subdomains select a workspace, e.g. tasks.example.com", value: stringValue{&cfg.Server.WorkspaceDomain}},
+        {key: "server.open_signup", env: "OPEN_SIGNUP", usage: "let anyone create workspaces, not just the first one", value: boolValue{&cfg.Server.OpenSignup}},
+        {key: "server.signup_rate_limit", env: "SIGNUP_RATE_LIMIT", usage: "sign-ups a client IP may attempt per hour; 0 is unlimited", value: intValue{&cfg.Server.SignupRateLimit}},
+        {key: "server.trusted_proxies", env: "TRUSTED_PROXIES", usage: "comma separated proxy IPs or CIDR ranges whose X-Forwarded-For is believed", value: listValue{&cfg.Server.TrustedProxies}},
+        {key: "database.url", env: "DATABASE_URL", usage: "SQLite file or postgres:// URL", value: stringValue{&cfg.Database.URL}},
+        {key: "cors.allowed_origins", env: "CORS_ALLOWED_ORIGINS", usage: "comma separated origins allowed to call the API from browsers; * allows any", value: listValue{&cfg.CORS.AllowedOrigins}},
+        {key: "cors.allow_credentials", env: "CORS_ALLOW_CREDENTIALS", usage: "let browsers send credentials; requires explicit origins", value: boolValue{&cfg.CORS.AllowCredentials}},
+        {key: "cors.max_age", env: "CORS_MAX_AGE", usage: "how long browsers may cache preflight responses", value: &cfg.CORS.MaxAge},
+        {key: "jwt.algorithm", env: "JWT_ALGORITHM", usage: "token signing algorithm, HS256 or RS256", value: stringValue{&cfg.JWT.Algorithm}},
+        {key: "jwt.secret", env: "JWT_SECRET", usage: "HS256 signing key; generated when empty", secret: true, value: stringValue{&cfg.JWT.Secret}},
+        {key: "jwt.private_key_file", env: "JWT_PRIVATE_KEY_FILE", usage: "PEM file with the RS256 private key", value: stringValue{&cfg.JWT.PrivateKeyFile}},
+        {key: "jwt.public_key_file", env: "JWT_PUBLIC_KEY_FILE", usage: "PEM file with the RS256 public key", value: stringValue{&cfg.JWT.PublicKeyFile}},
+        {key: "jwt.issuer", env: "JWT_ISSUER", usage: "iss claim of issued tokens", value: stringValue{&cfg.JWT.Issuer}},
+        {key: "jwt.access_ttl", env: "JWT_ACCESS_TTL", usage: "lifetime of access tokens", value: &cfg.JWT.AccessTTL},
+        {key: "jwt.refresh_ttl", env: "JWT_REFRESH_TTL", usage: "lifetime of refresh tokens", value: &cfg.JWT.RefreshTTL},
+        {key: "retention.trash", env: "TRASH_RETENTION", usage: "how long deleted tasks stay in the trash; 0 keeps them forever", value: &cfg.Retention.Trash},
+        {key: "retention.idempotency_keys", env: "IDEMPOTENCY_KEY_TTL", usage: "how long idempotency keys are remembered", value: &cfg.Retention.IdempotencyKeys},
+        {key: "retention.purge_interval", env: "PURGE_INTERVAL", usage: "how often expired data is purged", value: &cfg.Retention.PurgeInterval},
+    }
+}
+
+// String implements flag.Value
+func (d *Duration) String() string {
+    return d.Std().String()
+}
+
+// Set implements flag.Value
+func (d *Duration) Set(value string) error {
+    return d.UnmarshalText([]byte(value))
+}
+
+type stringValue struct{ p *string }
+
+func (v stringValue) String() string {
+    if v.p == nil {
+        return ""
+    }
+    return *v.p
+}
+
+func (v stringValue) Set(value string) error {
+    *v.p = value
+    return nil
+}
+
+type intValue struct{ p *int }
+
+func (v intValue) String() string {
+    if v.p == nil {
+        return "0"
+    }
+    return strconv.Itoa(*v.p)
+}
+
+func (v intValue) Set(value string) error {
+    n, err := strconv.Atoi(value)
+    if err != nil {
+        return err
+    }
+    *v.p = n
+    return nil
+}
+
+type boolValue struct{ p *bool }
+
+func (v boolValue) String() string {
+    if v.p == nil {
+        return "false"
+    }
+    return strconv.FormatBool(*v.p)
+}
+
+func (v boolValue) Set(value string) error {
+    b, err := strconv.ParseBool(value)
+    if err != nil {
+        return err
+    }
+    *v.p = b
+    return nil
+}
+
+func (v boolValue) IsBoolFlag() bool {
+    return true
+}
+
+// listValue reads comma separated values, ignoring blanks
+type listValue struct{ p *[]string }
+
+func (v listValue) String() string {
+    if v.p == nil {
+        return ""
+    }
+    return strings.Join(*v.p, ",")
+}
+
+func (v listValue) Set(value string) error {
+    list := []string{}
+    for _, item := range strings.Split(value, ",") {
+        if item = strings.TrimSpace(item); item != "" {
+            list = append(list, item)
+        }
+    }
+    *v.p = list
+    return nil
+}
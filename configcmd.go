@@ -0,0 +1,29 @@
+package main
+
+import (
+    "fmt"
+    "os"
+    "github.com/maazxenon/task-api/config"
+)
+
+const configUsage = `usage: task-api config <command>
+
+commands:
+  print [yaml|toml]    show the effective configuration with secrets redacted`
+
+// runConfig implements the config subcommand
+func runConfig(cfg config.Config, args []string) error {
+    if len(args) == 0 {
+        return fmt.Errorf("missing command\n%s", configUsage)
+    }
+
+    switch args[0] {
+    case "print":
+        format := "yaml"
+        if len(args) > 1 {
+            format = args[1]
+        }
+        return cfg.Redacted().Write(os.Stdout, format)
+    }
+    return fmt.Errorf("unknown command %q\n%s", args[0], configUsage)
+}
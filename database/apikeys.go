@@ -0,0 +1,120 @@
+package database
+
+import (
+    "context"
+    "database/sql"
+    "errors"
+    "strings"
+    "time"
+    "github.com/maazxenon/task-api/dates"
+    "github.com/maazxenon/task-api/models"
+)
+
+// ErrAPIKeyNotFound is returned when an API key doesn't exist or belongs to another user
+var ErrAPIKeyNotFound = errors.New("API key not found")
+
+// APIKeyStore is the persistence layer used by the handlers to manage API keys
+type APIKeyStore interface {
+    // APIKeys returns the keys of a user, newest first, including revoked ones
+    APIKeys(ctx context.Context, userID int) ([]models.APIKey, error)
+    // CreateAPIKey stores a key under its hash and returns it with its assigned ID
+    CreateAPIKey(ctx context.Context, key models.APIKey, hash string) (models.APIKey, error)
+    // RevokeAPIKey revokes a key of a user or returns ErrAPIKeyNotFound
+    RevokeAPIKey(ctx context.Context, userID, id int) (models.APIKey, error)
+    // APIKeyByHash returns the key stored under hash or ErrAPIKeyNotFound
+    APIKeyByHash(ctx context.Context, hash string) (models.APIKey, error)
+    // TouchAPIKey records that a key has been used at the given time
+    TouchAPIKey(ctx context.Context, id int, at time.Time) error
+}
+
+const apiKeyColumns = "id, user_id, name, prefix, scopes, created_at, last_used_at, expires_at, revoked_at"
+
+// apiKeyTouchInterval limits how often last_used_at is written for a busy key
+const apiKeyTouchInterval = time.Minute
+
+// scanAPIKey reads a row selected with apiKeyColumns
+func scanAPIKey(row scanner) (models.APIKey, error) {
+    var key models.APIKey
+    var scopes string
+    err := row.Scan(&key.ID, &key.UserID, &key.Name, &key.Prefix, &scopes,
+        timestamp{&key.CreatedAt}, timestamp{&key.LastUsedAt}, timestamp{&key.ExpiresAt}, timestamp{&key.RevokedAt})
+    key.Scopes = strings.Fields(scopes)
+    return key, err
+}
+
+// APIKeys returns the keys of a user
+func (s *SQLStore) APIKeys(ctx context.Context, userID int) ([]models.APIKey, error) {
+    rows, err := s.query(ctx, "SELECT "+apiKeyColumns+" FROM api_keys WHERE user_id = ? ORDER BY id DESC", userID)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    keys := []models.APIKey{}
+    for rows.Next() {
+        key, err := scanAPIKey(rows)
+        if err != nil {
+            return nil, err
+        }
+        keys = append(keys, key)
+    }
+    return keys, rows.Err()
+}
+
+// CreateAPIKey inserts a new key
+func (s *SQLStore) CreateAPIKey(ctx context.Context, key models.APIKey, hash string) (models.APIKey, error) {
+    key.CreatedAt = dates.Format(time.Now())
+    id, err := s.insert(ctx, "INSERT INTO api_keys(user_id, name, prefix, key_hash, scopes, created_at, expires_at) VALUES(?, ?, ?, ?, ?, ?, ?)",
+        key.UserID, key.Name, key.Prefix, hash, strings.Join(key.Scopes, " "), key.CreatedAt, timestampArg(key.ExpiresAt))
+    if err != nil {
+        return models.APIKey{}, err
+    }
+
+    key.ID = id
+    return key, nil
+}
+
+// RevokeAPIKey sets the revoked_at timestamp of a key. Revoking a revoked key keeps the
+// original time.
+func (s *SQLStore) RevokeAPIKey(ctx context.Context, userID, id int) (models.APIKey, error) {
+    var key models.APIKey
+    err := s.withTx(ctx, func(tx *SQLStore) error {
+        if _, err := tx.exec(ctx, "UPDATE api_keys SET revoked_at = ? WHERE id = ? AND user_id = ? AND revoked_at IS NULL",
+            dates.Format(time.Now()), id, userID); err != nil {
+            return err
+        }
+
+        var err error
+        key, err = scanAPIKey(tx.queryRow(ctx, "SELECT "+apiKeyColumns+" FROM api_keys WHERE id = ? AND user_id = ?", id, userID))
+        if errors.Is(err, sql.ErrNoRows) {
+            return ErrAPIKeyNotFound
+        }
+        return err
+    })
+    if err != nil {
+        return models.APIKey{}, err
+    }
+    return key, nil
+}
+
+// APIKeyByHash returns the key stored under hash, with the workspace of its user
+func (s *SQLStore) APIKeyByHash(ctx context.Context, hash string) (models.APIKey, error) {
+    var key models.APIKey
+    var scopes string
+    err := s.queryRow(ctx, `SELECT k.id, k.user_id, k.name, k.prefix, k.scopes, k.created_at, k.last_used_at, k.expires_at, k.revoked_at, u.workspace_id
+        FROM api_keys k JOIN users u ON u.id = k.user_id WHERE k.key_hash = ?`, hash).
+        Scan(&key.ID, &key.UserID, &key.Name, &key.Prefix, &scopes, timestamp{&key.CreatedAt}, timestamp{&key.LastUsedAt},
+            timestamp{&key.ExpiresAt}, timestamp{&key.RevokedAt}, &key.WorkspaceID)
+    key.Scopes = strings.Fields(scopes)
+    if errors.Is(err, sql.ErrNoRows) {
+        return models.APIKey{}, ErrAPIKeyNotFound
+    }
+    return key, err
+}
+
+// TouchAPIKey sets last_used_at, at most once per apiKeyTouchInterval
+func (s *SQLStore) TouchAPIKey(ctx context.Context, id int, at time.Time) error {
+    _, err := s.exec(ctx, "UPDATE api_keys SET last_used_at = ? WHERE id = ? AND (last_used_at IS NULL OR last_used_at < ?)",
+        dates.Format(at), id, dates.Format(at.Add(-apiKeyTouchInterval)))
+    return err
+}
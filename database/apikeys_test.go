@@ -0,0 +1,74 @@
+package database
+
+import (
+    "errors"
+    "reflect"
+    "testing"
+    "time"
+    "github.com/maazxenon/task-api/models"
+)
+
+func TestAPIKeys(t *testing.T) {
+    s, ctx := newTestStore(t)
+    owner := *Actor(ctx)
+    bob, err := s.CreateUser(ctx, models.User{Name: "Bob", Email: "bob@example.com"})
+    if err != nil {
+        t.Fatalf("CreateUser: %v", err)
+    }
+
+    key, err := s.CreateAPIKey(ctx, models.APIKey{UserID: owner, Name: "CI", Prefix: "tk_ci", Scopes: []string{"read", "write"}}, "hash")
+    if err != nil {
+        t.Fatalf("CreateAPIKey: %v", err)
+    }
+    found, err := s.APIKeyByHash(ctx, "hash")
+    if err != nil {
+        t.Fatalf("APIKeyByHash: %v", err)
+    }
+    if found.ID != key.ID || found.WorkspaceID != WorkspaceID(ctx) || !reflect.DeepEqual(found.Scopes, key.Scopes) {
+        t.Errorf("APIKeyByHash = %+v, want key %d of workspace %d with scopes %v", found, key.ID, WorkspaceID(ctx), key.Scopes)
+    }
+    if _, err := s.APIKeyByHash(ctx, "other"); !errors.Is(err, ErrAPIKeyNotFound) {
+        t.Errorf("APIKeyByHash of an unknown hash error = %v, want ErrAPIKeyNotFound", err)
+    }
+
+    // Uses are recorded at most once per interval
+    used := time.Now().UTC().Truncate(time.Second)
+    for _, at := range []time.Time{used, used.Add(apiKeyTouchInterval / 2)} {
+        if err := s.TouchAPIKey(ctx, key.ID, at); err != nil {
+            t.Fatalf("TouchAPIKey: %v", err)
+        }
+    }
+    if keys, err := s.APIKeys(ctx, owner); err != nil || len(keys) != 1 || keys[0].LastUsedAt != used.Format(time.RFC3339) {
+        t.Errorf("APIKeys = %+v, %v, want the key last used at %s", keys, err, used.Format(time.RFC3339))
+    }
+
+    if _, err := s.RevokeAPIKey(ctx, bob.ID, key.ID); !errors.Is(err, ErrAPIKeyNotFound) {
+        t.Errorf("RevokeAPIKey by another user error = %v, want ErrAPIKeyNotFound", err)
+    }
+    revoked, err := s.RevokeAPIKey(ctx, owner, key.ID)
+    if err != nil || revoked.RevokedAt == "" {
+        t.Fatalf("RevokeAPIKey = %+v, %v, want a revocation time", revoked, err)
+    }
+    again, err := s.RevokeAPIKey(ctx, owner, key.ID)
+    if err != nil || again.RevokedAt != revoked.RevokedAt {
+        t.Errorf("revoking again = %+v, %v, want the original time %s", again, err, revoked.RevokedAt)
+    }
+}
+
+func TestDeleteUserRemovesAPIKeys(t *testing.T) {
+    s, ctx := newTestStore(t)
+    bob, err := s.CreateUser(ctx, models.User{Name: "Bob", Email: "bob@example.com"})
+    if err != nil {
+        t.Fatalf("CreateUser: %v", err)
+    }
+    if _, err := s.CreateAPIKey(ctx, models.APIKey{UserID: bob.ID, Name: "CI", Prefix: "tk_ci", Scopes: []string{"read"}}, "hash"); err != nil {
+        t.Fatalf("CreateAPIKey: %v", err)
+    }
+
+    if err := s.DeleteUser(ctx, bob.ID); err != nil {
+        t.Fatalf("DeleteUser: %v", err)
+    }
+    if _, err := s.APIKeyByHash(ctx, "hash"); !errors.Is(err, ErrAPIKeyNotFound) {
+        t.Errorf("APIKeyByHash after deleting its user error = %v, want ErrAPIKeyNotFound", err)
+    }
+}
@@ -0,0 +1,242 @@
+package database
+
+import (
+    "context"
+    "database/sql"
+    "encoding/json"
+    "strings"
+    "time"
+    "github.com/maazxenon/task-api/dates"
+    "github.com/maazxenon/task-api/models"
+)
+
+// Audited actions. Deleting moves a task to the trash; purging removes it for good.
+const (
+    AuditCreate  = "create"
+    AuditUpdate  = "update"
+    AuditDelete  = "delete"
+    AuditRestore = "restore"
+    AuditPurge   = "purge"
+)
+
+// AuditStore is the persistence layer used by the handlers to read the audit log. Entries
+// are written by the task mutations themselves, in the same transaction.
+type AuditStore interface {
+    // TaskHistory returns the entries of a task, oldest first, or ErrTaskNotFound if there
+    // are none and the task doesn't exist
+    TaskHistory(ctx context.Context, taskID int) ([]models.AuditEntry, error)
+    // AuditLog returns one page of the entries matching filter, newest first
+    AuditLog(ctx context.Context, filter AuditFilter) (AuditPage, error)
+}
+
+// AuditFilter narrows and paginates the entries returned by AuditLog
+type AuditFilter struct {
+    TaskID    *int
+    ActorID   *int
+    Action    string
+    RequestID string
+    // Since and Until are inclusive bounds on the time of the change
+    Since string
+    Until string
+    // Limit is the page size; it defaults to DefaultPageSize
+    Limit int
+    // Before continues a previous listing below the entry ID it returned in AuditPage.Next
+    Before int
+}
+
+// AuditPage is one page of the audit log
+type AuditPage struct {
+    Entries []models.AuditEntry
+    // Next is the ID to continue below, 0 on the last page
+    Next int
+}
+
+type actorKey struct{}
+
+type requestIDKey struct{}
+
+// WithActor returns a context attributing the changes made with it to a user
+func WithActor(ctx context.Context, userID int) context.Context {
+    return context.WithValue(ctx, actorKey{}, userID)
+}
+
+// Actor returns the user changes made with ctx are attributed to, or nil
+func Actor(ctx context.Context) *int {
+    id, ok := ctx.Value(actorKey{}).(int)
+    if !ok {
+        return nil
+    }
+    return &id
+}
+
+// WithRequestID returns a context recording the changes made with it under a request ID
+func WithRequestID(ctx context.Context, id string) context.Context {
+    return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID returns the request ID of ctx, or ""
+func RequestID(ctx context.Context) string {
+    id, _ := ctx.Value(requestIDKey{}).(string)
+    return id
+}
+
+const auditColumns = "id, task_id, action, actor_id, request_id, created_at, before_snapshot, after_snapshot"
+
+// scanAuditEntry reads a row selected with auditColumns
+func scanAuditEntry(row scanner) (models.AuditEntry, error) {
+    var entry models.AuditEntry
+    var before, after sql.NullString
+    if err := row.Scan(&entry.ID, &entry.TaskID, &entry.Action, &entry.ActorID, &entry.RequestID,
+        timestamp{&entry.CreatedAt}, &before, &after); err != nil {
+        return entry, err
+    }
+
+    var err error
+    if entry.Before, err = unmarshalSnapshot(before); err != nil {
+        return entry, err
+    }
+    entry.After, err = unmarshalSnapshot(after)
+    return entry, err
+}
+
+// unmarshalSnapshot decodes a stored task snapshot
+func unmarshalSnapshot(value sql.NullString) (*models.Task, error) {
+    if !value.Valid {
+        return nil, nil
+    }
+    var task models.Task
+    if err := json.Unmarshal([]byte(value.String), &task); err != nil {
+        return nil, err
+    }
+    return &task, nil
+}
+
+// marshalSnapshot encodes a task snapshot, nil for a missing one
+func marshalSnapshot(task *models.Task) (any, error) {
+    if task == nil {
+        return nil, nil
+    }
+    b, err := json.Marshal(task)
+    if err != nil {
+        return nil, err
+    }
+    return string(b), nil
+}
+
+// TaskHistory returns the entries of a task
+func (s *SQLStore) TaskHistory(ctx context.Context, taskID int) ([]models.AuditEntry, error) {
+    rows, err := s.query(ctx, "SELECT "+auditColumns+" FROM audit_log WHERE workspace_id = ? AND task_id = ? ORDER BY id",
+        WorkspaceID(ctx), taskID)
+    if err != nil {
+        return nil, err
+    }
+    entries, err := scanAuditEntries(rows)
+    if err != nil {
+        return nil, err
+    }
+
+    if len(entries) == 0 {
+        if err := s.exists(ctx, taskID); err != nil {
+            return nil, err
+        }
+    }
+    return entries, nil
+}
+
+// AuditLog returns one page of the audit log
+func (s *SQLStore) AuditLog(ctx context.Context, filter AuditFilter) (AuditPage, error) {
+    where := []string{"workspace_id = ?"}
+    args := []any{WorkspaceID(ctx)}
+
+    if filter.TaskID != nil {
+        where = append(where, "task_id = ?")
+        args = append(args, *filter.TaskID)
+    }
+    if filter.ActorID != nil {
+        where = append(where, "actor_id = ?")
+        args = append(args, *filter.ActorID)
+    }
+    if filter.Action != "" {
+        where = append(where, "action = ?")
+        args = append(args, filter.Action)
+    }
+    if filter.RequestID != "" {
+        where = append(where, "request_id = ?")
+        args = append(args, filter.RequestID)
+    }
+    if filter.Since != "" {
+        where = append(where, "created_at >= ?")
+        args = append(args, filter.Since)
+    }
+    if filter.Until != "" {
+        where = append(where, "created_at <= ?")
+        args = append(args, filter.Until)
+    }
+    if filter.Before != 0 {
+        where = append(where, "id < ?")
+        args = append(args, filter.Before)
+    }
+
+    limit := filter.Limit
+    if limit <= 0 {
+        limit = DefaultPageSize
+    }
+    // fetch one extra row to learn whether another page follows
+    args = append(args, limit+1)
+
+    rows, err := s.query(ctx, "SELECT "+auditColumns+" FROM audit_log WHERE "+strings.Join(where, " AND ")+" ORDER BY id DESC LIMIT ?", args...)
+    if err != nil {
+        return AuditPage{}, err
+    }
+    entries, err := scanAuditEntries(rows)
+    if err != nil {
+        return AuditPage{}, err
+    }
+
+    page := AuditPage{Entries: entries}
+    if len(entries) > limit {
+        page.Entries = entries[:limit]
+        page.Next = entries[limit-1].ID
+    }
+    return page, nil
+}
+
+// scanAuditEntries scans and closes rows selected with auditColumns
+func scanAuditEntries(rows *sql.Rows) ([]models.AuditEntry, error) {
+    defer rows.Close()
+
+    entries := []models.AuditEntry{}
+    for rows.Next() {
+        entry, err := scanAuditEntry(rows)
+        if err != nil {
+            return nil, err
+        }
+        entries = append(entries, entry)
+    }
+    return entries, rows.Err()
+}
+
+// audit appends an entry for a change to a task, attributed to the actor and request of ctx
+func (s *SQLStore) audit(ctx context.Context, action string, taskID int, before, after *models.Task) error {
+    beforeArg, err := marshalSnapshot(before)
+    if err != nil {
+        return err
+    }
+    afterArg, err := marshalSnapshot(after)
+    if err != nil {
+        return err
+    }
+
+    _, err = s.exec(ctx, `INSERT INTO audit_log(workspace_id, task_id, action, actor_id, request_id, created_at, before_snapshot, after_snapshot)
+        VALUES(?, ?, ?, ?, ?, ?, ?, ?)`, WorkspaceID(ctx), taskID, action, Actor(ctx), RequestID(ctx), dates.Format(time.Now()), beforeArg, afterArg)
+    return err
+}
+
+// auditCurrent appends an entry whose after snapshot is the stored state of task id
+func (s *SQLStore) auditCurrent(ctx context.Context, action string, id int, before *models.Task) error {
+    after, err := s.Get(ctx, id)
+    if err != nil {
+        return err
+    }
+    return s.audit(ctx, action, id, before, &after)
+}
@@ -0,0 +1,104 @@
+package database
+
+import (
+    "errors"
+    "reflect"
+    "testing"
+)
+
+func TestTaskHistory(t *testing.T) {
+    s, ctx := newTestStore(t)
+    ctx = WithRequestID(ctx, "req-1")
+    task := createTask(t, s, ctx, "Draft", nil)
+
+    task.Title = "Final"
+    updated, err := s.Update(ctx, task, task.Version)
+    if err != nil {
+        t.Fatalf("Update: %v", err)
+    }
+    // A rejected change leaves no entry behind
+    if _, err := s.Update(ctx, task, task.Version); !errors.Is(err, ErrVersionConflict) {
+        t.Fatalf("stale Update error = %v, want ErrVersionConflict", err)
+    }
+    if err := s.Delete(ctx, task.ID, DeleteOptions{}); err != nil {
+        t.Fatalf("Delete: %v", err)
+    }
+    if _, err := s.Restore(ctx, task.ID); err != nil {
+        t.Fatalf("Restore: %v", err)
+    }
+
+    history, err := s.TaskHistory(ctx, task.ID)
+    if err != nil {
+        t.Fatalf("TaskHistory: %v", err)
+    }
+    var actions []string
+    for _, entry := range history {
+        actions = append(actions, entry.Action)
+        if entry.ActorID == nil || *entry.ActorID != *Actor(ctx) || entry.RequestID != "req-1" {
+            t.Errorf("%s entry by actor %v in request %q, want %d in req-1", entry.Action, entry.ActorID, entry.RequestID, *Actor(ctx))
+        }
+    }
+    if want := []string{AuditCreate, AuditUpdate, AuditDelete, AuditRestore}; !reflect.DeepEqual(actions, want) {
+        t.Fatalf("history actions = %v, want %v", actions, want)
+    }
+
+    if history[0].Before != nil || history[0].After == nil || history[0].After.Title != "Draft" {
+        t.Errorf("create entry = %+v, want no before and the created task after", history[0])
+    }
+    if history[1].Before.Title != "Draft" || !reflect.DeepEqual(*history[1].After, updated) {
+        t.Errorf("update entry %+v -> %+v, want Draft -> %+v", history[1].Before, history[1].After, updated)
+    }
+    if history[2].After == nil || history[2].After.DeletedAt == "" {
+        t.Errorf("delete entry after = %+v, want the trashed task", history[2].After)
+    }
+}
+
+func TestAuditLog(t *testing.T) {
+    s, ctx := newTestStore(t)
+    first := createTask(t, s, ctx, "One", nil)
+    createTask(t, s, ctx, "Two", nil)
+    createTask(t, s, ctx, "Three", nil)
+    if err := s.Delete(ctx, first.ID, DeleteOptions{}); err != nil {
+        t.Fatalf("Delete: %v", err)
+    }
+
+    page, err := s.AuditLog(ctx, AuditFilter{Action: AuditCreate, Limit: 2})
+    if err != nil {
+        t.Fatalf("AuditLog: %v", err)
+    }
+    if len(page.Entries) != 2 || page.Next == 0 {
+        t.Fatalf("first page has %d entries and next %d, want 2 and more to follow", len(page.Entries), page.Next)
+    }
+    if page.Entries[0].ID < page.Entries[1].ID {
+        t.Errorf("entries %d, %d are not newest first", page.Entries[0].ID, page.Entries[1].ID)
+    }
+
+    page, err = s.AuditLog(ctx, AuditFilter{Action: AuditCreate, Limit: 2, Before: page.Next})
+    if err != nil {
+        t.Fatalf("AuditLog: %v", err)
+    }
+    if len(page.Entries) != 1 || page.Next != 0 || page.Entries[0].TaskID != first.ID {
+        t.Errorf("last page = %+v, want only the creation of task %d", page, first.ID)
+    }
+
+    page, err = s.AuditLog(ctx, AuditFilter{TaskID: &first.ID})
+    if err != nil {
+        t.Fatalf("AuditLog: %v", err)
+    }
+    if len(page.Entries) != 2 || page.Entries[0].Action != AuditDelete {
+        t.Errorf("entries of task %d = %+v, want its delete and create", first.ID, page.Entries)
+    }
+
+    other := createWorkspace(t, s, "globex")
+    page, err = s.AuditLog(other, AuditFilter{})
+    if err != nil {
+        t.Fatalf("AuditLog: %v", err)
+    }
+    if len(page.Entries) != 0 {
+        t.Errorf("another workspace sees %d entries", len(page.Entries))
+    }
+    if _, err := s.TaskHistory(other, first.ID); !errors.Is(err, ErrTaskNotFound) {
+        t.Errorf("TaskHistory from another workspace error = %v, want ErrTaskNotFound", err)
+    }
+}
+
@@ -0,0 +1,46 @@
+package database
+
+import (
+    "context"
+    "database/sql"
+)
+
+// BatchStore runs several store calls as a unit
+type BatchStore interface {
+    // InTx runs fn with a Store bound to a single transaction, committed if fn returns nil
+    // and rolled back otherwise
+    InTx(ctx context.Context, fn func(tx Store) error) error
+    // Savepoint runs fn inside a savepoint of the transaction of a Store handed out by InTx.
+    // If fn fails only its writes are undone and the transaction stays usable.
+    Savepoint(ctx context.Context, fn func() error) error
+}
+
+// InTx runs fn in a transaction
+func (s *SQLStore) InTx(ctx context.Context, fn func(tx Store) error) error {
+    return s.withTx(ctx, func(tx *SQLStore) error {
+        return fn(tx)
+    })
+}
+
+// Savepoint runs fn inside a savepoint. Outside a transaction there is nothing to roll
+// back to, so fn just runs.
+func (s *SQLStore) Savepoint(ctx context.Context, fn func() error) error {
+    if _, ok := s.db.(*sql.Tx); !ok {
+        return fn()
+    }
+
+    if _, err := s.exec(ctx, "SAVEPOINT batch_item"); err != nil {
+        return err
+    }
+    if err := fn(); err != nil {
+        if _, rerr := s.exec(ctx, "ROLLBACK TO SAVEPOINT batch_item"); rerr != nil {
+            return rerr
+        }
+        if _, rerr := s.exec(ctx, "RELEASE SAVEPOINT batch_item"); rerr != nil {
+            return rerr
+        }
+        return err
+    }
+    _, err := s.exec(ctx, "RELEASE SAVEPOINT batch_item")
+    return err
+}
@@ -0,0 +1,76 @@
+package database
+
+import (
+    "errors"
+    "testing"
+    "github.com/maazxenon/task-api/models"
+)
+
+func TestInTxRollsBack(t *testing.T) {
+    s, ctx := newTestStore(t)
+    failed := errors.New("operation failed")
+
+    err := s.InTx(ctx, func(tx Store) error {
+        if _, err := tx.Create(ctx, models.Task{Title: "One", Status: "pending"}); err != nil {
+            return err
+        }
+        return failed
+    })
+    if !errors.Is(err, failed) {
+        t.Fatalf("InTx error = %v, want %v", err, failed)
+    }
+
+    page, err := s.List(ctx, TaskFilter{})
+    if err != nil {
+        t.Fatalf("List: %v", err)
+    }
+    audit, err := s.AuditLog(ctx, AuditFilter{})
+    if err != nil {
+        t.Fatalf("AuditLog: %v", err)
+    }
+    if len(page.Tasks) != 0 || len(audit.Entries) != 0 {
+        t.Errorf("rolled back batch left %d tasks and %d audit entries", len(page.Tasks), len(audit.Entries))
+    }
+}
+
+func TestSavepoint(t *testing.T) {
+    s, ctx := newTestStore(t)
+    kept := createTask(t, s, ctx, "Keep", nil)
+    var created models.Task
+
+    err := s.InTx(ctx, func(tx Store) error {
+        err := tx.Savepoint(ctx, func() error {
+            var err error
+            created, err = tx.Create(ctx, models.Task{Title: "Created", Status: "pending"})
+            return err
+        })
+        if err != nil {
+            return err
+        }
+
+        // The failed operation's own writes are undone, the earlier ones stay
+        err = tx.Savepoint(ctx, func() error {
+            if err := tx.Delete(ctx, kept.ID, DeleteOptions{}); err != nil {
+                return err
+            }
+            _, err := tx.Update(ctx, models.Task{ID: kept.ID, Title: "Renamed", Status: "pending"}, 0)
+            return err
+        })
+        if !errors.Is(err, ErrTaskNotFound) {
+            t.Errorf("failing savepoint error = %v, want ErrTaskNotFound", err)
+        }
+
+        _, err = tx.Update(ctx, models.Task{ID: created.ID, Title: "Created and renamed", Status: "pending"}, 0)
+        return err
+    })
+    if err != nil {
+        t.Fatalf("InTx: %v", err)
+    }
+
+    if got, err := s.Get(ctx, kept.ID); err != nil || got.Version != kept.Version {
+        t.Errorf("task changed by the failed operation = %+v, %v, want it untouched", got, err)
+    }
+    if got, err := s.Get(ctx, created.ID); err != nil || got.Title != "Created and renamed" {
+        t.Errorf("task of the committed operations = %+v, %v, want it renamed", got, err)
+    }
+}
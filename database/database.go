@@ -1,28 +1,49 @@
 package database
 
 import (
+    "context"
     "database/sql"
     "log"
+    _ "github.com/lib/pq"
     _ "github.com/mattn/go-sqlite3"
-    "github.com/maazxenon/task-api/models"
 )
 
-var DB *sql.DB
+// Open connects to the database described by dsn without touching its schema
+func Open(dsn string) (*sql.DB, Dialect, error) {
+    dialect, conn := parseDSN(dsn)
+    db, err := sql.Open(dialect.Driver(), conn)
+    if err != nil {
+        return nil, dialect, err
+    }
 
-// InitDB initializes the SQLite database and creates the tasks table if it doesn't exist
-func InitDB() {
-    var err error
-    DB, err = sql.Open("sqlite3", "./app.db")
+    if err := db.Ping(); err != nil {
+        db.Close()
+        return nil, dialect, err
+    }
+
+    return db, dialect, nil
+}
+
+// InitDB opens the database described by dsn and applies any pending migrations
+func InitDB(dsn string) (*sql.DB, Dialect) {
+    db, dialect, err := Open(dsn)
     if err != nil {
-        log.Fatal(err)
+        log.Fatalf("Error connecting to %s database: %v", dialect, err)
     }
 
-    sqlStmt := models.TaskTable
+    migrator, err := NewMigrator(db, dialect)
+    if err != nil {
+        log.Fatalf("Error loading migrations: %v", err)
+    }
 
-    _, err = DB.Exec(sqlStmt)
+    applied, err := migrator.Up(context.Background(), 0)
     if err != nil {
-        log.Fatalf("Error creating table: %q: %s\n", err, sqlStmt)
+        log.Fatalf("Error migrating database: %v", err)
+    }
+    for _, m := range applied {
+        log.Printf("Applied migration %04d_%s", m.Version, m.Name)
     }
 
-    log.Println("Database initialized")
+    log.Printf("Database initialized (%s)", dialect)
+    return db, dialect
 }
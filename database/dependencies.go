@@ -0,0 +1,113 @@
+package database
+
+import (
+    "context"
+    "database/sql"
+    "errors"
+    "github.com/maazxenon/task-api/models"
+)
+
+// Blockers returns the tasks that block the task with the given ID
+func (s *SQLStore) Blockers(ctx context.Context, id int) ([]models.Task, error) {
+    if err := s.exists(ctx, id); err != nil {
+        return nil, err
+    }
+
+    rows, err := s.query(ctx, "SELECT "+qualify("t", taskColumns)+" FROM task_dependencies d JOIN tasks t ON t.id = d.blocked_by_id WHERE d.task_id = ? AND t.deleted_at IS NULL ORDER BY t.id", id)
+    if err != nil {
+        return nil, err
+    }
+    return s.collectTasks(ctx, rows)
+}
+
+// AddDependency records that taskID is blocked by blockerID. Adding an existing edge is a no-op.
+func (s *SQLStore) AddDependency(ctx context.Context, taskID, blockerID int) error {
+    if taskID == blockerID {
+        return ErrDependencyCycle
+    }
+
+    return s.withTx(ctx, func(tx *SQLStore) error {
+        if err := tx.exists(ctx, taskID); err != nil {
+            return err
+        }
+        if err := tx.exists(ctx, blockerID); err != nil {
+            if errors.Is(err, ErrTaskNotFound) {
+                return ErrBlockerNotFound
+            }
+            return err
+        }
+
+        // The edge closes a cycle if the blocker already waits, directly or not, on the task
+        var found int
+        err := tx.queryRow(ctx, `WITH RECURSIVE chain(id) AS (
+            SELECT blocked_by_id FROM task_dependencies WHERE task_id = ?
+            UNION
+            SELECT d.blocked_by_id FROM task_dependencies d JOIN chain c ON d.task_id = c.id
+        ) SELECT 1 FROM chain WHERE id = ?`, blockerID, taskID).Scan(&found)
+        if err == nil {
+            return ErrDependencyCycle
+        }
+        if !errors.Is(err, sql.ErrNoRows) {
+            return err
+        }
+
+        _, err = tx.exec(ctx, "INSERT INTO task_dependencies(task_id, blocked_by_id) VALUES(?, ?) ON CONFLICT DO NOTHING", taskID, blockerID)
+        return err
+    })
+}
+
+// RemoveDependency deletes the edge between taskID and blockerID
+func (s *SQLStore) RemoveDependency(ctx context.Context, taskID, blockerID int) error {
+    if err := s.exists(ctx, taskID); err != nil {
+        return err
+    }
+
+    result, err := s.exec(ctx, "DELETE FROM task_dependencies WHERE task_id = ? AND blocked_by_id = ?", taskID, blockerID)
+    if err != nil {
+        return err
+    }
+
+    err = checkAffected(result)
+    if errors.Is(err, ErrTaskNotFound) {
+        return ErrDependencyNotFound
+    }
+    return err
+}
+
+// DependencyGraph returns tasks and the dependency edges between them. Trashed tasks are
+// left out, and so are completed tasks and their edges unless includeCompleted is set.
+func (s *SQLStore) DependencyGraph(ctx context.Context, includeCompleted bool) ([]models.Task, []models.Dependency, error) {
+    taskQuery := "SELECT " + taskColumns + " FROM tasks WHERE workspace_id = ? AND " + liveTask + " AND " + activeProject
+    edgeQuery := `SELECT d.task_id, d.blocked_by_id FROM task_dependencies d
+        JOIN tasks t ON t.id = d.task_id JOIN tasks b ON b.id = d.blocked_by_id
+        WHERE t.workspace_id = ? AND t.deleted_at IS NULL AND b.deleted_at IS NULL`
+    if !includeCompleted {
+        taskQuery += " AND status <> 'completed'"
+        edgeQuery += " AND t.status <> 'completed' AND b.status <> 'completed'"
+    }
+
+    rows, err := s.query(ctx, taskQuery+" ORDER BY id", WorkspaceID(ctx))
+    if err != nil {
+        return nil, nil, err
+    }
+    tasks, err := s.collectTasks(ctx, rows)
+    if err != nil {
+        return nil, nil, err
+    }
+
+    rows, err = s.query(ctx, edgeQuery, WorkspaceID(ctx))
+    if err != nil {
+        return nil, nil, err
+    }
+    defer rows.Close()
+
+    edges := []models.Dependency{}
+    for rows.Next() {
+        var edge models.Dependency
+        if err := rows.Scan(&edge.TaskID, &edge.BlockedByID); err != nil {
+            return nil, nil, err
+        }
+        edges = append(edges, edge)
+    }
+    return tasks, edges, rows.Err()
+}
@@ -0,0 +1,82 @@
+package database
+
+import (
+    "strconv"
+    "strings"
+)
+
+// Dialect identifies the SQL flavour spoken by the underlying database
+type Dialect string
+
+const (
+    // SQLite is the dialect of the embedded go-sqlite3 driver
+    SQLite Dialect = "sqlite3"
+    // Postgres is the dialect of the lib/pq driver
+    Postgres Dialect = "postgres"
+)
+
+// Driver returns the database/sql driver name registered for the dialect
+func (d Dialect) Driver() string {
+    return string(d)
+}
+
+// Rebind rewrites the ? placeholders in query into the dialect's native form.
+// Queries in this package never contain a literal question mark, so a plain scan is enough.
+func (d Dialect) Rebind(query string) string {
+    if d != Postgres {
+        return query
+    }
+
+    var b strings.Builder
+    b.Grow(len(query) + 8)
+    n := 0
+    for _, r := range query {
+        if r == '?' {
+            n++
+            b.WriteByte('$')
+            b.WriteString(strconv.Itoa(n))
+            continue
+        }
+        b.WriteRune(r)
+    }
+    return b.String()
+}
+
+// parseDSN picks the dialect from a DSN and returns the connection string for its driver.
+// postgres:// and postgresql:// URLs select PostgreSQL; sqlite:// URLs, file: URIs and
+// bare paths select SQLite.
+func parseDSN(dsn string) (Dialect, string) {
+    switch {
+    case strings.HasPrefix(dsn, "postgres://"), strings.HasPrefix(dsn, "postgresql://"):
+        return Postgres, dsn
+    case strings.HasPrefix(dsn, "sqlite://"):
+        return SQLite, sqliteConn(strings.TrimPrefix(dsn, "sqlite://"))
+    case strings.HasPrefix(dsn, "sqlite3://"):
+        return SQLite, sqliteConn(strings.TrimPrefix(dsn, "sqlite3://"))
+    }
+    return SQLite, sqliteConn(dsn)
+}
+
+// sqliteParams are the go-sqlite3 connection parameters added unless the DSN sets them.
+// Transactions take the write lock when they begin, so a quota counted in one still holds
+// when it inserts, and foreign keys are enforced so their ON DELETE actions run as they
+// do on PostgreSQL.
+var sqliteParams = []struct{ name, value string }{
+    {"_txlock", "immediate"},
+    {"_foreign_keys", "1"},
+}
+
+// sqliteConn adds the missing sqliteParams to a SQLite connection string
+func sqliteConn(conn string) string {
+    for _, p := range sqliteParams {
+        if strings.Contains(conn, p.name+"=") {
+            continue
+        }
+        sep := "?"
+        if strings.Contains(conn, "?") {
+            sep = "&"
+        }
+        conn += sep + p.name + "=" + p.value
+    }
+    return conn
+}
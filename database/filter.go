@@ -0,0 +1,66 @@
+package database
+
+import (
+    "strings"
+    "github.com/maazxenon/task-api/models"
+)
+
+// DefaultPageSize and MaxPageSize bound the number of tasks returned by List
+const (
+    DefaultPageSize = 50
+    MaxPageSize     = 200
+)
+
+// SortFields lists the task fields List can order by
+var SortFields = map[string]string{
+    "id":       "id",
+    "title":    "title",
+    "due_date": "due_date",
+    "status":   "status",
+}
+
+// TaskFilter narrows, orders and paginates the tasks returned by List
+type TaskFilter struct {
+    // Status matches tasks with exactly this status
+    Status string
+    // DueAfter and DueBefore are inclusive bounds on the due date
+    DueAfter  string
+    DueBefore string
+    // Title matches tasks whose title contains this text, ignoring case
+    Title string
+    // ProjectID matches tasks in this project
+    ProjectID *int
+    // AssigneeID matches tasks assigned to this user, Unassigned tasks assigned to nobody
+    AssigneeID *int
+    Unassigned bool
+    // IncludeArchived keeps tasks of archived projects, which are left out by default
+    IncludeArchived bool
+    // TagsAny matches tasks carrying at least one of these tags, TagsAll those carrying all of them
+    TagsAny []string
+    TagsAll []string
+    // Sort is one of the keys of SortFields; it defaults to id
+    Sort string
+    Desc bool
+    // Limit is the page size; it defaults to DefaultPageSize
+    Limit int
+    // After continues a previous listing from the position it returned in TaskPage.Next
+    After *Cursor
+}
+
+// Cursor marks the last task of a page in keyset order
+type Cursor struct {
+    Value string `json:"v"`
+    ID    int    `json:"id"`
+}
+
+// TaskPage is one page of a listing
+type TaskPage struct {
+    Tasks []models.Task
+    // Next is nil on the last page
+    Next *Cursor
+}
+
+// escapeLike escapes the LIKE wildcards in s so it matches literally with ESCAPE '\'
+func escapeLike(s string) string {
+    return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
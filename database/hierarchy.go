@@ -0,0 +1,119 @@
+package database
+
+import (
+    "context"
+    "database/sql"
+    "errors"
+    "github.com/maazxenon/task-api/models"
+)
+
+// Children returns the direct subtasks of a task
+func (s *SQLStore) Children(ctx context.Context, id int) ([]models.Task, error) {
+    if err := s.exists(ctx, id); err != nil {
+        return nil, err
+    }
+
+    rows, err := s.query(ctx, "SELECT "+taskColumns+" FROM tasks WHERE parent_id = ? AND workspace_id = ? AND "+liveTask+" ORDER BY id", id, WorkspaceID(ctx))
+    if err != nil {
+        return nil, err
+    }
+    return s.collectTasks(ctx, rows)
+}
+
+// Subtree returns a task followed by all of its descendants, parents before their children
+func (s *SQLStore) Subtree(ctx context.Context, id int) ([]models.Task, error) {
+    rows, err := s.query(ctx, `WITH RECURSIVE subtree(id, depth) AS (
+        SELECT id, 0 FROM tasks WHERE id = ? AND workspace_id = ? AND deleted_at IS NULL
+        UNION ALL
+        SELECT t.id, st.depth + 1 FROM tasks t JOIN subtree st ON t.parent_id = st.id WHERE t.deleted_at IS NULL
+    ) SELECT `+qualify("t", taskColumns)+` FROM subtree st JOIN tasks t ON t.id = st.id ORDER BY st.depth, t.id`, id, WorkspaceID(ctx))
+    if err != nil {
+        return nil, err
+    }
+
+    tasks, err := s.collectTasks(ctx, rows)
+    if err != nil {
+        return nil, err
+    }
+    if len(tasks) == 0 {
+        return nil, ErrTaskNotFound
+    }
+    return tasks, nil
+}
+
+// checkParent verifies that parentID can become the parent of task id. Pass 0 for a
+// task that doesn't exist yet; it can't be anyone's ancestor.
+func (s *SQLStore) checkParent(ctx context.Context, id int, parentID *int) error {
+    if parentID == nil {
+        return nil
+    }
+    if *parentID == id {
+        return ErrHierarchyCycle
+    }
+
+    if err := s.exists(ctx, *parentID); err != nil {
+        if errors.Is(err, ErrTaskNotFound) {
+            return ErrParentNotFound
+        }
+        return err
+    }
+
+    if id == 0 {
+        return nil
+    }
+
+    // Walk up from the new parent; meeting the task on the way means it would become its own ancestor
+    var found int
+    err := s.queryRow(ctx, `WITH RECURSIVE ancestors(id) AS (
+        SELECT parent_id FROM tasks WHERE id = ?
+        UNION
+        SELECT t.parent_id FROM tasks t JOIN ancestors a ON t.id = a.id
+    ) SELECT 1 FROM ancestors WHERE id = ?`, *parentID, id).Scan(&found)
+    if errors.Is(err, sql.ErrNoRows) {
+        return nil
+    }
+    if err != nil {
+        return err
+    }
+    return ErrHierarchyCycle
+}
+
+// exists returns ErrTaskNotFound unless a task with the given ID exists in the workspace
+// outside of the trash
+func (s *SQLStore) exists(ctx context.Context, id int) error {
+    var found int
+    err := s.queryRow(ctx, "SELECT 1 FROM tasks WHERE id = ? AND workspace_id = ? AND "+liveTask, id, WorkspaceID(ctx)).Scan(&found)
+    if errors.Is(err, sql.ErrNoRows) {
+        return ErrTaskNotFound
+    }
+    return err
+}
+
+// collectTasks scans and closes rows selected with taskColumns, then loads the tasks' tags
+func (s *SQLStore) collectTasks(ctx context.Context, rows *sql.Rows) ([]models.Task, error) {
+    tasks, err := scanTasks(rows)
+    if err != nil {
+        return nil, err
+    }
+
+    ptrs := make([]*models.Task, len(tasks))
+    for i := range tasks {
+        ptrs[i] = &tasks[i]
+    }
+    return tasks, s.loadTags(ctx, ptrs...)
+}
+
+// scanTasks scans and closes rows selected with taskColumns
+func scanTasks(rows *sql.Rows) ([]models.Task, error) {
+    defer rows.Close()
+
+    tasks := []models.Task{}
+    for rows.Next() {
+        task, err := scanTask(rows)
+        if err != nil {
+            return nil, err
+        }
+        tasks = append(tasks, task)
+    }
+    return tasks, rows.Err()
+}
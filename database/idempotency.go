@@ -0,0 +1,113 @@
+package database
+
+import (
+    "context"
+    "database/sql"
+    "errors"
+    "time"
+    "github.com/maazxenon/task-api/dates"
+)
+
+// ErrIdempotencyKeyReused is returned when an idempotency key comes back with a different request
+var ErrIdempotencyKeyReused = errors.New("idempotency key was already used with a different request")
+
+// ErrIdempotencyKeyInProgress is returned when the request that claimed a key hasn't finished yet
+var ErrIdempotencyKeyInProgress = errors.New("a request with this idempotency key is still in progress")
+
+// IdempotentResponse is the response stored for a request made with an idempotency key
+type IdempotentResponse struct {
+    Status int
+    ETag   string
+    Body   []byte
+}
+
+// IdempotencyStore is the persistence layer used to answer retried requests
+type IdempotencyStore interface {
+    // ClaimIdempotencyKey reserves key for the acting user of ctx, returning nil. If the key
+    // was already used since notBefore it returns the stored response instead, or
+    // ErrIdempotencyKeyReused if fingerprint doesn't match the first request. Claimed inside
+    // InTx, the key is released again if the transaction rolls back.
+    ClaimIdempotencyKey(ctx context.Context, key, fingerprint string, notBefore time.Time) (*IdempotentResponse, error)
+    // SaveIdempotentResponse stores the response to the request that claimed key
+    SaveIdempotentResponse(ctx context.Context, key string, response IdempotentResponse) error
+    // PurgeIdempotencyKeys removes the keys of every workspace used before the given time
+    // and returns how many there were
+    PurgeIdempotencyKeys(ctx context.Context, before time.Time) (int, error)
+}
+
+// idempotencyUser returns the user whose keys ctx sees; keys are never shared between users
+func idempotencyUser(ctx context.Context) int {
+    if actor := Actor(ctx); actor != nil {
+        return *actor
+    }
+    return 0
+}
+
+// ClaimIdempotencyKey reserves an idempotency key or returns its stored response
+func (s *SQLStore) ClaimIdempotencyKey(ctx context.Context, key, fingerprint string, notBefore time.Time) (*IdempotentResponse, error) {
+    var stored *IdempotentResponse
+    err := s.withTx(ctx, func(tx *SQLStore) error {
+        // an expired key is free to be used again
+        if _, err := tx.exec(ctx, "DELETE FROM idempotency_keys WHERE workspace_id = ? AND user_id = ? AND idempotency_key = ? AND created_at < ?",
+            WorkspaceID(ctx), idempotencyUser(ctx), key, dates.Format(notBefore)); err != nil {
+            return err
+        }
+
+        result, err := tx.exec(ctx, `INSERT INTO idempotency_keys(workspace_id, user_id, idempotency_key, fingerprint, created_at)
+            VALUES(?, ?, ?, ?, ?) ON CONFLICT DO NOTHING`, WorkspaceID(ctx), idempotencyUser(ctx), key, fingerprint, dates.Format(time.Now()))
+        if err != nil {
+            return err
+        }
+        if n, err := result.RowsAffected(); err != nil || n == 1 {
+            return err
+        }
+
+        var response IdempotentResponse
+        var storedFingerprint, body string
+        err = tx.queryRow(ctx, "SELECT fingerprint, status, etag, body FROM idempotency_keys WHERE workspace_id = ? AND user_id = ? AND idempotency_key = ?",
+            WorkspaceID(ctx), idempotencyUser(ctx), key).Scan(&storedFingerprint, &response.Status, &response.ETag, &body)
+        if errors.Is(err, sql.ErrNoRows) {
+            // expired and purged in between; another retry claims the key afresh
+            return ErrIdempotencyKeyInProgress
+        }
+        if err != nil {
+            return err
+        }
+        if storedFingerprint != fingerprint {
+            return ErrIdempotencyKeyReused
+        }
+        if response.Status == 0 {
+            return ErrIdempotencyKeyInProgress
+        }
+        response.Body = []byte(body)
+        stored = &response
+        return nil
+    })
+    return stored, err
+}
+
+// SaveIdempotentResponse stores the response to a claimed key
+func (s *SQLStore) SaveIdempotentResponse(ctx context.Context, key string, response IdempotentResponse) error {
+    result, err := s.exec(ctx, "UPDATE idempotency_keys SET status = ?, etag = ?, body = ? WHERE workspace_id = ? AND user_id = ? AND idempotency_key = ?",
+        response.Status, response.ETag, string(response.Body), WorkspaceID(ctx), idempotencyUser(ctx), key)
+    if err != nil {
+        return err
+    }
+    if n, err := result.RowsAffected(); err != nil || n != 1 {
+        if err == nil {
+            err = errors.New("idempotency key " + key + " was not claimed")
+        }
+        return err
+    }
+    return nil
+}
+
+// PurgeIdempotencyKeys removes the keys used before the given time
+func (s *SQLStore) PurgeIdempotencyKeys(ctx context.Context, before time.Time) (int, error) {
+    result, err := s.exec(ctx, "DELETE FROM idempotency_keys WHERE created_at < ?", dates.Format(before))
+    if err != nil {
+        return 0, err
+    }
+    n, err := result.RowsAffected()
+    return int(n), err
+}
@@ -0,0 +1,79 @@
+package database
+
+import (
+    "errors"
+    "reflect"
+    "testing"
+    "time"
+)
+
+func TestClaimIdempotencyKey(t *testing.T) {
+    s, ctx := newTestStore(t)
+    notBefore := time.Now().Add(-time.Hour)
+
+    if stored, err := s.ClaimIdempotencyKey(ctx, "k1", "POST /tasks", notBefore); stored != nil || err != nil {
+        t.Fatalf("first claim = %v, %v, want nil, nil", stored, err)
+    }
+    if _, err := s.ClaimIdempotencyKey(ctx, "k1", "POST /tasks", notBefore); !errors.Is(err, ErrIdempotencyKeyInProgress) {
+        t.Errorf("claim before the response is saved error = %v, want ErrIdempotencyKeyInProgress", err)
+    }
+
+    response := IdempotentResponse{Status: 200, ETag: `"1"`, Body: []byte(`{"id":1}`)}
+    if err := s.SaveIdempotentResponse(ctx, "k1", response); err != nil {
+        t.Fatalf("SaveIdempotentResponse: %v", err)
+    }
+    stored, err := s.ClaimIdempotencyKey(ctx, "k1", "POST /tasks", notBefore)
+    if err != nil {
+        t.Fatalf("retry: %v", err)
+    }
+    if stored == nil || !reflect.DeepEqual(*stored, response) {
+        t.Errorf("retry = %+v, want the saved response %+v", stored, response)
+    }
+    if _, err := s.ClaimIdempotencyKey(ctx, "k1", "POST /tasks?tz=UTC", notBefore); !errors.Is(err, ErrIdempotencyKeyReused) {
+        t.Errorf("claim for another request error = %v, want ErrIdempotencyKeyReused", err)
+    }
+
+    // Keys belong to the user who sent them
+    if stored, err := s.ClaimIdempotencyKey(WithActor(ctx, *Actor(ctx)+1), "k1", "POST /tasks?tz=UTC", notBefore); stored != nil || err != nil {
+        t.Errorf("claim by another user = %v, %v, want nil, nil", stored, err)
+    }
+    // and expire
+    if stored, err := s.ClaimIdempotencyKey(ctx, "k1", "POST /tasks?tz=UTC", time.Now().Add(time.Hour)); stored != nil || err != nil {
+        t.Errorf("claim of an expired key = %v, %v, want nil, nil", stored, err)
+    }
+}
+
+func TestClaimIdempotencyKeyRollsBack(t *testing.T) {
+    s, ctx := newTestStore(t)
+    notBefore := time.Now().Add(-time.Hour)
+    failed := errors.New("create failed")
+
+    err := s.InTx(ctx, func(tx Store) error {
+        if _, err := tx.ClaimIdempotencyKey(ctx, "k1", "POST /tasks", notBefore); err != nil {
+            return err
+        }
+        return failed
+    })
+    if !errors.Is(err, failed) {
+        t.Fatalf("InTx error = %v, want %v", err, failed)
+    }
+    if stored, err := s.ClaimIdempotencyKey(ctx, "k1", "POST /tasks", notBefore); stored != nil || err != nil {
+        t.Errorf("claim after a rolled back request = %v, %v, want nil, nil", stored, err)
+    }
+}
+
+func TestPurgeIdempotencyKeys(t *testing.T) {
+    s, ctx := newTestStore(t)
+    for _, key := range []string{"k1", "k2"} {
+        if _, err := s.ClaimIdempotencyKey(ctx, key, "POST /tasks", time.Now().Add(-time.Hour)); err != nil {
+            t.Fatalf("ClaimIdempotencyKey(%q): %v", key, err)
+        }
+    }
+
+    if purged, err := s.PurgeIdempotencyKeys(ctx, time.Now().Add(-time.Hour)); purged != 0 || err != nil {
+        t.Errorf("PurgeIdempotencyKeys before the keys = %d, %v, want 0, nil", purged, err)
+    }
+    if purged, err := s.PurgeIdempotencyKeys(ctx, time.Now().Add(time.Hour)); purged != 2 || err != nil {
+        t.Errorf("PurgeIdempotencyKeys after the keys = %d, %v, want 2, nil", purged, err)
+    }
+}
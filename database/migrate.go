@@ -0,0 +1,291 @@
+package database
+
+import (
+    "context"
+    "crypto/sha256"
+    "database/sql"
+    "embed"
+    "encoding/hex"
+    "errors"
+    "fmt"
+    "io/fs"
+    "path"
+    "sort"
+    "strconv"
+    "strings"
+    "time"
+)
+
+//go:embed migrations
+var migrationFiles embed.FS
+
+// ErrChecksumMismatch is returned when an applied migration no longer matches its embedded source
+var ErrChecksumMismatch = errors.New("migration checksum mismatch")
+
+const schemaMigrationsTable = `
+CREATE TABLE IF NOT EXISTS schema_migrations (
+    version INTEGER NOT NULL PRIMARY KEY,
+    name TEXT NOT NULL,
+    checksum TEXT NOT NULL,
+    applied_at TEXT NOT NULL
+);`
+
+// Migration is one versioned schema change with its rollback
+type Migration struct {
+    Version  int
+    Name     string
+    Up       string
+    Down     string
+    Checksum string
+}
+
+// MigrationStatus describes whether a migration has been applied
+type MigrationStatus struct {
+    Migration
+    Applied   bool
+    AppliedAt string
+    // Modified is set when the applied checksum differs from the embedded migration
+    Modified bool
+}
+
+// Migrator applies the embedded migrations for a dialect
+type Migrator struct {
+    db         *sql.DB
+    dialect    Dialect
+    migrations []Migration
+}
+
+// NewMigrator loads the migrations embedded for dialect
+func NewMigrator(db *sql.DB, dialect Dialect) (*Migrator, error) {
+    migrations, err := loadMigrations(dialect)
+    if err != nil {
+        return nil, err
+    }
+    return &Migrator{db: db, dialect: dialect, migrations: migrations}, nil
+}
+
+// loadMigrations reads migrations/<dialect>/NNNN_name.{up,down}.sql in version order
+func loadMigrations(dialect Dialect) ([]Migration, error) {
+    dir := "migrations/" + migrationDir(dialect)
+    entries, err := fs.ReadDir(migrationFiles, dir)
+    if err != nil {
+        return nil, err
+    }
+
+    byVersion := map[int]*Migration{}
+    for _, entry := range entries {
+        file := entry.Name()
+        var direction string
+        switch {
+        case strings.HasSuffix(file, ".up.sql"):
+            direction = "up"
+        case strings.HasSuffix(file, ".down.sql"):
+            direction = "down"
+        default:
+            continue
+        }
+
+        base := strings.TrimSuffix(file, "."+direction+".sql")
+        prefix, name, ok := strings.Cut(base, "_")
+        if !ok {
+            return nil, fmt.Errorf("migration %s: expected NNNN_name.%s.sql", file, direction)
+        }
+        version, err := strconv.Atoi(prefix)
+        if err != nil {
+            return nil, fmt.Errorf("migration %s: invalid version: %w", file, err)
+        }
+
+        body, err := fs.ReadFile(migrationFiles, path.Join(dir, file))
+        if err != nil {
+            return nil, err
+        }
+
+        m, ok := byVersion[version]
+        if !ok {
+            m = &Migration{Version: version, Name: name}
+            byVersion[version] = m
+        }
+        if direction == "up" {
+            m.Up = string(body)
+        } else {
+            m.Down = string(body)
+        }
+    }
+
+    migrations := make([]Migration, 0, len(byVersion))
+    for _, m := range byVersion {
+        if m.Up == "" || m.Down == "" {
+            return nil, fmt.Errorf("migration %04d_%s: missing up or down step", m.Version, m.Name)
+        }
+        sum := sha256.Sum256([]byte(m.Up + "\x00" + m.Down))
+        m.Checksum = hex.EncodeToString(sum[:])
+        migrations = append(migrations, *m)
+    }
+    sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+    return migrations, nil
+}
+
+func migrationDir(dialect Dialect) string {
+    if dialect == Postgres {
+        return "postgres"
+    }
+    return "sqlite"
+}
+
+// Status reports every known migration and whether it has been applied
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+    applied, err := m.applied(ctx)
+    if err != nil {
+        return nil, err
+    }
+
+    statuses := make([]MigrationStatus, 0, len(m.migrations))
+    for _, migration := range m.migrations {
+        status := MigrationStatus{Migration: migration}
+        if row, ok := applied[migration.Version]; ok {
+            status.Applied = true
+            status.AppliedAt = row.appliedAt
+            status.Modified = row.checksum != migration.Checksum
+        }
+        statuses = append(statuses, status)
+    }
+    return statuses, nil
+}
+
+// Up applies pending migrations up to and including target; a target of 0 applies all of them.
+// It returns the migrations that were applied.
+func (m *Migrator) Up(ctx context.Context, target int) ([]Migration, error) {
+    applied, err := m.applied(ctx)
+    if err != nil {
+        return nil, err
+    }
+    if err := m.verify(applied); err != nil {
+        return nil, err
+    }
+
+    var done []Migration
+    for _, migration := range m.migrations {
+        if target > 0 && migration.Version > target {
+            break
+        }
+        if _, ok := applied[migration.Version]; ok {
+            continue
+        }
+
+        err := m.inTx(ctx, func(tx *sql.Tx) error {
+            if _, err := tx.ExecContext(ctx, migration.Up); err != nil {
+                return err
+            }
+            _, err := tx.ExecContext(ctx, m.dialect.Rebind("INSERT INTO schema_migrations(version, name, checksum, applied_at) VALUES(?, ?, ?, ?)"),
+                migration.Version, migration.Name, migration.Checksum, time.Now().UTC().Format(time.RFC3339))
+            return err
+        })
+        if err != nil && m.dialect == SQLite && strings.Contains(err.Error(), "no such module: fts5") {
+            err = fmt.Errorf("%w (build with -tags sqlite_fts5 to enable full-text search)", err)
+        }
+        if err != nil {
+            return done, fmt.Errorf("applying migration %04d_%s: %w", migration.Version, migration.Name, err)
+        }
+        done = append(done, migration)
+    }
+    return done, nil
+}
+
+// Down rolls back the most recently applied migrations, steps at a time.
+// It returns the migrations that were rolled back.
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+    applied, err := m.applied(ctx)
+    if err != nil {
+        return nil, err
+    }
+    if err := m.verify(applied); err != nil {
+        return nil, err
+    }
+
+    var done []Migration
+    for i := len(m.migrations) - 1; i >= 0 && len(done) < steps; i-- {
+        migration := m.migrations[i]
+        if _, ok := applied[migration.Version]; !ok {
+            continue
+        }
+
+        err := m.inTx(ctx, func(tx *sql.Tx) error {
+            if _, err := tx.ExecContext(ctx, migration.Down); err != nil {
+                return err
+            }
+            _, err := tx.ExecContext(ctx, m.dialect.Rebind("DELETE FROM schema_migrations WHERE version = ?"), migration.Version)
+            return err
+        })
+        if err != nil {
+            return done, fmt.Errorf("rolling back migration %04d_%s: %w", migration.Version, migration.Name, err)
+        }
+        done = append(done, migration)
+    }
+    return done, nil
+}
+
+type appliedMigration struct {
+    checksum  string
+    appliedAt string
+}
+
+// applied returns the rows of schema_migrations keyed by version, creating the table if needed
+func (m *Migrator) applied(ctx context.Context) (map[int]appliedMigration, error) {
+    if _, err := m.db.ExecContext(ctx, schemaMigrationsTable); err != nil {
+        return nil, err
+    }
+
+    rows, err := m.db.QueryContext(ctx, "SELECT version, checksum, applied_at FROM schema_migrations")
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    applied := map[int]appliedMigration{}
+    for rows.Next() {
+        var version int
+        var row appliedMigration
+        if err := rows.Scan(&version, &row.checksum, &row.appliedAt); err != nil {
+            return nil, err
+        }
+        applied[version] = row
+    }
+    return applied, rows.Err()
+}
+
+// verify refuses to run when an applied migration was edited after the fact
+func (m *Migrator) verify(applied map[int]appliedMigration) error {
+    for _, migration := range m.migrations {
+        if row, ok := applied[migration.Version]; ok && row.checksum != migration.Checksum {
+            return fmt.Errorf("%w: %04d_%s", ErrChecksumMismatch, migration.Version, migration.Name)
+        }
+    }
+    return nil
+}
+
+// inTx runs fn in a transaction. SQLite migrations rebuild tables by dropping them, which
+// would fire the ON DELETE actions of the tables referencing them, so they run on a
+// connection with foreign keys off; SQLite ignores the pragma inside a transaction.
+func (m *Migrator) inTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+    conn, err := m.db.Conn(ctx)
+    if err != nil {
+        return err
+    }
+    defer conn.Close()
+    if m.dialect == SQLite {
+        if _, err := conn.ExecContext(ctx, "PRAGMA foreign_keys = OFF"); err != nil {
+            return err
+        }
+        defer conn.ExecContext(context.Background(), "PRAGMA foreign_keys = ON")
+    }
+
+    tx, err := conn.BeginTx(ctx, nil)
+    if err != nil {
+        return err
+    }
+    if err := fn(tx); err != nil {
+        tx.Rollback()
+        return err
+    }
+    return tx.Commit()
+}
@@ -0,0 +1,132 @@
+package database
+
+import (
+    "context"
+    "errors"
+    "reflect"
+    "testing"
+)
+
+func TestMigrationsMatchAcrossDialects(t *testing.T) {
+    versions := func(dialect Dialect) []string {
+        migrations, err := loadMigrations(dialect)
+        if err != nil {
+            t.Fatalf("loadMigrations(%s): %v", dialect, err)
+        }
+        names := make([]string, len(migrations))
+        for i, m := range migrations {
+            if m.Up == "" || m.Down == "" {
+                t.Errorf("%s migration %04d_%s lacks an up or down script", dialect, m.Version, m.Name)
+            }
+            names[i] = m.Name
+        }
+        return names
+    }
+    if sqlite, postgres := versions(SQLite), versions(Postgres); !reflect.DeepEqual(sqlite, postgres) {
+        t.Errorf("SQLite migrations %v differ from PostgreSQL migrations %v", sqlite, postgres)
+    }
+}
+
+func TestMigrationsRoundTrip(t *testing.T) {
+    ctx := context.Background()
+    db := openTestDB(t)
+    migrator := migrateTestDB(t, db, 0)
+
+    statuses, err := migrator.Status(ctx)
+    if err != nil {
+        t.Fatalf("Status: %v", err)
+    }
+    for _, status := range statuses {
+        if !status.Applied || status.Modified {
+            t.Errorf("migration %04d_%s applied %v, modified %v after Up", status.Version, status.Name, status.Applied, status.Modified)
+        }
+    }
+
+    undone, err := migrator.Down(ctx, len(statuses))
+    if err != nil {
+        t.Fatalf("Down: %v", err)
+    }
+    if len(undone) != len(statuses) {
+        t.Errorf("Down rolled back %d migrations, want %d", len(undone), len(statuses))
+    }
+    var tables []string
+    rows, err := db.Query("SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%' AND name <> 'schema_migrations' ORDER BY name")
+    if err != nil {
+        t.Fatalf("listing tables: %v", err)
+    }
+    for rows.Next() {
+        var name string
+        if err := rows.Scan(&name); err != nil {
+            t.Fatalf("listing tables: %v", err)
+        }
+        tables = append(tables, name)
+    }
+    rows.Close()
+    if len(tables) != 0 {
+        t.Errorf("tables left after rolling back every migration: %v", tables)
+    }
+
+    redone, err := migrator.Up(ctx, 0)
+    if err != nil {
+        t.Fatalf("Up after Down: %v", err)
+    }
+    if len(redone) != len(statuses) {
+        t.Errorf("Up reapplied %d migrations, want %d", len(redone), len(statuses))
+    }
+}
+
+func TestMigrationsRefuseEditedMigration(t *testing.T) {
+    ctx := context.Background()
+    db := openTestDB(t)
+    migrator := migrateTestDB(t, db, 0)
+
+    if _, err := db.Exec("UPDATE schema_migrations SET checksum = 'edited' WHERE version = 1"); err != nil {
+        t.Fatalf("editing checksum: %v", err)
+    }
+    if _, err := migrator.Up(ctx, 0); !errors.Is(err, ErrChecksumMismatch) {
+        t.Errorf("Up error = %v, want ErrChecksumMismatch", err)
+    }
+    if _, err := migrator.Down(ctx, 1); !errors.Is(err, ErrChecksumMismatch) {
+        t.Errorf("Down error = %v, want ErrChecksumMismatch", err)
+    }
+}
+
+func TestMigrationsKeepReferencesWithForeignKeys(t *testing.T) {
+    db := openTestDB(t)
+    migrator := migrateTestDB(t, db, 13)
+
+    // 0014 rebuilds the tags and users tables, which must not cascade to the rows
+    // referencing them now that foreign keys are enforced
+    for _, stmt := range []string{
+        "INSERT INTO users(id, name, email, password_hash, role) VALUES(1, 'Ada', 'ada@example.com', '', 'admin')",
+        "INSERT INTO tasks(id, title, description, status, assignee_id, created_by) VALUES(1, 'Ship it', '', 'pending', 1, 1)",
+        "INSERT INTO tags(id, name) VALUES(1, 'release')",
+        "INSERT INTO task_tags(task_id, tag_id) VALUES(1, 1)",
+        "INSERT INTO api_keys(user_id, name, prefix, key_hash, scopes, created_at) VALUES(1, 'ci', 'tk_', 'hash', 'tasks:read', '2024-01-01T00:00:00Z')",
+    } {
+        if _, err := db.Exec(stmt); err != nil {
+            t.Fatalf("%s: %v", stmt, err)
+        }
+    }
+    if _, err := migrator.Up(context.Background(), 0); err != nil {
+        t.Fatalf("Up: %v", err)
+    }
+
+    var tags, keys, assigned int
+    err := db.QueryRow(`SELECT (SELECT COUNT(*) FROM task_tags), (SELECT COUNT(*) FROM api_keys),
+        (SELECT COUNT(*) FROM tasks WHERE assignee_id = 1 AND created_by = 1)`).Scan(&tags, &keys, &assigned)
+    if err != nil {
+        t.Fatalf("counting references: %v", err)
+    }
+    if tags != 1 || keys != 1 || assigned != 1 {
+        t.Errorf("after migrating: %d task tags, %d API keys, %d assigned tasks, want 1 each", tags, keys, assigned)
+    }
+
+    var enforced bool
+    if err := db.QueryRow("PRAGMA foreign_keys").Scan(&enforced); err != nil {
+        t.Fatalf("PRAGMA foreign_keys: %v", err)
+    }
+    if !enforced {
+        t.Error("foreign keys are not enforced after migrating")
+    }
+}
@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS tasks (
+    id SERIAL PRIMARY KEY,
+    title TEXT,
+    description TEXT,
+    due_date TEXT,
+    status TEXT
+);
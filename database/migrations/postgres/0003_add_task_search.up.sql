@@ -0,0 +1,6 @@
+ALTER TABLE tasks ADD COLUMN search tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('english', coalesce(title, '')), 'A') ||
+    setweight(to_tsvector('english', coalesce(description, '')), 'B')
+) STORED;
+
+CREATE INDEX tasks_search_idx ON tasks USING GIN (search);
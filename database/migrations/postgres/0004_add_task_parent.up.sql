@@ -0,0 +1,2 @@
+ALTER TABLE tasks ADD COLUMN parent_id INTEGER REFERENCES tasks(id);
+CREATE INDEX tasks_parent_id_idx ON tasks(parent_id);
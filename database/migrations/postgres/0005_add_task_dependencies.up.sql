@@ -0,0 +1,6 @@
+CREATE TABLE task_dependencies (
+    task_id INTEGER NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    blocked_by_id INTEGER NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    PRIMARY KEY (task_id, blocked_by_id)
+);
+CREATE INDEX task_dependencies_blocked_by_idx ON task_dependencies(blocked_by_id);
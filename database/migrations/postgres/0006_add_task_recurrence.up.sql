@@ -0,0 +1,2 @@
+ALTER TABLE tasks ADD COLUMN recurrence TEXT NOT NULL DEFAULT '';
+ALTER TABLE tasks ADD COLUMN next_occurrence_id INTEGER REFERENCES tasks(id) ON DELETE SET NULL;
@@ -0,0 +1,4 @@
+-- Tasks still without a due date get their unconverted text back
+ALTER TABLE tasks ALTER COLUMN due_date TYPE TEXT
+    USING COALESCE(to_char(due_date AT TIME ZONE 'UTC', 'YYYY-MM-DD"T"HH24:MI:SS"Z"'), legacy_due_date, '');
+ALTER TABLE tasks DROP COLUMN legacy_due_date;
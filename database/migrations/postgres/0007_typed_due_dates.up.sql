@@ -0,0 +1,24 @@
+-- Due dates become timestamptz. Date-only values are taken as midnight UTC and anything
+-- that isn't a date or an ISO 8601 timestamp, like "tomorrow", or that names a day that
+-- doesn't exist, like 2023-02-30, becomes NULL; its original text is kept in
+-- legacy_due_date so nothing is lost.
+CREATE FUNCTION pg_temp.typed_due_date(value TEXT) RETURNS TIMESTAMPTZ AS $$
+BEGIN
+    IF value ~ '^\d{4}-\d{2}-\d{2}$' THEN
+        RETURN (value || 'T00:00:00Z')::timestamptz;
+    END IF;
+    IF value ~ '^\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}(:\d{2}(\.\d+)?)?(Z|[+-]\d{2}(:?\d{2})?)$' THEN
+        RETURN value::timestamptz;
+    END IF;
+    RETURN NULL;
+EXCEPTION WHEN datetime_field_overflow OR invalid_datetime_format THEN
+    RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
+
+ALTER TABLE tasks ADD COLUMN legacy_due_date TEXT;
+UPDATE tasks SET legacy_due_date = due_date
+WHERE due_date <> '' AND pg_temp.typed_due_date(due_date) IS NULL;
+
+ALTER TABLE tasks ALTER COLUMN due_date TYPE TIMESTAMPTZ USING pg_temp.typed_due_date(due_date);
+DROP FUNCTION pg_temp.typed_due_date(TEXT);
@@ -0,0 +1,9 @@
+CREATE TABLE projects (
+    id SERIAL PRIMARY KEY,
+    name TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    default_status TEXT NOT NULL DEFAULT 'pending',
+    archived_at TIMESTAMPTZ
+);
+ALTER TABLE tasks ADD COLUMN project_id INTEGER REFERENCES projects(id) ON DELETE SET NULL;
+CREATE INDEX tasks_project_id_idx ON tasks(project_id);
@@ -0,0 +1,23 @@
+-- Tag names and emails become globally unique again. Where workspaces share one, the
+-- oldest keeps it and the others are renamed after their workspace: "urgent (acme)" and
+-- ada+acme@example.com.
+UPDATE tags t SET name = t.name || ' (' || w.slug || ')'
+FROM workspaces w
+WHERE w.id = t.workspace_id AND EXISTS (SELECT 1 FROM tags o WHERE o.name = t.name AND o.id < t.id);
+UPDATE users u SET email = regexp_replace(u.email, '@', '+' || w.slug || '@')
+FROM workspaces w
+WHERE w.id = u.workspace_id AND EXISTS (SELECT 1 FROM users o WHERE o.email = u.email AND o.id < u.id);
+
+ALTER TABLE users DROP CONSTRAINT users_workspace_id_email_key;
+ALTER TABLE users ADD CONSTRAINT users_email_key UNIQUE (email);
+ALTER TABLE users DROP COLUMN workspace_id;
+
+ALTER TABLE tags DROP CONSTRAINT tags_workspace_id_name_key;
+ALTER TABLE tags ADD CONSTRAINT tags_name_key UNIQUE (name);
+ALTER TABLE tags DROP COLUMN workspace_id;
+
+DROP INDEX IF EXISTS projects_workspace_id_idx;
+ALTER TABLE projects DROP COLUMN workspace_id;
+DROP INDEX IF EXISTS tasks_workspace_id_idx;
+ALTER TABLE tasks DROP COLUMN workspace_id;
+DROP TABLE IF EXISTS workspaces;
@@ -0,0 +1,39 @@
+-- Quotas of 0 are unlimited
+CREATE TABLE workspaces (
+    id SERIAL PRIMARY KEY,
+    name TEXT NOT NULL,
+    slug TEXT NOT NULL UNIQUE,
+    max_users INTEGER NOT NULL DEFAULT 50,
+    max_projects INTEGER NOT NULL DEFAULT 100,
+    max_tasks INTEGER NOT NULL DEFAULT 10000
+);
+
+-- Existing data moves into a default workspace without quotas. Its users may have no
+-- password, so operators give it an admin with: task-api workspace add-admin default ...
+INSERT INTO workspaces(id, name, slug, max_users, max_projects, max_tasks)
+SELECT 1, 'Default', 'default', 0, 0, 0
+WHERE EXISTS (SELECT 1 FROM users) OR EXISTS (SELECT 1 FROM tasks) OR EXISTS (SELECT 1 FROM projects) OR EXISTS (SELECT 1 FROM tags);
+SELECT setval(pg_get_serial_sequence('workspaces', 'id'), COALESCE((SELECT MAX(id) FROM workspaces), 0) + 1, false);
+
+ALTER TABLE tasks ADD COLUMN workspace_id INTEGER REFERENCES workspaces(id) ON DELETE CASCADE;
+UPDATE tasks SET workspace_id = 1;
+ALTER TABLE tasks ALTER COLUMN workspace_id SET NOT NULL;
+CREATE INDEX tasks_workspace_id_idx ON tasks(workspace_id);
+
+ALTER TABLE projects ADD COLUMN workspace_id INTEGER REFERENCES workspaces(id) ON DELETE CASCADE;
+UPDATE projects SET workspace_id = 1;
+ALTER TABLE projects ALTER COLUMN workspace_id SET NOT NULL;
+CREATE INDEX projects_workspace_id_idx ON projects(workspace_id);
+
+-- Tag names and emails become unique per workspace
+ALTER TABLE tags ADD COLUMN workspace_id INTEGER REFERENCES workspaces(id) ON DELETE CASCADE;
+UPDATE tags SET workspace_id = 1;
+ALTER TABLE tags ALTER COLUMN workspace_id SET NOT NULL;
+ALTER TABLE tags DROP CONSTRAINT tags_name_key;
+ALTER TABLE tags ADD CONSTRAINT tags_workspace_id_name_key UNIQUE (workspace_id, name);
+
+ALTER TABLE users ADD COLUMN workspace_id INTEGER REFERENCES workspaces(id) ON DELETE CASCADE;
+UPDATE users SET workspace_id = 1;
+ALTER TABLE users ALTER COLUMN workspace_id SET NOT NULL;
+ALTER TABLE users DROP CONSTRAINT users_email_key;
+ALTER TABLE users ADD CONSTRAINT users_workspace_id_email_key UNIQUE (workspace_id, email);
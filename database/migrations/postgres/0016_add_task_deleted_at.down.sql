@@ -0,0 +1,4 @@
+-- Tasks still in the trash become live again
+DROP INDEX tasks_deleted_at_idx;
+ALTER TABLE tasks DROP COLUMN trash_root_id;
+ALTER TABLE tasks DROP COLUMN deleted_at;
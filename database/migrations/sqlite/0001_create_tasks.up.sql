@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS tasks (
+    id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+    title TEXT,
+    description TEXT,
+    due_date TEXT,
+    status TEXT
+);
@@ -0,0 +1,2 @@
+ALTER TABLE tasks DROP COLUMN next_occurrence_id;
+ALTER TABLE tasks DROP COLUMN recurrence;
@@ -0,0 +1,6 @@
+-- The column stays TEXT and normalized values are valid free-form dates. Tasks still
+-- without a due date get their unconverted text back, the rest the empty string the old
+-- code wrote for tasks without a due date.
+UPDATE tasks SET due_date = legacy_due_date WHERE due_date IS NULL AND legacy_due_date IS NOT NULL;
+UPDATE tasks SET due_date = '' WHERE due_date IS NULL;
+ALTER TABLE tasks DROP COLUMN legacy_due_date;
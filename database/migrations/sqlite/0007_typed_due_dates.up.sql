@@ -0,0 +1,13 @@
+-- Due dates become UTC timestamps stored as 2006-01-02T15:04:05Z text, which sorts
+-- chronologically. Only values starting with a YYYY-MM-DD date are converted: strftime
+-- would read "2023" as a Julian day number. Everything else, like "tomorrow", and dates
+-- SQLite can't read become NULL; their original text is kept in legacy_due_date so
+-- nothing is lost.
+ALTER TABLE tasks ADD COLUMN legacy_due_date TEXT;
+UPDATE tasks SET legacy_due_date = due_date
+WHERE due_date <> ''
+    AND (due_date NOT GLOB '[0-9][0-9][0-9][0-9]-[0-9][0-9]-[0-9][0-9]*'
+        OR strftime('%Y-%m-%dT%H:%M:%SZ', due_date) IS NULL);
+UPDATE tasks SET due_date = CASE
+    WHEN legacy_due_date IS NULL THEN strftime('%Y-%m-%dT%H:%M:%SZ', due_date)
+END;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS tasks_project_id_idx;
+ALTER TABLE tasks DROP COLUMN project_id;
+DROP TABLE IF EXISTS projects;
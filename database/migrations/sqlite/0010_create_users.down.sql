@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS tasks_assignee_id_idx;
+ALTER TABLE tasks DROP COLUMN updated_by;
+ALTER TABLE tasks DROP COLUMN created_by;
+ALTER TABLE tasks DROP COLUMN assignee_id;
+DROP TABLE IF EXISTS users;
@@ -0,0 +1,3 @@
+-- Existing users get no password and can't log in until an admin sets one with
+-- PUT /users/{id} or an operator runs: task-api workspace set-password <slug> <email>
+ALTER TABLE users ADD COLUMN password_hash TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,3 @@
+ALTER TABLE users ADD COLUMN role TEXT NOT NULL DEFAULT 'member';
+-- Existing installations keep someone able to manage users
+UPDATE users SET role = 'admin' WHERE id = (SELECT MIN(id) FROM users);
@@ -0,0 +1,32 @@
+-- Tag names and emails become globally unique again. Where workspaces share one, the
+-- oldest keeps it and the others are renamed after their workspace: "urgent (acme)" and
+-- ada+acme@example.com.
+UPDATE tags SET name = name || ' (' || (SELECT slug FROM workspaces w WHERE w.id = tags.workspace_id) || ')'
+WHERE EXISTS (SELECT 1 FROM tags o WHERE o.name = tags.name AND o.id < tags.id);
+UPDATE users SET email = substr(email, 1, instr(email, '@') - 1) || '+' || (SELECT slug FROM workspaces w WHERE w.id = users.workspace_id) || substr(email, instr(email, '@'))
+WHERE EXISTS (SELECT 1 FROM users o WHERE o.email = users.email AND o.id < users.id);
+
+CREATE TABLE users_old (
+    id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL,
+    email TEXT NOT NULL UNIQUE,
+    password_hash TEXT NOT NULL DEFAULT '',
+    role TEXT NOT NULL DEFAULT 'member'
+);
+INSERT INTO users_old(id, name, email, password_hash, role) SELECT id, name, email, password_hash, role FROM users;
+DROP TABLE users;
+ALTER TABLE users_old RENAME TO users;
+
+CREATE TABLE tags_old (
+    id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL UNIQUE
+);
+INSERT INTO tags_old(id, name) SELECT id, name FROM tags;
+DROP TABLE tags;
+ALTER TABLE tags_old RENAME TO tags;
+
+DROP INDEX IF EXISTS projects_workspace_id_idx;
+ALTER TABLE projects DROP COLUMN workspace_id;
+DROP INDEX IF EXISTS tasks_workspace_id_idx;
+ALTER TABLE tasks DROP COLUMN workspace_id;
+DROP TABLE IF EXISTS workspaces;
@@ -0,0 +1,47 @@
+-- Quotas of 0 are unlimited
+CREATE TABLE workspaces (
+    id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL,
+    slug TEXT NOT NULL UNIQUE,
+    max_users INTEGER NOT NULL DEFAULT 50,
+    max_projects INTEGER NOT NULL DEFAULT 100,
+    max_tasks INTEGER NOT NULL DEFAULT 10000
+);
+
+-- Existing data moves into a default workspace without quotas. Its users may have no
+-- password, so operators give it an admin with: task-api workspace add-admin default ...
+INSERT INTO workspaces(id, name, slug, max_users, max_projects, max_tasks)
+SELECT 1, 'Default', 'default', 0, 0, 0
+WHERE EXISTS (SELECT 1 FROM users) OR EXISTS (SELECT 1 FROM tasks) OR EXISTS (SELECT 1 FROM projects) OR EXISTS (SELECT 1 FROM tags);
+
+ALTER TABLE tasks ADD COLUMN workspace_id INTEGER REFERENCES workspaces(id) ON DELETE CASCADE;
+UPDATE tasks SET workspace_id = 1;
+CREATE INDEX tasks_workspace_id_idx ON tasks(workspace_id);
+
+ALTER TABLE projects ADD COLUMN workspace_id INTEGER REFERENCES workspaces(id) ON DELETE CASCADE;
+UPDATE projects SET workspace_id = 1;
+CREATE INDEX projects_workspace_id_idx ON projects(workspace_id);
+
+-- Tag names and emails become unique per workspace, which SQLite can only do by rebuilding the tables
+CREATE TABLE tags_new (
+    id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+    workspace_id INTEGER NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    UNIQUE (workspace_id, name)
+);
+INSERT INTO tags_new(id, workspace_id, name) SELECT id, 1, name FROM tags;
+DROP TABLE tags;
+ALTER TABLE tags_new RENAME TO tags;
+
+CREATE TABLE users_new (
+    id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+    workspace_id INTEGER NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    email TEXT NOT NULL,
+    password_hash TEXT NOT NULL DEFAULT '',
+    role TEXT NOT NULL DEFAULT 'member',
+    UNIQUE (workspace_id, email)
+);
+INSERT INTO users_new(id, workspace_id, name, email, password_hash, role) SELECT id, 1, name, email, password_hash, role FROM users;
+DROP TABLE users;
+ALTER TABLE users_new RENAME TO users;
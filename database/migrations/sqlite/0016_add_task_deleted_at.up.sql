@@ -0,0 +1,4 @@
+ALTER TABLE tasks ADD COLUMN deleted_at TEXT;
+-- The task whose deletion trashed this one, so tasks trashed together are restored together
+ALTER TABLE tasks ADD COLUMN trash_root_id INTEGER;
+CREATE INDEX tasks_deleted_at_idx ON tasks(deleted_at);
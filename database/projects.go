@@ -0,0 +1,312 @@
+package database
+
+import (
+    "context"
+    "database/sql"
+    "errors"
+    "time"
+    "github.com/maazxenon/task-api/dates"
+    "github.com/maazxenon/task-api/models"
+)
+
+// ErrProjectNotFound is returned when a project doesn't exist
+var ErrProjectNotFound = errors.New("project not found")
+
+// ErrInvalidProject is returned when a task refers to a project that doesn't exist
+var ErrInvalidProject = errors.New("project does not exist")
+
+// ErrProjectArchived is returned when writing to a task of an archived project or moving one into it
+var ErrProjectArchived = errors.New("project is archived")
+
+// ProjectStore is the persistence layer used by the handlers to manage projects
+type ProjectStore interface {
+    // Projects returns the projects ordered by name, archived ones only if includeArchived is set
+    Projects(ctx context.Context, includeArchived bool) ([]models.Project, error)
+    // GetProject returns the project with the given ID or ErrProjectNotFound
+    GetProject(ctx context.Context, id int) (models.Project, error)
+    // CreateProject inserts a new project and returns it with its assigned ID
+    CreateProject(ctx context.Context, project models.Project) (models.Project, error)
+    // UpdateProject overwrites the name, description and default status of a project
+    UpdateProject(ctx context.Context, project models.Project) (models.Project, error)
+    // DeleteProject removes a project; its tasks are kept outside of any project
+    DeleteProject(ctx context.Context, id int) error
+    // ArchiveProject archives or unarchives a project together with its tasks
+    ArchiveProject(ctx context.Context, id int, archived bool) (models.Project, error)
+    // MoveTasks moves tasks into a project, or out of any project when projectID is nil
+    MoveTasks(ctx context.Context, projectID *int, taskIDs []int) ([]models.Task, error)
+}
+
+const projectColumns = "p.id, p.name, p.description, p.default_status, p.archived_at, (SELECT COUNT(*) FROM tasks t WHERE t.project_id = p.id AND t.deleted_at IS NULL)"
+
+// scanProject reads a row selected with projectColumns
+func scanProject(row scanner) (models.Project, error) {
+    var project models.Project
+    err := row.Scan(&project.ID, &project.Name, &project.Description, &project.DefaultStatus, timestamp{&project.ArchivedAt}, &project.TaskCount)
+    return project, err
+}
+
+// activeProject is the condition matching tasks outside of archived projects
+const activeProject = "(project_id IS NULL OR project_id NOT IN (SELECT id FROM projects WHERE archived_at IS NOT NULL))"
+
+// Projects returns all projects
+func (s *SQLStore) Projects(ctx context.Context, includeArchived bool) ([]models.Project, error) {
+    query := "SELECT " + projectColumns + " FROM projects p WHERE p.workspace_id = ?"
+    if !includeArchived {
+        query += " AND p.archived_at IS NULL"
+    }
+
+    rows, err := s.query(ctx, query+" ORDER BY p.name, p.id", WorkspaceID(ctx))
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    projects := []models.Project{}
+    for rows.Next() {
+        project, err := scanProject(rows)
+        if err != nil {
+            return nil, err
+        }
+        projects = append(projects, project)
+    }
+    return projects, rows.Err()
+}
+
+// GetProject returns the project with the given ID
+func (s *SQLStore) GetProject(ctx context.Context, id int) (models.Project, error) {
+    project, err := scanProject(s.queryRow(ctx, "SELECT "+projectColumns+" FROM projects p WHERE p.id = ? AND p.workspace_id = ?", id, WorkspaceID(ctx)))
+    if errors.Is(err, sql.ErrNoRows) {
+        return models.Project{}, ErrProjectNotFound
+    }
+    return project, err
+}
+
+// CreateProject inserts a new project
+func (s *SQLStore) CreateProject(ctx context.Context, project models.Project) (models.Project, error) {
+    if project.DefaultStatus == "" {
+        project.DefaultStatus = "pending"
+    }
+
+    err := s.withTx(ctx, func(tx *SQLStore) error {
+        if err := tx.checkQuota(ctx, QuotaProjects); err != nil {
+            return err
+        }
+
+        id, err := tx.insert(ctx, "INSERT INTO projects(workspace_id, name, description, default_status) VALUES(?, ?, ?, ?)",
+            WorkspaceID(ctx), project.Name, project.Description, project.DefaultStatus)
+        project.ID = id
+        return err
+    })
+    if err != nil {
+        return models.Project{}, err
+    }
+
+    project.ArchivedAt = ""
+    project.TaskCount = 0
+    return project, nil
+}
+
+// UpdateProject overwrites an existing project, leaving its archived state alone
+func (s *SQLStore) UpdateProject(ctx context.Context, project models.Project) (models.Project, error) {
+    if project.DefaultStatus == "" {
+        project.DefaultStatus = "pending"
+    }
+
+    result, err := s.exec(ctx, "UPDATE projects SET name = ?, description = ?, default_status = ? WHERE id = ? AND workspace_id = ?",
+        project.Name, project.Description, project.DefaultStatus, project.ID, WorkspaceID(ctx))
+    if err != nil {
+        return models.Project{}, err
+    }
+    if err := checkAffected(result); err != nil {
+        return models.Project{}, ErrProjectNotFound
+    }
+    return s.GetProject(ctx, project.ID)
+}
+
+// DeleteProject removes a project and detaches its tasks
+func (s *SQLStore) DeleteProject(ctx context.Context, id int) error {
+    return s.withTx(ctx, func(tx *SQLStore) error {
+        if _, err := tx.GetProject(ctx, id); err != nil {
+            return err
+        }
+
+        before, err := tx.projectTasks(ctx, id)
+        if err != nil {
+            return err
+        }
+        if _, err := tx.exec(ctx, "UPDATE tasks SET project_id = NULL, version = version + 1 WHERE project_id = ?", id); err != nil {
+            return err
+        }
+        after, err := tx.tasksByID(ctx, before)
+        if err != nil {
+            return err
+        }
+        for i := range after {
+            if err := tx.audit(ctx, AuditUpdate, after[i].ID, &before[i], &after[i]); err != nil {
+                return err
+            }
+        }
+
+        _, err = tx.exec(ctx, "DELETE FROM projects WHERE id = ?", id)
+        return err
+    })
+}
+
+// projectTasks returns the tasks of a project, trashed ones included, ordered by ID
+func (s *SQLStore) projectTasks(ctx context.Context, projectID int) ([]models.Task, error) {
+    rows, err := s.query(ctx, "SELECT "+taskColumns+" FROM tasks WHERE project_id = ? AND workspace_id = ? ORDER BY id", projectID, WorkspaceID(ctx))
+    if err != nil {
+        return nil, err
+    }
+    return s.collectTasks(ctx, rows)
+}
+
+// tasksByID reloads tasks, in the same order
+func (s *SQLStore) tasksByID(ctx context.Context, tasks []models.Task) ([]models.Task, error) {
+    if len(tasks) == 0 {
+        return nil, nil
+    }
+    args := make([]any, len(tasks))
+    for i, task := range tasks {
+        args[i] = task.ID
+    }
+    rows, err := s.query(ctx, "SELECT "+taskColumns+" FROM tasks WHERE id IN ("+placeholders(len(args))+") ORDER BY id", args...)
+    if err != nil {
+        return nil, err
+    }
+    return s.collectTasks(ctx, rows)
+}
+
+// ArchiveProject sets or clears the archived_at timestamp of a project. Its tasks follow
+// the project, so they are archived and restored along with it.
+func (s *SQLStore) ArchiveProject(ctx context.Context, id int, archived bool) (models.Project, error) {
+    var project models.Project
+    err := s.withTx(ctx, func(tx *SQLStore) error {
+        var err error
+        if project, err = tx.GetProject(ctx, id); err != nil {
+            return err
+        }
+        if archived == (project.ArchivedAt != "") {
+            return nil
+        }
+
+        var archivedAt string
+        if archived {
+            archivedAt = dates.Format(time.Now())
+        }
+        if _, err := tx.exec(ctx, "UPDATE projects SET archived_at = ? WHERE id = ?", timestampArg(archivedAt), id); err != nil {
+            return err
+        }
+        // Bump the tasks' versions so cached copies see the change in writability
+        before, err := tx.projectTasks(ctx, id)
+        if err != nil {
+            return err
+        }
+        if _, err := tx.exec(ctx, "UPDATE tasks SET version = version + 1 WHERE project_id = ?", id); err != nil {
+            return err
+        }
+        after, err := tx.tasksByID(ctx, before)
+        if err != nil {
+            return err
+        }
+        for i := range after {
+            if err := tx.audit(ctx, AuditUpdate, after[i].ID, &before[i], &after[i]); err != nil {
+                return err
+            }
+        }
+
+        project.ArchivedAt = archivedAt
+        return nil
+    })
+    if err != nil {
+        return models.Project{}, err
+    }
+    return project, nil
+}
+
+// MoveTasks moves tasks into a project, bumping their versions
+func (s *SQLStore) MoveTasks(ctx context.Context, projectID *int, taskIDs []int) ([]models.Task, error) {
+    var tasks []models.Task
+    err := s.withTx(ctx, func(tx *SQLStore) error {
+        if err := tx.checkProject(ctx, projectID); err != nil {
+            return err
+        }
+
+        before := make(map[int]models.Task, len(taskIDs))
+        for _, id := range taskIDs {
+            if err := tx.checkTaskWritable(ctx, id); err != nil {
+                return err
+            }
+            task, err := tx.Get(ctx, id)
+            if err != nil {
+                return err
+            }
+            before[id] = task
+            if _, err := tx.exec(ctx, "UPDATE tasks SET project_id = ?, version = version + 1 WHERE id = ?", projectID, id); err != nil {
+                return err
+            }
+        }
+
+        args := make([]any, len(taskIDs))
+        for i, id := range taskIDs {
+            args[i] = id
+        }
+        rows, err := tx.query(ctx, "SELECT "+taskColumns+" FROM tasks WHERE id IN ("+placeholders(len(args))+") ORDER BY id", args...)
+        if err != nil {
+            return err
+        }
+        if tasks, err = tx.collectTasks(ctx, rows); err != nil {
+            return err
+        }
+
+        for i := range tasks {
+            old := before[tasks[i].ID]
+            if err := tx.audit(ctx, AuditUpdate, tasks[i].ID, &old, &tasks[i]); err != nil {
+                return err
+            }
+        }
+        return nil
+    })
+    if err != nil {
+        return nil, err
+    }
+    return tasks, nil
+}
+
+// checkProject verifies that a task can be placed in projectID
+func (s *SQLStore) checkProject(ctx context.Context, projectID *int) error {
+    if projectID == nil {
+        return nil
+    }
+
+    var archivedAt string
+    err := s.queryRow(ctx, "SELECT archived_at FROM projects WHERE id = ? AND workspace_id = ?", *projectID, WorkspaceID(ctx)).Scan(timestamp{&archivedAt})
+    if errors.Is(err, sql.ErrNoRows) {
+        return ErrInvalidProject
+    }
+    if err != nil {
+        return err
+    }
+    if archivedAt != "" {
+        return ErrProjectArchived
+    }
+    return nil
+}
+
+// checkTaskWritable returns ErrTaskNotFound for a missing task and ErrProjectArchived for
+// a task in an archived project
+func (s *SQLStore) checkTaskWritable(ctx context.Context, id int) error {
+    var archived bool
+    err := s.queryRow(ctx, `SELECT p.archived_at IS NOT NULL FROM tasks t
+        LEFT JOIN projects p ON p.id = t.project_id WHERE t.id = ? AND t.workspace_id = ? AND t.deleted_at IS NULL`, id, WorkspaceID(ctx)).Scan(&archived)
+    if errors.Is(err, sql.ErrNoRows) {
+        return ErrTaskNotFound
+    }
+    if err != nil {
+        return err
+    }
+    if archived {
+        return ErrProjectArchived
+    }
+    return nil
+}
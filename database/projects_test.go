@@ -0,0 +1,73 @@
+package database
+
+import (
+    "testing"
+    "github.com/maazxenon/task-api/models"
+)
+
+func TestArchiveProjectAuditsTasks(t *testing.T) {
+    s, ctx := newTestStore(t)
+    project, err := s.CreateProject(ctx, models.Project{Name: "Launch"})
+    if err != nil {
+        t.Fatalf("CreateProject: %v", err)
+    }
+    task, err := s.Create(ctx, models.Task{Title: "Ship it", Status: "pending", ProjectID: &project.ID})
+    if err != nil {
+        t.Fatalf("Create: %v", err)
+    }
+
+    for _, archived := range []bool{true, false} {
+        before, err := s.Get(ctx, task.ID)
+        if err != nil {
+            t.Fatalf("Get: %v", err)
+        }
+        if _, err := s.ArchiveProject(ctx, project.ID, archived); err != nil {
+            t.Fatalf("ArchiveProject(%v): %v", archived, err)
+        }
+
+        history, err := s.TaskHistory(ctx, task.ID)
+        if err != nil {
+            t.Fatalf("TaskHistory: %v", err)
+        }
+        last := history[len(history)-1]
+        if last.Action != AuditUpdate || last.Before == nil || last.After == nil {
+            t.Fatalf("ArchiveProject(%v) last history entry = %+v, want an update", archived, last)
+        }
+        if last.Before.Version != before.Version || last.After.Version != before.Version+1 {
+            t.Errorf("ArchiveProject(%v) audited version %d -> %d, want %d -> %d", archived,
+                last.Before.Version, last.After.Version, before.Version, before.Version+1)
+        }
+    }
+}
+
+func TestDeleteProjectAuditsTasks(t *testing.T) {
+    s, ctx := newTestStore(t)
+    project, err := s.CreateProject(ctx, models.Project{Name: "Launch"})
+    if err != nil {
+        t.Fatalf("CreateProject: %v", err)
+    }
+    task, err := s.Create(ctx, models.Task{Title: "Ship it", Status: "pending", ProjectID: &project.ID})
+    if err != nil {
+        t.Fatalf("Create: %v", err)
+    }
+
+    if err := s.DeleteProject(ctx, project.ID); err != nil {
+        t.Fatalf("DeleteProject: %v", err)
+    }
+    got, err := s.Get(ctx, task.ID)
+    if err != nil {
+        t.Fatalf("Get: %v", err)
+    }
+    if got.ProjectID != nil || got.Version != task.Version+1 {
+        t.Errorf("task after DeleteProject = %+v, want no project and version %d", got, task.Version+1)
+    }
+
+    history, err := s.TaskHistory(ctx, task.ID)
+    if err != nil {
+        t.Fatalf("TaskHistory: %v", err)
+    }
+    last := history[len(history)-1]
+    if last.Action != AuditUpdate || last.Before == nil || last.Before.ProjectID == nil || last.After == nil || last.After.ProjectID != nil {
+        t.Errorf("last history entry = %+v, want the update clearing the project", last)
+    }
+}
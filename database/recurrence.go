@@ -0,0 +1,61 @@
+package database
+
+import (
+    "context"
+    "time"
+    "github.com/maazxenon/task-api/dates"
+    "github.com/maazxenon/task-api/models"
+    "github.com/maazxenon/task-api/recurrence"
+)
+
+// scheduleNextOccurrence creates the next task of a recurring series once task is
+// completed. A task only ever spawns one successor, so completing it again after
+// reopening it doesn't duplicate the series.
+func (s *SQLStore) scheduleNextOccurrence(ctx context.Context, task *models.Task) error {
+    if task.Status != "completed" || task.Recurrence == "" || task.NextOccurrenceID != nil {
+        return nil
+    }
+
+    next, ok, err := recurrence.Next(task.Recurrence, task.DueDate, dates.Location(ctx), time.Now())
+    if err != nil || !ok {
+        return err
+    }
+
+    // The next occurrence is a new task, so completing the task fails once the quota is used up
+    if err := s.checkQuota(ctx, QuotaTasks); err != nil {
+        return err
+    }
+
+    id, err := s.insert(ctx, `INSERT INTO tasks(workspace_id, title, description, due_date, status, parent_id, project_id, assignee_id, created_by, updated_by, recurrence)
+        VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`, WorkspaceID(ctx), task.Title, task.Description, timestampArg(next.DueDate), "pending", task.ParentID, task.ProjectID,
+        task.AssigneeID, task.UpdatedBy, task.UpdatedBy, next.Rule)
+    if err != nil {
+        return err
+    }
+
+    if _, err := s.exec(ctx, "INSERT INTO task_tags(task_id, tag_id) SELECT ?, tag_id FROM task_tags WHERE task_id = ?", id, task.ID); err != nil {
+        return err
+    }
+    if _, err := s.exec(ctx, "UPDATE tasks SET next_occurrence_id = ? WHERE id = ?", id, task.ID); err != nil {
+        return err
+    }
+    task.NextOccurrenceID = &id
+    return s.auditCurrent(ctx, AuditCreate, id, nil)
+}
+
+// pruneReferences clears references to deleted tasks. The foreign key actions do this on
+// PostgreSQL and on SQLite connections opened by Open, but SQLite databases written
+// before foreign keys were enforced, or opened with _foreign_keys=0, may still hold some.
+func (s *SQLStore) pruneReferences(ctx context.Context) error {
+    if _, err := s.exec(ctx, `DELETE FROM task_dependencies
+        WHERE task_id NOT IN (SELECT id FROM tasks) OR blocked_by_id NOT IN (SELECT id FROM tasks)`); err != nil {
+        return err
+    }
+    if _, err := s.exec(ctx, "DELETE FROM task_tags WHERE task_id NOT IN (SELECT id FROM tasks)"); err != nil {
+        return err
+    }
+
+    _, err := s.exec(ctx, `UPDATE tasks SET next_occurrence_id = NULL
+        WHERE next_occurrence_id IS NOT NULL AND next_occurrence_id NOT IN (SELECT id FROM tasks)`)
+    return err
+}
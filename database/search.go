@@ -0,0 +1,181 @@
+package database
+
+import (
+    "context"
+    "errors"
+    "html"
+    "strings"
+    "unicode"
+    "github.com/maazxenon/task-api/models"
+)
+
+// ErrEmptyQuery is returned by Search when the query contains nothing to search for
+var ErrEmptyQuery = errors.New("search query has no searchable terms")
+
+// Highlight markers wrapped around matched words in titles and snippets. The engines mark
+// matches with private-use characters instead of tags, so the text can be HTML-escaped
+// before the markers become <mark> tags.
+const (
+    highlightStart = "\uE000"
+    highlightEnd   = "\uE001"
+)
+
+var highlightTags = strings.NewReplacer(highlightStart, "<mark>", highlightEnd, "</mark>")
+
+// searchTerm is one required term of a search query
+type searchTerm struct {
+    words []string
+    // prefix makes the last word match any word starting with it
+    prefix bool
+}
+
+// parseSearchQuery splits a user query into terms that must all match. Bare words match
+// anywhere, "quoted phrases" match words in sequence and a trailing * turns a word into a
+// prefix. Punctuation is dropped so the result is always a valid engine query.
+func parseSearchQuery(q string) []searchTerm {
+    var terms []searchTerm
+    for q = strings.TrimSpace(q); q != ""; q = strings.TrimSpace(q) {
+        var token string
+        if q[0] == '"' {
+            end := strings.IndexByte(q[1:], '"')
+            if end < 0 {
+                token, q = q[1:], ""
+            } else {
+                token, q = q[1:end+1], q[end+2:]
+            }
+        } else {
+            end := strings.IndexAny(q, " \t\n\"")
+            if end < 0 {
+                token, q = q, ""
+            } else {
+                token, q = q[:end], q[end:]
+            }
+        }
+
+        words := strings.FieldsFunc(token, func(r rune) bool {
+            return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+        })
+        if len(words) > 0 {
+            terms = append(terms, searchTerm{words: words, prefix: strings.HasSuffix(token, "*")})
+        }
+    }
+    return terms
+}
+
+// ftsQuery renders terms as an FTS5 MATCH expression
+func ftsQuery(terms []searchTerm) string {
+    parts := make([]string, len(terms))
+    for i, term := range terms {
+        parts[i] = `"` + strings.Join(term.words, " ") + `"`
+        if term.prefix {
+            parts[i] += "*"
+        }
+    }
+    return strings.Join(parts, " AND ")
+}
+
+// tsQuery renders terms as a PostgreSQL to_tsquery expression
+func tsQuery(terms []searchTerm) string {
+    parts := make([]string, len(terms))
+    for i, term := range terms {
+        parts[i] = strings.Join(term.words, " <-> ")
+        if term.prefix {
+            parts[i] += ":*"
+        }
+        if len(term.words) > 1 {
+            parts[i] = "(" + parts[i] + ")"
+        }
+    }
+    return strings.Join(parts, " & ")
+}
+
+// Search returns tasks whose title or description match query, best matches first
+func (s *SQLStore) Search(ctx context.Context, query string, limit, offset int) ([]models.TaskMatch, error) {
+    terms := parseSearchQuery(query)
+    if len(terms) == 0 {
+        return nil, ErrEmptyQuery
+    }
+
+    var matches []models.TaskMatch
+    var err error
+    if s.dialect == Postgres {
+        matches, err = s.searchPostgres(ctx, terms, limit, offset)
+    } else {
+        matches, err = s.searchSQLite(ctx, terms, limit, offset)
+    }
+    if err != nil {
+        return nil, err
+    }
+
+    tasks := make([]*models.Task, len(matches))
+    for i := range matches {
+        tasks[i] = &matches[i].Task
+    }
+    return matches, s.loadTags(ctx, tasks...)
+}
+
+// searchPostgres ranks and pages the matches in the database
+func (s *SQLStore) searchPostgres(ctx context.Context, terms []searchTerm, limit, offset int) ([]models.TaskMatch, error) {
+    rows, err := s.query(ctx, `SELECT `+qualify("t", taskColumns)+`, ts_rank(t.search, q) AS rank,
+            ts_headline('english', coalesce(t.title, ''), q, 'HighlightAll=true, StartSel=`+highlightStart+`, StopSel=`+highlightEnd+`'),
+            ts_headline('english', coalesce(t.description, ''), q, 'MaxWords=16, MinWords=6, StartSel=`+highlightStart+`, StopSel=`+highlightEnd+`')
+        FROM tasks t, to_tsquery('english', ?) q
+        WHERE t.search @@ q AND t.workspace_id = ? AND t.deleted_at IS NULL AND `+activeProject+`
+        ORDER BY rank DESC, t.id
+        LIMIT ? OFFSET ?`, tsQuery(terms), WorkspaceID(ctx), limit, offset)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    matches := []models.TaskMatch{}
+    for rows.Next() {
+        var m models.TaskMatch
+        var title, snippet *string
+        dest := append(taskFields(&m.Task), &m.Rank, &title, &snippet)
+        if err := rows.Scan(dest...); err != nil {
+            return nil, err
+        }
+        m.TitleHighlight, m.Snippet = highlight(title), highlight(snippet)
+        matches = append(matches, m)
+    }
+    return matches, rows.Err()
+}
+
+// searchSQLite ranks and pages the matches with FTS5. bm25 is lower for better matches;
+// it is negated so rank reads the same on both backends. Title hits weigh ten times as
+// much as description hits.
+func (s *SQLStore) searchSQLite(ctx context.Context, terms []searchTerm, limit, offset int) ([]models.TaskMatch, error) {
+    rows, err := s.query(ctx, `SELECT `+qualify("t", taskColumns)+`, -bm25(tasks_fts, 10.0, 1.0) AS rank,
+            highlight(tasks_fts, 0, '`+highlightStart+`', '`+highlightEnd+`'),
+            snippet(tasks_fts, 1, '`+highlightStart+`', '`+highlightEnd+`', '…', 16)
+        FROM tasks_fts JOIN tasks t ON t.id = tasks_fts.rowid
+        WHERE tasks_fts MATCH ? AND t.workspace_id = ? AND t.deleted_at IS NULL AND `+activeProject+`
+        ORDER BY bm25(tasks_fts, 10.0, 1.0), t.id
+        LIMIT ? OFFSET ?`, ftsQuery(terms), WorkspaceID(ctx), limit, offset)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    matches := []models.TaskMatch{}
+    for rows.Next() {
+        var m models.TaskMatch
+        var title, snippet *string
+        dest := append(taskFields(&m.Task), &m.Rank, &title, &snippet)
+        if err := rows.Scan(dest...); err != nil {
+            return nil, err
+        }
+        m.TitleHighlight, m.Snippet = highlight(title), highlight(snippet)
+        matches = append(matches, m)
+    }
+    return matches, rows.Err()
+}
+
+// highlight HTML-escapes text marked by the search engine and turns its markers into tags
+func highlight(text *string) string {
+    if text == nil {
+        return ""
+    }
+    return highlightTags.Replace(html.EscapeString(*text))
+}
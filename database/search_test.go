@@ -0,0 +1,58 @@
+package database
+
+import (
+    "errors"
+    "testing"
+    "github.com/maazxenon/task-api/models"
+)
+
+func TestSearch(t *testing.T) {
+    s, ctx := newTestStore(t)
+    inDescription, err := s.Create(ctx, models.Task{Title: "Quarterly numbers", Description: "Send the report to finance", Status: "pending"})
+    if err != nil {
+        t.Fatalf("Create: %v", err)
+    }
+    inTitle, err := s.Create(ctx, models.Task{Title: "<img src=x onerror=alert(1)> report", Description: "a <b>draft</b> & notes", Status: "pending"})
+    if err != nil {
+        t.Fatalf("Create: %v", err)
+    }
+    trashed := createTask(t, s, ctx, "Old report", nil)
+    if err := s.Delete(ctx, trashed.ID, DeleteOptions{}); err != nil {
+        t.Fatalf("Delete: %v", err)
+    }
+    globex := createWorkspace(t, s, "globex")
+    createTask(t, s, globex, "Globex report", nil)
+
+    matches, err := s.Search(ctx, "repo*", 10, 0)
+    if err != nil {
+        t.Fatalf("Search: %v", err)
+    }
+    if len(matches) != 2 || matches[0].Task.ID != inTitle.ID || matches[1].Task.ID != inDescription.ID {
+        t.Fatalf("Search = %+v, want the title match before the description match", matches)
+    }
+    if matches[0].Rank <= matches[1].Rank {
+        t.Errorf("ranks %v, %v are not best first", matches[0].Rank, matches[1].Rank)
+    }
+    // Stored text is escaped before matches are marked
+    if want := "&lt;img src=x onerror=alert(1)&gt; <mark>report</mark>"; matches[0].TitleHighlight != want {
+        t.Errorf("title highlight = %q, want %q", matches[0].TitleHighlight, want)
+    }
+    if want := "a &lt;b&gt;draft&lt;/b&gt; &amp; notes"; matches[0].Snippet != want {
+        t.Errorf("snippet = %q, want %q", matches[0].Snippet, want)
+    }
+    if want := "Send the <mark>report</mark> to finance"; matches[1].Snippet != want {
+        t.Errorf("snippet = %q, want %q", matches[1].Snippet, want)
+    }
+
+    page, err := s.Search(ctx, "report", 1, 1)
+    if err != nil {
+        t.Fatalf("Search: %v", err)
+    }
+    if len(page) != 1 || page[0].Task.ID != inDescription.ID {
+        t.Errorf("second page = %+v, want only task %d", page, inDescription.ID)
+    }
+
+    if _, err := s.Search(ctx, `"" *`, 10, 0); !errors.Is(err, ErrEmptyQuery) {
+        t.Errorf("Search without terms error = %v, want ErrEmptyQuery", err)
+    }
+}
@@ -0,0 +1,440 @@
+package database
+
+import (
+    "context"
+    "database/sql"
+    "errors"
+    "fmt"
+    "strconv"
+    "strings"
+    "time"
+    "github.com/maazxenon/task-api/dates"
+    "github.com/maazxenon/task-api/models"
+)
+
+// SQLStore is a TaskStore backed by a SQLite or PostgreSQL database
+type SQLStore struct {
+    conn    *sql.DB
+    // db is conn, or the transaction the store was handed to inside withTx
+    db      dbtx
+    dialect Dialect
+}
+
+// dbtx is the part of *sql.DB and *sql.Tx the store runs statements on
+type dbtx interface {
+    ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+    QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+    QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// NewSQLStore returns a TaskStore that uses the given connection and speaks dialect
+func NewSQLStore(db *sql.DB, dialect Dialect) *SQLStore {
+    return &SQLStore{conn: db, db: db, dialect: dialect}
+}
+
+// Ping checks that the database connection is alive
+func (s *SQLStore) Ping(ctx context.Context) error {
+    return s.conn.PingContext(ctx)
+}
+
+// withTx runs fn with a store bound to a transaction, committing if fn succeeds.
+// Calls nested inside another withTx reuse the outer transaction.
+func (s *SQLStore) withTx(ctx context.Context, fn func(tx *SQLStore) error) error {
+    if _, ok := s.db.(*sql.Tx); ok {
+        return fn(s)
+    }
+
+    tx, err := s.conn.BeginTx(ctx, nil)
+    if err != nil {
+        return err
+    }
+
+    if err := fn(&SQLStore{conn: s.conn, db: tx, dialect: s.dialect}); err != nil {
+        tx.Rollback()
+        return err
+    }
+    return tx.Commit()
+}
+
+// exec, query and queryRow run a statement written with ? placeholders in the store's dialect
+func (s *SQLStore) exec(ctx context.Context, query string, args ...any) (sql.Result, error) {
+    return s.db.ExecContext(ctx, s.dialect.Rebind(query), args...)
+}
+
+func (s *SQLStore) query(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+    return s.db.QueryContext(ctx, s.dialect.Rebind(query), args...)
+}
+
+func (s *SQLStore) queryRow(ctx context.Context, query string, args ...any) *sql.Row {
+    return s.db.QueryRowContext(ctx, s.dialect.Rebind(query), args...)
+}
+
+// taskColumns is the column list scanned by scanTask
+const taskColumns = "id, title, description, due_date, status, version, parent_id, project_id, assignee_id, created_by, updated_by, recurrence, next_occurrence_id, deleted_at"
+
+// liveTask is the condition matching tasks that aren't in the trash
+const liveTask = "deleted_at IS NULL"
+
+// scanner is implemented by *sql.Row and *sql.Rows
+type scanner interface {
+    Scan(dest ...any) error
+}
+
+// taskFields returns scan destinations for taskColumns
+func taskFields(task *models.Task) []any {
+    return []any{&task.ID, &task.Title, &task.Description, timestamp{&task.DueDate}, &task.Status, &task.Version, &task.ParentID, &task.ProjectID, &task.AssigneeID, &task.CreatedBy, &task.UpdatedBy, &task.Recurrence, &task.NextOccurrenceID, timestamp{&task.DeletedAt}}
+}
+
+// scanTask reads a row selected with taskColumns
+func scanTask(row scanner) (models.Task, error) {
+    var task models.Task
+    err := row.Scan(taskFields(&task)...)
+    return task, err
+}
+
+// qualify prefixes every column of a comma separated list with a table alias
+func qualify(alias, columns string) string {
+    parts := strings.Split(columns, ", ")
+    for i, column := range parts {
+        parts[i] = alias + "." + column
+    }
+    return strings.Join(parts, ", ")
+}
+
+// List returns one page of the tasks matching filter, ordered by the requested
+// field with id as a tie-breaker so the keyset cursor is stable
+func (s *SQLStore) List(ctx context.Context, filter TaskFilter) (TaskPage, error) {
+    where := []string{"workspace_id = ?", liveTask}
+    args := []any{WorkspaceID(ctx)}
+
+    if filter.Status != "" {
+        where = append(where, "status = ?")
+        args = append(args, filter.Status)
+    }
+    if filter.DueAfter != "" {
+        where = append(where, "due_date >= ?")
+        args = append(args, filter.DueAfter)
+    }
+    if filter.DueBefore != "" {
+        where = append(where, "due_date <= ?")
+        args = append(args, filter.DueBefore)
+    }
+    if filter.Title != "" {
+        where = append(where, `LOWER(title) LIKE ? ESCAPE '\'`)
+        args = append(args, "%"+escapeLike(strings.ToLower(filter.Title))+"%")
+    }
+    if filter.ProjectID != nil {
+        where = append(where, "project_id = ?")
+        args = append(args, *filter.ProjectID)
+    }
+    if filter.AssigneeID != nil {
+        where = append(where, "assignee_id = ?")
+        args = append(args, *filter.AssigneeID)
+    }
+    if filter.Unassigned {
+        where = append(where, "assignee_id IS NULL")
+    }
+    if !filter.IncludeArchived {
+        where = append(where, activeProject)
+    }
+    tagWhere, tagArgs := tagFilter(filter.TagsAny, filter.TagsAll)
+    where = append(where, tagWhere...)
+    args = append(args, tagArgs...)
+
+    column, ok := SortFields[filter.Sort]
+    if !ok {
+        column = "id"
+    }
+    op, dir := ">", "ASC"
+    if filter.Desc {
+        op, dir = "<", "DESC"
+    }
+
+    if filter.After != nil {
+        if column == "id" {
+            where = append(where, "id "+op+" ?")
+            args = append(args, filter.After.ID)
+        } else {
+            value := s.dialect.sortArg(column, filter.After.Value)
+            where = append(where, fmt.Sprintf("(%[1]s %[2]s ? OR (%[1]s = ? AND id %[2]s ?))", s.dialect.sortExpr(column), op))
+            args = append(args, value, value, filter.After.ID)
+        }
+    }
+
+    limit := filter.Limit
+    if limit <= 0 {
+        limit = DefaultPageSize
+    }
+
+    query := "SELECT " + taskColumns + " FROM tasks WHERE " + strings.Join(where, " AND ")
+    query += " ORDER BY " + s.dialect.sortExpr(column) + " " + dir
+    if column != "id" {
+        query += ", id " + dir
+    }
+    query += " LIMIT ?"
+    // fetch one extra row to learn whether another page follows
+    args = append(args, limit+1)
+
+    rows, err := s.query(ctx, query, args...)
+    if err != nil {
+        return TaskPage{}, err
+    }
+
+    tasks, err := s.collectTasks(ctx, rows)
+    if err != nil {
+        return TaskPage{}, err
+    }
+    page := TaskPage{Tasks: tasks}
+
+    if len(page.Tasks) > limit {
+        page.Tasks = page.Tasks[:limit]
+        last := page.Tasks[limit-1]
+        page.Next = &Cursor{Value: sortValue(last, column), ID: last.ID}
+    }
+
+    return page, nil
+}
+
+// sortValue returns the value of column for task as used in a Cursor
+func sortValue(task models.Task, column string) string {
+    switch column {
+    case "title":
+        return task.Title
+    case "due_date":
+        return task.DueDate
+    case "status":
+        return task.Status
+    }
+    return strconv.Itoa(task.ID)
+}
+
+// Get returns the task with the given ID
+func (s *SQLStore) Get(ctx context.Context, id int) (models.Task, error) {
+    return s.getTask(ctx, id, liveTask)
+}
+
+// getTask returns the task with the given ID if it matches state, liveTask or trashedTask
+func (s *SQLStore) getTask(ctx context.Context, id int, state string) (models.Task, error) {
+    task, err := scanTask(s.queryRow(ctx, "SELECT "+taskColumns+" FROM tasks WHERE id = ? AND workspace_id = ? AND "+state, id, WorkspaceID(ctx)))
+    if errors.Is(err, sql.ErrNoRows) {
+        return models.Task{}, ErrTaskNotFound
+    }
+    if err != nil {
+        return models.Task{}, err
+    }
+    return task, s.loadTags(ctx, &task)
+}
+
+// Create inserts a new task
+func (s *SQLStore) Create(ctx context.Context, task models.Task) (models.Task, error) {
+    err := s.withTx(ctx, func(tx *SQLStore) error {
+        if err := tx.checkQuota(ctx, QuotaTasks); err != nil {
+            return err
+        }
+        if err := tx.checkParent(ctx, 0, task.ParentID); err != nil {
+            return err
+        }
+        if err := tx.checkProject(ctx, task.ProjectID); err != nil {
+            return err
+        }
+        if err := tx.checkAssignee(ctx, task.AssigneeID); err != nil {
+            return err
+        }
+
+        id, err := tx.insert(ctx, `INSERT INTO tasks(workspace_id, title, description, due_date, status, parent_id, project_id, assignee_id, created_by, updated_by, recurrence)
+            VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`, WorkspaceID(ctx), task.Title, task.Description, timestampArg(task.DueDate), task.Status, task.ParentID, task.ProjectID, task.AssigneeID, task.CreatedBy, task.CreatedBy, task.Recurrence)
+        task.ID = id
+        if err != nil {
+            return err
+        }
+
+        if err := tx.setTags(ctx, task.ID, task.Tags); err != nil {
+            return err
+        }
+        if err := tx.loadTags(ctx, &task); err != nil {
+            return err
+        }
+        return tx.auditCurrent(ctx, AuditCreate, task.ID, nil)
+    })
+    if err != nil {
+        return models.Task{}, err
+    }
+
+    task.Version = 1
+    task.UpdatedBy = task.CreatedBy
+    task.NextOccurrenceID = nil
+    return task, nil
+}
+
+// Update overwrites an existing task and bumps its version
+func (s *SQLStore) Update(ctx context.Context, task models.Task, version int) (models.Task, error) {
+    err := s.withTx(ctx, func(tx *SQLStore) error {
+        if err := tx.checkTaskWritable(ctx, task.ID); err != nil {
+            return err
+        }
+        before, err := tx.Get(ctx, task.ID)
+        if err != nil {
+            return err
+        }
+        if err := tx.checkParent(ctx, task.ID, task.ParentID); err != nil {
+            return err
+        }
+        if err := tx.checkProject(ctx, task.ProjectID); err != nil {
+            return err
+        }
+        if err := tx.checkAssignee(ctx, task.AssigneeID); err != nil {
+            return err
+        }
+
+        query := `UPDATE tasks SET title = ?, description = ?, due_date = ?, status = ?, parent_id = ?, project_id = ?, assignee_id = ?,
+            updated_by = ?, recurrence = ?, version = version + 1 WHERE id = ? AND workspace_id = ?`
+        args := []any{task.Title, task.Description, timestampArg(task.DueDate), task.Status, task.ParentID, task.ProjectID, task.AssigneeID, task.UpdatedBy, task.Recurrence, task.ID, WorkspaceID(ctx)}
+        if version != 0 {
+            query += " AND version = ?"
+            args = append(args, version)
+        }
+
+        err = tx.queryRow(ctx, query+" RETURNING version, created_by, next_occurrence_id", args...).Scan(&task.Version, &task.CreatedBy, &task.NextOccurrenceID)
+        if errors.Is(err, sql.ErrNoRows) {
+            return tx.missingOrConflict(ctx, task.ID)
+        }
+        if err != nil {
+            return err
+        }
+
+        if task.Tags != nil {
+            if err := tx.setTags(ctx, task.ID, task.Tags); err != nil {
+                return err
+            }
+        }
+        if err := tx.loadTags(ctx, &task); err != nil {
+            return err
+        }
+
+        if err := tx.scheduleNextOccurrence(ctx, &task); err != nil {
+            return err
+        }
+        return tx.auditCurrent(ctx, AuditUpdate, task.ID, &before)
+    })
+    if err != nil {
+        return models.Task{}, err
+    }
+
+    return task, nil
+}
+
+// Delete moves a task to the trash along with, or detaching, its subtasks
+func (s *SQLStore) Delete(ctx context.Context, id int, opts DeleteOptions) error {
+    return s.withTx(ctx, func(tx *SQLStore) error {
+        // The subtask statements below trust id to belong to the workspace. The tasks
+        // trashed or detached are kept for the audit log.
+        task, err := tx.Get(ctx, id)
+        if err != nil {
+            return err
+        }
+        trashed, orphans := []models.Task{task}, []models.Task{}
+        if opts.Cascade {
+            trashed, err = tx.Subtree(ctx, id)
+        } else {
+            orphans, err = tx.Children(ctx, id)
+        }
+        if err != nil {
+            return err
+        }
+
+        // Subtasks trashed together record the task they were trashed with, so they are
+        // restored together too
+        deletedAt := dates.Format(time.Now())
+        query := "UPDATE tasks SET deleted_at = ?, trash_root_id = ?, version = version + 1 WHERE id = ? AND workspace_id = ? AND " + liveTask
+        args := []any{deletedAt, id, id, WorkspaceID(ctx)}
+        if opts.Version != 0 {
+            query += " AND version = ?"
+            args = append(args, opts.Version)
+        }
+
+        result, err := tx.exec(ctx, query, args...)
+        if err != nil {
+            return err
+        }
+
+        err = checkAffected(result)
+        if errors.Is(err, ErrTaskNotFound) {
+            return tx.missingOrConflict(ctx, id)
+        }
+        if err != nil {
+            return err
+        }
+
+        if len(trashed) > 1 {
+            ids := make([]any, 0, len(trashed)-1)
+            for _, t := range trashed[1:] {
+                ids = append(ids, t.ID)
+            }
+            if _, err := tx.exec(ctx, "UPDATE tasks SET deleted_at = ?, trash_root_id = ?, version = version + 1 WHERE id IN ("+placeholders(len(ids))+")",
+                append([]any{deletedAt, id}, ids...)...); err != nil {
+                return err
+            }
+        }
+        if len(orphans) > 0 {
+            if _, err := tx.exec(ctx, "UPDATE tasks SET parent_id = NULL, version = version + 1 WHERE parent_id = ? AND "+liveTask, id); err != nil {
+                return err
+            }
+        }
+
+        for i := range trashed {
+            after, err := tx.TrashedTask(ctx, trashed[i].ID)
+            if err != nil {
+                return err
+            }
+            if err := tx.audit(ctx, AuditDelete, trashed[i].ID, &trashed[i], &after); err != nil {
+                return err
+            }
+        }
+        for i := range orphans {
+            if err := tx.auditCurrent(ctx, AuditUpdate, orphans[i].ID, &orphans[i]); err != nil {
+                return err
+            }
+        }
+        return nil
+    })
+}
+
+// missingOrConflict explains why a conditional write matched no row
+func (s *SQLStore) missingOrConflict(ctx context.Context, id int) error {
+    if err := s.exists(ctx, id); err != nil {
+        return err
+    }
+    return ErrVersionConflict
+}
+
+// insert runs an INSERT and returns the generated id. PostgreSQL has no
+// LastInsertId, so the id is read back with RETURNING instead.
+func (s *SQLStore) insert(ctx context.Context, query string, args ...any) (int, error) {
+    if s.dialect == Postgres {
+        var id int
+        err := s.queryRow(ctx, query+" RETURNING id", args...).Scan(&id)
+        return id, err
+    }
+
+    result, err := s.exec(ctx, query, args...)
+    if err != nil {
+        return 0, err
+    }
+
+    id, err := result.LastInsertId()
+    return int(id), err
+}
+
+// checkAffected returns ErrTaskNotFound when a statement didn't touch any row
+func checkAffected(result sql.Result) error {
+    rowsAffected, err := result.RowsAffected()
+    if err != nil {
+        return err
+    }
+
+    if rowsAffected == 0 {
+        return ErrTaskNotFound
+    }
+
+    return nil
+}
@@ -0,0 +1,82 @@
+package database
+
+import (
+    "context"
+    "errors"
+    "github.com/maazxenon/task-api/models"
+)
+
+// ErrTaskNotFound is an error returned when a task is not found
+var ErrTaskNotFound = errors.New("task not found")
+
+// ErrParentNotFound is returned when a task refers to a parent that doesn't exist
+var ErrParentNotFound = errors.New("parent task not found")
+
+// ErrHierarchyCycle is returned when re-parenting would make a task its own ancestor
+var ErrHierarchyCycle = errors.New("task cannot be moved under itself or one of its subtasks")
+
+// ErrBlockerNotFound is returned when a dependency refers to a blocking task that doesn't exist
+var ErrBlockerNotFound = errors.New("blocking task not found")
+
+// ErrDependencyCycle is returned when a dependency would make a task wait on itself
+var ErrDependencyCycle = errors.New("dependency would create a cycle")
+
+// ErrDependencyNotFound is returned when removing a dependency that doesn't exist
+var ErrDependencyNotFound = errors.New("dependency not found")
+
+// ErrVersionConflict is returned when a conditional write finds the task at a different version
+var ErrVersionConflict = errors.New("task version conflict")
+
+// TaskStore is the persistence layer used by the handlers to manage tasks
+type TaskStore interface {
+    // List returns one page of the tasks matching filter
+    List(ctx context.Context, filter TaskFilter) (TaskPage, error)
+    // Get returns the task with the given ID or ErrTaskNotFound
+    Get(ctx context.Context, id int) (models.Task, error)
+    // Create inserts a new task and returns it with its assigned ID
+    Create(ctx context.Context, task models.Task) (models.Task, error)
+    // Update overwrites an existing task and returns ErrTaskNotFound if it doesn't exist.
+    // A non-zero version makes the write conditional: ErrVersionConflict is returned if the
+    // stored task is at a different version.
+    Update(ctx context.Context, task models.Task, version int) (models.Task, error)
+    // Delete moves the task with the given ID to the trash or returns ErrTaskNotFound
+    Delete(ctx context.Context, id int, opts DeleteOptions) error
+    // Search runs a full-text query over titles and descriptions, best matches first
+    Search(ctx context.Context, query string, limit, offset int) ([]models.TaskMatch, error)
+    // Children returns the direct subtasks of a task
+    Children(ctx context.Context, id int) ([]models.Task, error)
+    // Subtree returns a task followed by all of its descendants
+    Subtree(ctx context.Context, id int) ([]models.Task, error)
+    // Blockers returns the tasks the given task is blocked by
+    Blockers(ctx context.Context, id int) ([]models.Task, error)
+    // AddDependency marks taskID as blocked by blockerID, refusing edges that form a cycle
+    AddDependency(ctx context.Context, taskID, blockerID int) error
+    // RemoveDependency removes a dependency or returns ErrDependencyNotFound
+    RemoveDependency(ctx context.Context, taskID, blockerID int) error
+    // DependencyGraph returns the tasks and dependency edges used for planning
+    DependencyGraph(ctx context.Context, includeCompleted bool) ([]models.Task, []models.Dependency, error)
+}
+
+// Store combines the stores the handlers depend on
+type Store interface {
+    TaskStore
+    TagStore
+    ProjectStore
+    UserStore
+    APIKeyStore
+    WorkspaceStore
+    AuditStore
+    TrashStore
+    BatchStore
+    IdempotencyStore
+    // Ping checks that the database is reachable
+    Ping(ctx context.Context) error
+}
+
+// DeleteOptions controls how Delete treats the task and its subtasks
+type DeleteOptions struct {
+    // Version makes the delete conditional like Update when non-zero
+    Version int
+    // Cascade deletes all descendants too; otherwise direct children become top-level tasks
+    Cascade bool
+}
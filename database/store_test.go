@@ -0,0 +1,78 @@
+package database
+
+import (
+    "context"
+    "database/sql"
+    "path/filepath"
+    "strings"
+    "testing"
+    "github.com/maazxenon/task-api/models"
+)
+
+// newTestStore returns a store backed by a migrated SQLite database in a temporary
+// directory and a context for a new workspace in it
+func newTestStore(t *testing.T) (*SQLStore, context.Context) {
+    t.Helper()
+    db := openTestDB(t)
+    migrateTestDB(t, db, 0)
+    s := NewSQLStore(db, SQLite)
+    return s, createWorkspace(t, s, "acme")
+}
+
+// openTestDB opens an empty SQLite database in a temporary directory
+func openTestDB(t *testing.T) *sql.DB {
+    t.Helper()
+    db, _, err := Open(filepath.Join(t.TempDir(), "test.db"))
+    if err != nil {
+        t.Fatalf("Open: %v", err)
+    }
+    t.Cleanup(func() { db.Close() })
+    return db
+}
+
+// migrateTestDB migrates db up to target, or all the way for 0. Without FTS5 the test is
+// skipped since the search migration can't be applied.
+func migrateTestDB(t *testing.T, db *sql.DB, target int) *Migrator {
+    t.Helper()
+    migrator, err := NewMigrator(db, SQLite)
+    if err != nil {
+        t.Fatalf("NewMigrator: %v", err)
+    }
+    if _, err := migrator.Up(context.Background(), target); err != nil {
+        if strings.Contains(err.Error(), "fts5") {
+            t.Skipf("SQLite was built without full-text search, run the tests with -tags sqlite_fts5: %v", err)
+        }
+        t.Fatalf("Up: %v", err)
+    }
+    return migrator
+}
+
+// createWorkspace creates a workspace and its admin and returns a context acting as them
+func createWorkspace(t *testing.T, s *SQLStore, slug string) context.Context {
+    t.Helper()
+    workspace, admin, err := s.CreateWorkspace(context.Background(), models.Workspace{Name: slug, Slug: slug},
+        models.User{Name: "Admin", Email: "admin@" + slug + ".example"})
+    if err != nil {
+        t.Fatalf("CreateWorkspace(%q): %v", slug, err)
+    }
+    return WithActor(WithWorkspace(context.Background(), workspace.ID), admin.ID)
+}
+
+// createTask stores a task with the given title and parent, failing the test on error
+func createTask(t *testing.T, s *SQLStore, ctx context.Context, title string, parentID *int) models.Task {
+    t.Helper()
+    task, err := s.Create(ctx, models.Task{Title: title, Status: "pending", ParentID: parentID})
+    if err != nil {
+        t.Fatalf("Create(%q): %v", title, err)
+    }
+    return task
+}
+
+// taskIDs returns the IDs of tasks in order
+func taskIDs(tasks []models.Task) []int {
+    ids := make([]int, len(tasks))
+    for i, task := range tasks {
+        ids[i] = task.ID
+    }
+    return ids
+}
@@ -0,0 +1,245 @@
+package database
+
+import (
+    "context"
+    "database/sql"
+    "errors"
+    "strings"
+    "github.com/maazxenon/task-api/models"
+)
+
+// ErrTagNotFound is returned when a tag doesn't exist
+var ErrTagNotFound = errors.New("tag not found")
+
+// ErrTagExists is returned when creating or renaming a tag to a name already in use
+var ErrTagExists = errors.New("a tag with this name already exists")
+
+// TagStore is the persistence layer used by the handlers to manage tags
+type TagStore interface {
+    // Tags returns all tags ordered by name, with the number of tasks carrying each
+    Tags(ctx context.Context) ([]models.Tag, error)
+    // GetTag returns the tag with the given ID or ErrTagNotFound
+    GetTag(ctx context.Context, id int) (models.Tag, error)
+    // CreateTag inserts a new tag or returns ErrTagExists
+    CreateTag(ctx context.Context, tag models.Tag) (models.Tag, error)
+    // UpdateTag renames a tag; every task carrying it follows the new name
+    UpdateTag(ctx context.Context, tag models.Tag) (models.Tag, error)
+    // DeleteTag removes a tag from all tasks and deletes it
+    DeleteTag(ctx context.Context, id int) error
+}
+
+// NormalizeTag returns the stored form of a tag name. Tag names are case-insensitive.
+func NormalizeTag(name string) string {
+    return strings.ToLower(strings.TrimSpace(name))
+}
+
+// normalizeTags normalizes names and drops duplicates, keeping the first occurrence
+func normalizeTags(names []string) []string {
+    seen := make(map[string]bool, len(names))
+    tags := make([]string, 0, len(names))
+    for _, name := range names {
+        name = NormalizeTag(name)
+        if name == "" || seen[name] {
+            continue
+        }
+        seen[name] = true
+        tags = append(tags, name)
+    }
+    return tags
+}
+
+// placeholders returns n comma separated ? placeholders
+func placeholders(n int) string {
+    return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
+
+// stringArgs converts values into query arguments
+func stringArgs(values []string) []any {
+    args := make([]any, len(values))
+    for i, v := range values {
+        args[i] = v
+    }
+    return args
+}
+
+const tagColumns = "g.id, g.name, (SELECT COUNT(*) FROM task_tags tt JOIN tasks t ON t.id = tt.task_id WHERE tt.tag_id = g.id AND t.deleted_at IS NULL)"
+
+// Tags returns all tags with their task counts
+func (s *SQLStore) Tags(ctx context.Context) ([]models.Tag, error) {
+    rows, err := s.query(ctx, "SELECT "+tagColumns+" FROM tags g WHERE g.workspace_id = ? ORDER BY g.name", WorkspaceID(ctx))
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    tags := []models.Tag{}
+    for rows.Next() {
+        var tag models.Tag
+        if err := rows.Scan(&tag.ID, &tag.Name, &tag.TaskCount); err != nil {
+            return nil, err
+        }
+        tags = append(tags, tag)
+    }
+    return tags, rows.Err()
+}
+
+// GetTag returns the tag with the given ID
+func (s *SQLStore) GetTag(ctx context.Context, id int) (models.Tag, error) {
+    var tag models.Tag
+    err := s.queryRow(ctx, "SELECT "+tagColumns+" FROM tags g WHERE g.id = ? AND g.workspace_id = ?", id, WorkspaceID(ctx)).Scan(&tag.ID, &tag.Name, &tag.TaskCount)
+    if errors.Is(err, sql.ErrNoRows) {
+        return models.Tag{}, ErrTagNotFound
+    }
+    return tag, err
+}
+
+// CreateTag inserts a new tag
+func (s *SQLStore) CreateTag(ctx context.Context, tag models.Tag) (models.Tag, error) {
+    tag.Name = NormalizeTag(tag.Name)
+    err := s.withTx(ctx, func(tx *SQLStore) error {
+        if err := tx.checkTagName(ctx, 0, tag.Name); err != nil {
+            return err
+        }
+
+        id, err := tx.insert(ctx, "INSERT INTO tags(workspace_id, name) VALUES(?, ?)", WorkspaceID(ctx), tag.Name)
+        tag.ID = id
+        return err
+    })
+    if err != nil {
+        return models.Tag{}, err
+    }
+
+    tag.TaskCount = 0
+    return tag, nil
+}
+
+// UpdateTag renames an existing tag
+func (s *SQLStore) UpdateTag(ctx context.Context, tag models.Tag) (models.Tag, error) {
+    tag.Name = NormalizeTag(tag.Name)
+    err := s.withTx(ctx, func(tx *SQLStore) error {
+        if err := tx.checkTagName(ctx, tag.ID, tag.Name); err != nil {
+            return err
+        }
+
+        result, err := tx.exec(ctx, "UPDATE tags SET name = ? WHERE id = ? AND workspace_id = ?", tag.Name, tag.ID, WorkspaceID(ctx))
+        if err != nil {
+            return err
+        }
+        if err := checkAffected(result); err != nil {
+            return ErrTagNotFound
+        }
+
+        tag, err = tx.GetTag(ctx, tag.ID)
+        return err
+    })
+    if err != nil {
+        return models.Tag{}, err
+    }
+    return tag, nil
+}
+
+// DeleteTag removes a tag and detaches it from its tasks
+func (s *SQLStore) DeleteTag(ctx context.Context, id int) error {
+    return s.withTx(ctx, func(tx *SQLStore) error {
+        if _, err := tx.GetTag(ctx, id); err != nil {
+            return err
+        }
+        if _, err := tx.exec(ctx, "DELETE FROM task_tags WHERE tag_id = ?", id); err != nil {
+            return err
+        }
+        _, err := tx.exec(ctx, "DELETE FROM tags WHERE id = ?", id)
+        return err
+    })
+}
+
+// checkTagName returns ErrTagExists if a tag other than id is already called name
+func (s *SQLStore) checkTagName(ctx context.Context, id int, name string) error {
+    var other int
+    err := s.queryRow(ctx, "SELECT id FROM tags WHERE workspace_id = ? AND name = ? AND id <> ?", WorkspaceID(ctx), name, id).Scan(&other)
+    if errors.Is(err, sql.ErrNoRows) {
+        return nil
+    }
+    if err != nil {
+        return err
+    }
+    return ErrTagExists
+}
+
+// setTags replaces the tags of a task, creating tags that don't exist yet
+func (s *SQLStore) setTags(ctx context.Context, taskID int, names []string) error {
+    names = normalizeTags(names)
+    if _, err := s.exec(ctx, "DELETE FROM task_tags WHERE task_id = ?", taskID); err != nil {
+        return err
+    }
+    if len(names) == 0 {
+        return nil
+    }
+
+    workspace := WorkspaceID(ctx)
+    for _, name := range names {
+        // checked first rather than ON CONFLICT, which still burns an id on SQLite
+        if _, err := s.exec(ctx, "INSERT INTO tags(workspace_id, name) SELECT ?, ? WHERE NOT EXISTS (SELECT 1 FROM tags WHERE workspace_id = ? AND name = ?)",
+            workspace, name, workspace, name); err != nil {
+            return err
+        }
+    }
+
+    args := append([]any{taskID, workspace}, stringArgs(names)...)
+    _, err := s.exec(ctx, "INSERT INTO task_tags(task_id, tag_id) SELECT ?, id FROM tags WHERE workspace_id = ? AND name IN ("+placeholders(len(names))+")", args...)
+    return err
+}
+
+// loadTags fills in the tag names of tasks
+func (s *SQLStore) loadTags(ctx context.Context, tasks ...*models.Task) error {
+    if len(tasks) == 0 {
+        return nil
+    }
+
+    byID := make(map[int]*models.Task, len(tasks))
+    args := make([]any, 0, len(tasks))
+    for _, task := range tasks {
+        task.Tags = []string{}
+        if _, ok := byID[task.ID]; !ok {
+            args = append(args, task.ID)
+        }
+        byID[task.ID] = task
+    }
+
+    rows, err := s.query(ctx, `SELECT tt.task_id, g.name FROM task_tags tt JOIN tags g ON g.id = tt.tag_id
+        WHERE tt.task_id IN (`+placeholders(len(args))+`) ORDER BY g.name`, args...)
+    if err != nil {
+        return err
+    }
+    defer rows.Close()
+
+    for rows.Next() {
+        var id int
+        var name string
+        if err := rows.Scan(&id, &name); err != nil {
+            return err
+        }
+        task := byID[id]
+        task.Tags = append(task.Tags, name)
+    }
+    return rows.Err()
+}
+
+// tagFilter returns the WHERE clauses and arguments restricting tasks to those carrying any
+// of the tags in anyOf and all of the tags in allOf
+func tagFilter(anyOf, allOf []string) ([]string, []any) {
+    var where []string
+    var args []any
+
+    if names := normalizeTags(anyOf); len(names) > 0 {
+        where = append(where, `id IN (SELECT tt.task_id FROM task_tags tt JOIN tags g ON g.id = tt.tag_id
+            WHERE g.name IN (`+placeholders(len(names))+`))`)
+        args = append(args, stringArgs(names)...)
+    }
+    if names := normalizeTags(allOf); len(names) > 0 {
+        where = append(where, `id IN (SELECT tt.task_id FROM task_tags tt JOIN tags g ON g.id = tt.tag_id
+            WHERE g.name IN (`+placeholders(len(names))+`) GROUP BY tt.task_id HAVING COUNT(*) = ?)`)
+        args = append(args, stringArgs(names)...)
+        args = append(args, len(names))
+    }
+    return where, args
+}
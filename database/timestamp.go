@@ -0,0 +1,59 @@
+package database
+
+import (
+    "database/sql/driver"
+    "fmt"
+    "time"
+    "github.com/maazxenon/task-api/dates"
+)
+
+// timestamp scans a due_date or archived_at column into its stored string form. SQLite
+// keeps UTC text, PostgreSQL returns a timestamptz, and NULL becomes the empty string.
+type timestamp struct {
+    value *string
+}
+
+// Scan implements sql.Scanner
+func (d timestamp) Scan(src any) error {
+    switch v := src.(type) {
+    case nil:
+        *d.value = ""
+    case time.Time:
+        *d.value = dates.Format(v)
+    case string:
+        *d.value = v
+    case []byte:
+        *d.value = string(v)
+    default:
+        return fmt.Errorf("unsupported timestamp type %T", src)
+    }
+    return nil
+}
+
+// timestampArg is the value written to a timestamp column, NULL for the empty string
+func timestampArg(value string) driver.Value {
+    if value == "" {
+        return nil
+    }
+    return value
+}
+
+// sortExpr returns the ORDER BY expression for a column of SortFields. Tasks without a
+// due date sort before all others on both dialects, so NULLs don't break keyset paging.
+func (d Dialect) sortExpr(column string) string {
+    if column != "due_date" {
+        return column
+    }
+    if d == Postgres {
+        return "COALESCE(due_date, '-infinity')"
+    }
+    return "COALESCE(due_date, '')"
+}
+
+// sortArg converts a cursor value of column to the value compared against sortExpr
+func (d Dialect) sortArg(column, value string) any {
+    if column == "due_date" && value == "" && d == Postgres {
+        return "-infinity"
+    }
+    return value
+}
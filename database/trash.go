@@ -0,0 +1,159 @@
+package database
+
+import (
+    "context"
+    "time"
+    "github.com/maazxenon/task-api/dates"
+    "github.com/maazxenon/task-api/models"
+)
+
+// TrashStore is the persistence layer used to recover and purge deleted tasks
+type TrashStore interface {
+    // Trash returns the trashed tasks, most recently deleted first
+    Trash(ctx context.Context) ([]models.Task, error)
+    // TrashedTask returns the trashed task with the given ID or ErrTaskNotFound
+    TrashedTask(ctx context.Context, id int) (models.Task, error)
+    // TrashedSubtree returns a trashed task followed by the subtasks deleted along with it,
+    // which Restore brings back too, or ErrTaskNotFound
+    TrashedSubtree(ctx context.Context, id int) ([]models.Task, error)
+    // Restore takes a task out of the trash together with the subtasks deleted along with it
+    Restore(ctx context.Context, id int) (models.Task, error)
+    // PurgeTrash permanently removes the tasks of every workspace trashed before the given
+    // time and returns how many there were
+    PurgeTrash(ctx context.Context, before time.Time) (int, error)
+}
+
+// trashedTask is the condition matching tasks in the trash
+const trashedTask = "deleted_at IS NOT NULL"
+
+// Trash returns the trashed tasks
+func (s *SQLStore) Trash(ctx context.Context) ([]models.Task, error) {
+    rows, err := s.query(ctx, "SELECT "+taskColumns+" FROM tasks WHERE workspace_id = ? AND "+trashedTask+" ORDER BY deleted_at DESC, id",
+        WorkspaceID(ctx))
+    if err != nil {
+        return nil, err
+    }
+    return s.collectTasks(ctx, rows)
+}
+
+// TrashedTask returns the trashed task with the given ID
+func (s *SQLStore) TrashedTask(ctx context.Context, id int) (models.Task, error) {
+    return s.getTask(ctx, id, trashedTask)
+}
+
+// TrashedSubtree returns a trashed task and the subtasks trashed with it, which are the
+// descendants deleted by the same call
+func (s *SQLStore) TrashedSubtree(ctx context.Context, id int) ([]models.Task, error) {
+    task, err := s.TrashedTask(ctx, id)
+    if err != nil {
+        return nil, err
+    }
+
+    rows, err := s.query(ctx, `WITH RECURSIVE descendants(id) AS (
+        SELECT id FROM tasks WHERE parent_id = ? AND trash_root_id = (SELECT trash_root_id FROM tasks WHERE id = ?)
+        UNION ALL
+        SELECT t.id FROM tasks t JOIN descendants d ON t.parent_id = d.id WHERE t.trash_root_id = (SELECT trash_root_id FROM tasks WHERE id = ?)
+    ) SELECT `+taskColumns+` FROM tasks WHERE id IN (SELECT id FROM descendants) ORDER BY id`, id, id, id)
+    if err != nil {
+        return nil, err
+    }
+    subtasks, err := s.collectTasks(ctx, rows)
+    if err != nil {
+        return nil, err
+    }
+    return append([]models.Task{task}, subtasks...), nil
+}
+
+// Restore takes a task and the subtasks trashed with it out of the trash. A task whose
+// parent is still in the trash is detached from it.
+func (s *SQLStore) Restore(ctx context.Context, id int) (models.Task, error) {
+    var restored models.Task
+    err := s.withTx(ctx, func(tx *SQLStore) error {
+        trashed, err := tx.TrashedSubtree(ctx, id)
+        if err != nil {
+            return err
+        }
+        task, subtasks := trashed[0], trashed[1:]
+
+        parentID := task.ParentID
+        if parentID != nil && tx.exists(ctx, *parentID) != nil {
+            parentID = nil
+        }
+        if _, err := tx.exec(ctx, "UPDATE tasks SET deleted_at = NULL, trash_root_id = NULL, parent_id = ?, version = version + 1 WHERE id = ?", parentID, id); err != nil {
+            return err
+        }
+        if len(subtasks) > 0 {
+            ids := make([]any, len(subtasks))
+            for i, t := range subtasks {
+                ids[i] = t.ID
+            }
+            if _, err := tx.exec(ctx, "UPDATE tasks SET deleted_at = NULL, trash_root_id = NULL, version = version + 1 WHERE id IN ("+placeholders(len(ids))+")", ids...); err != nil {
+                return err
+            }
+        }
+
+        for i := range trashed {
+            if err := tx.auditCurrent(ctx, AuditRestore, trashed[i].ID, &trashed[i]); err != nil {
+                return err
+            }
+        }
+
+        restored, err = tx.Get(ctx, id)
+        return err
+    })
+    if err != nil {
+        return models.Task{}, err
+    }
+    return restored, nil
+}
+
+// PurgeTrash permanently removes tasks trashed before the given time
+func (s *SQLStore) PurgeTrash(ctx context.Context, before time.Time) (int, error) {
+    var purged int
+    err := s.withTx(ctx, func(tx *SQLStore) error {
+        rows, err := tx.query(ctx, "SELECT id, workspace_id FROM tasks WHERE "+trashedTask+" AND deleted_at < ? ORDER BY id", dates.Format(before))
+        if err != nil {
+            return err
+        }
+        expired := map[int]int{}
+        var ids []int
+        for rows.Next() {
+            var id, workspace int
+            if err := rows.Scan(&id, &workspace); err != nil {
+                rows.Close()
+                return err
+            }
+            expired[id] = workspace
+            ids = append(ids, id)
+        }
+        rows.Close()
+        if err := rows.Err(); err != nil {
+            return err
+        }
+
+        for _, id := range ids {
+            ctx := WithWorkspace(ctx, expired[id])
+            task, err := tx.TrashedTask(ctx, id)
+            if err != nil {
+                return err
+            }
+            // Subtasks deleted later than their parent may outlive it in the trash
+            if _, err := tx.exec(ctx, "UPDATE tasks SET parent_id = NULL, version = version + 1 WHERE parent_id = ?", id); err != nil {
+                return err
+            }
+            if _, err := tx.exec(ctx, "DELETE FROM tasks WHERE id = ?", id); err != nil {
+                return err
+            }
+            if err := tx.audit(ctx, AuditPurge, id, &task, nil); err != nil {
+                return err
+            }
+        }
+
+        purged = len(ids)
+        if purged == 0 {
+            return nil
+        }
+        return tx.pruneReferences(ctx)
+    })
+    return purged, err
+}
@@ -0,0 +1,103 @@
+package database
+
+import (
+    "errors"
+    "reflect"
+    "testing"
+    "time"
+)
+
+func TestRestoreSubtree(t *testing.T) {
+    s, ctx := newTestStore(t)
+    parent := createTask(t, s, ctx, "Plan trip", nil)
+    child := createTask(t, s, ctx, "Book flights", &parent.ID)
+    grandchild := createTask(t, s, ctx, "Compare fares", &child.ID)
+    sibling := createTask(t, s, ctx, "Book hotel", &parent.ID)
+
+    // The sibling is trashed on its own moments before its parent, usually within the
+    // same second, and must stay in the trash when the parent comes back
+    if err := s.Delete(ctx, sibling.ID, DeleteOptions{}); err != nil {
+        t.Fatalf("Delete sibling: %v", err)
+    }
+    if err := s.Delete(ctx, parent.ID, DeleteOptions{Cascade: true}); err != nil {
+        t.Fatalf("Delete parent: %v", err)
+    }
+
+    trashed, err := s.TrashedSubtree(ctx, parent.ID)
+    if err != nil {
+        t.Fatalf("TrashedSubtree: %v", err)
+    }
+    if got, want := taskIDs(trashed), []int{parent.ID, child.ID, grandchild.ID}; !reflect.DeepEqual(got, want) {
+        t.Errorf("TrashedSubtree = %v, want %v", got, want)
+    }
+
+    if _, err := s.Restore(ctx, parent.ID); err != nil {
+        t.Fatalf("Restore: %v", err)
+    }
+    for _, id := range []int{parent.ID, child.ID, grandchild.ID} {
+        if _, err := s.Get(ctx, id); err != nil {
+            t.Errorf("Get(%d) after restore: %v", id, err)
+        }
+    }
+    if _, err := s.TrashedTask(ctx, sibling.ID); err != nil {
+        t.Errorf("sibling trashed on its own was restored with its parent: %v", err)
+    }
+}
+
+func TestRestoreDescendant(t *testing.T) {
+    s, ctx := newTestStore(t)
+    parent := createTask(t, s, ctx, "Plan trip", nil)
+    child := createTask(t, s, ctx, "Book flights", &parent.ID)
+    grandchild := createTask(t, s, ctx, "Compare fares", &child.ID)
+    if err := s.Delete(ctx, parent.ID, DeleteOptions{Cascade: true}); err != nil {
+        t.Fatalf("Delete: %v", err)
+    }
+
+    restored, err := s.Restore(ctx, child.ID)
+    if err != nil {
+        t.Fatalf("Restore: %v", err)
+    }
+    if restored.ParentID != nil {
+        t.Errorf("restored task keeps trashed parent %d", *restored.ParentID)
+    }
+    if _, err := s.Get(ctx, grandchild.ID); err != nil {
+        t.Errorf("subtask trashed with the restored task is still trashed: %v", err)
+    }
+    if _, err := s.TrashedTask(ctx, parent.ID); err != nil {
+        t.Errorf("parent left the trash: %v", err)
+    }
+}
+
+func TestPurgeTrash(t *testing.T) {
+    s, ctx := newTestStore(t)
+    parent := createTask(t, s, ctx, "Plan trip", nil)
+    child := createTask(t, s, ctx, "Book flights", &parent.ID)
+    live := createTask(t, s, ctx, "Water plants", nil)
+    if err := s.Delete(ctx, parent.ID, DeleteOptions{Cascade: true}); err != nil {
+        t.Fatalf("Delete: %v", err)
+    }
+
+    purged, err := s.PurgeTrash(ctx, time.Now().Add(-time.Hour))
+    if err != nil {
+        t.Fatalf("PurgeTrash: %v", err)
+    }
+    if purged != 0 {
+        t.Errorf("PurgeTrash purged %d tasks trashed after the cutoff", purged)
+    }
+
+    purged, err = s.PurgeTrash(ctx, time.Now().Add(time.Hour))
+    if err != nil {
+        t.Fatalf("PurgeTrash: %v", err)
+    }
+    if purged != 2 {
+        t.Errorf("PurgeTrash = %d, want 2", purged)
+    }
+    for _, id := range []int{parent.ID, child.ID} {
+        if _, err := s.TrashedTask(ctx, id); !errors.Is(err, ErrTaskNotFound) {
+            t.Errorf("TrashedTask(%d) after purge error = %v, want ErrTaskNotFound", id, err)
+        }
+    }
+    if _, err := s.Get(ctx, live.ID); err != nil {
+        t.Errorf("live task was purged: %v", err)
+    }
+}
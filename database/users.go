@@ -0,0 +1,269 @@
+package database
+
+import (
+    "context"
+    "database/sql"
+    "errors"
+    "strings"
+    "github.com/maazxenon/task-api/models"
+)
+
+// ErrUserNotFound is returned when a user doesn't exist
+var ErrUserNotFound = errors.New("user not found")
+
+// ErrInvalidAssignee is returned when a task is assigned to a user that doesn't exist
+var ErrInvalidAssignee = errors.New("assignee does not exist")
+
+// ErrEmailTaken is returned when creating or updating a user with an email already in use
+var ErrEmailTaken = errors.New("a user with this email already exists")
+
+// ErrLastAdmin is returned when deleting or demoting the only admin
+var ErrLastAdmin = errors.New("the last admin can't be removed or demoted")
+
+// UserStore is the persistence layer used by the handlers to manage users
+type UserStore interface {
+    // Users returns all users ordered by name
+    Users(ctx context.Context) ([]models.User, error)
+    // GetUser returns the user with the given ID or ErrUserNotFound
+    GetUser(ctx context.Context, id int) (models.User, error)
+    // UserByEmail returns the user with the given email, including the password hash, or ErrUserNotFound
+    UserByEmail(ctx context.Context, email string) (models.User, error)
+    // CreateUser inserts a new user or returns ErrEmailTaken. Without a role the first user
+    // of the workspace becomes an admin and later ones members.
+    CreateUser(ctx context.Context, user models.User) (models.User, error)
+    // UpdateUser overwrites the name and email of a user, and the password hash if set
+    UpdateUser(ctx context.Context, user models.User) (models.User, error)
+    // SetUserRole changes the role of a user, refusing to demote the last admin
+    SetUserRole(ctx context.Context, id int, role string) (models.User, error)
+    // DeleteUser removes a user; tasks assigned to, created or changed by them keep no reference
+    DeleteUser(ctx context.Context, id int) error
+}
+
+const userColumns = "id, name, email, role"
+
+// Users returns all users
+func (s *SQLStore) Users(ctx context.Context) ([]models.User, error) {
+    rows, err := s.query(ctx, "SELECT "+userColumns+" FROM users WHERE workspace_id = ? ORDER BY name, id", WorkspaceID(ctx))
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    users := []models.User{}
+    for rows.Next() {
+        var user models.User
+        if err := rows.Scan(&user.ID, &user.Name, &user.Email, &user.Role); err != nil {
+            return nil, err
+        }
+        users = append(users, user)
+    }
+    return users, rows.Err()
+}
+
+// GetUser returns the user with the given ID
+func (s *SQLStore) GetUser(ctx context.Context, id int) (models.User, error) {
+    var user models.User
+    err := s.queryRow(ctx, "SELECT "+userColumns+" FROM users WHERE id = ? AND workspace_id = ?", id, WorkspaceID(ctx)).Scan(&user.ID, &user.Name, &user.Email, &user.Role)
+    if errors.Is(err, sql.ErrNoRows) {
+        return models.User{}, ErrUserNotFound
+    }
+    return user, err
+}
+
+// UserByEmail returns the user with the given email
+func (s *SQLStore) UserByEmail(ctx context.Context, email string) (models.User, error) {
+    var user models.User
+    err := s.queryRow(ctx, "SELECT "+userColumns+", password_hash FROM users WHERE email = ? AND workspace_id = ?", strings.ToLower(strings.TrimSpace(email)), WorkspaceID(ctx)).
+        Scan(&user.ID, &user.Name, &user.Email, &user.Role, &user.PasswordHash)
+    if errors.Is(err, sql.ErrNoRows) {
+        return models.User{}, ErrUserNotFound
+    }
+    return user, err
+}
+
+// CreateUser inserts a new user
+func (s *SQLStore) CreateUser(ctx context.Context, user models.User) (models.User, error) {
+    user.Email = strings.ToLower(strings.TrimSpace(user.Email))
+    err := s.withTx(ctx, func(tx *SQLStore) error {
+        if err := tx.checkQuota(ctx, QuotaUsers); err != nil {
+            return err
+        }
+        if err := tx.checkEmail(ctx, 0, user.Email); err != nil {
+            return err
+        }
+
+        if user.Role == "" {
+            var users int
+            if err := tx.queryRow(ctx, "SELECT COUNT(*) FROM users WHERE workspace_id = ?", WorkspaceID(ctx)).Scan(&users); err != nil {
+                return err
+            }
+            user.Role = "member"
+            if users == 0 {
+                user.Role = "admin"
+            }
+        }
+
+        id, err := tx.insert(ctx, "INSERT INTO users(workspace_id, name, email, role, password_hash) VALUES(?, ?, ?, ?, ?)",
+            WorkspaceID(ctx), user.Name, user.Email, user.Role, user.PasswordHash)
+        user.ID = id
+        return err
+    })
+    if err != nil {
+        return models.User{}, err
+    }
+    return user, nil
+}
+
+// UpdateUser overwrites an existing user
+func (s *SQLStore) UpdateUser(ctx context.Context, user models.User) (models.User, error) {
+    user.Email = strings.ToLower(strings.TrimSpace(user.Email))
+    err := s.withTx(ctx, func(tx *SQLStore) error {
+        if err := tx.checkEmail(ctx, user.ID, user.Email); err != nil {
+            return err
+        }
+
+        query := "UPDATE users SET name = ?, email = ?"
+        args := []any{user.Name, user.Email}
+        if user.PasswordHash != "" {
+            query += ", password_hash = ?"
+            args = append(args, user.PasswordHash)
+        }
+
+        result, err := tx.exec(ctx, query+" WHERE id = ? AND workspace_id = ?", append(args, user.ID, WorkspaceID(ctx))...)
+        if err != nil {
+            return err
+        }
+        if err := checkAffected(result); err != nil {
+            return ErrUserNotFound
+        }
+        return nil
+    })
+    if err != nil {
+        return models.User{}, err
+    }
+    return s.GetUser(ctx, user.ID)
+}
+
+// SetUserRole changes the role of a user
+func (s *SQLStore) SetUserRole(ctx context.Context, id int, role string) (models.User, error) {
+    var user models.User
+    err := s.withTx(ctx, func(tx *SQLStore) error {
+        var err error
+        if user, err = tx.GetUser(ctx, id); err != nil {
+            return err
+        }
+        if role != "admin" {
+            if err := tx.checkLastAdmin(ctx, user); err != nil {
+                return err
+            }
+        }
+
+        if _, err := tx.exec(ctx, "UPDATE users SET role = ? WHERE id = ?", role, id); err != nil {
+            return err
+        }
+        user.Role = role
+        return nil
+    })
+    if err != nil {
+        return models.User{}, err
+    }
+    return user, nil
+}
+
+// DeleteUser removes a user and clears the task references to them
+func (s *SQLStore) DeleteUser(ctx context.Context, id int) error {
+    return s.withTx(ctx, func(tx *SQLStore) error {
+        user, err := tx.GetUser(ctx, id)
+        if err != nil {
+            return err
+        }
+        if err := tx.checkLastAdmin(ctx, user); err != nil {
+            return err
+        }
+
+        // The tasks losing their reference to the user are kept for the audit log
+        rows, err := tx.query(ctx, "SELECT "+taskColumns+" FROM tasks WHERE (assignee_id = ? OR created_by = ? OR updated_by = ?) AND workspace_id = ? ORDER BY id",
+            id, id, id, WorkspaceID(ctx))
+        if err != nil {
+            return err
+        }
+        before, err := tx.collectTasks(ctx, rows)
+        if err != nil {
+            return err
+        }
+
+        _, err = tx.exec(ctx, `UPDATE tasks SET
+            assignee_id = CASE WHEN assignee_id = ? THEN NULL ELSE assignee_id END,
+            created_by = CASE WHEN created_by = ? THEN NULL ELSE created_by END,
+            updated_by = CASE WHEN updated_by = ? THEN NULL ELSE updated_by END,
+            version = version + 1
+            WHERE assignee_id = ? OR created_by = ? OR updated_by = ?`, id, id, id, id, id, id)
+        if err != nil {
+            return err
+        }
+        after, err := tx.tasksByID(ctx, before)
+        if err != nil {
+            return err
+        }
+        for i := range after {
+            if err := tx.audit(ctx, AuditUpdate, after[i].ID, &before[i], &after[i]); err != nil {
+                return err
+            }
+        }
+
+        if _, err := tx.exec(ctx, "DELETE FROM api_keys WHERE user_id = ?", id); err != nil {
+            return err
+        }
+
+        result, err := tx.exec(ctx, "DELETE FROM users WHERE id = ?", id)
+        if err != nil {
+            return err
+        }
+        if err := checkAffected(result); err != nil {
+            return ErrUserNotFound
+        }
+        return nil
+    })
+}
+
+// checkEmail returns ErrEmailTaken if a user other than id already has email
+func (s *SQLStore) checkEmail(ctx context.Context, id int, email string) error {
+    var other int
+    err := s.queryRow(ctx, "SELECT id FROM users WHERE workspace_id = ? AND email = ? AND id <> ?", WorkspaceID(ctx), email, id).Scan(&other)
+    if errors.Is(err, sql.ErrNoRows) {
+        return nil
+    }
+    if err != nil {
+        return err
+    }
+    return ErrEmailTaken
+}
+
+// checkLastAdmin returns ErrLastAdmin if user is the only admin
+func (s *SQLStore) checkLastAdmin(ctx context.Context, user models.User) error {
+    if user.Role != "admin" {
+        return nil
+    }
+
+    var others int
+    if err := s.queryRow(ctx, "SELECT COUNT(*) FROM users WHERE workspace_id = ? AND role = 'admin' AND id <> ?", WorkspaceID(ctx), user.ID).Scan(&others); err != nil {
+        return err
+    }
+    if others == 0 {
+        return ErrLastAdmin
+    }
+    return nil
+}
+
+// checkAssignee verifies that a task can be assigned to assigneeID
+func (s *SQLStore) checkAssignee(ctx context.Context, assigneeID *int) error {
+    if assigneeID == nil {
+        return nil
+    }
+
+    _, err := s.GetUser(ctx, *assigneeID)
+    if errors.Is(err, ErrUserNotFound) {
+        return ErrInvalidAssignee
+    }
+    return err
+}
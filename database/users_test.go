@@ -0,0 +1,128 @@
+package database
+
+import (
+    "errors"
+    "testing"
+    "github.com/maazxenon/task-api/models"
+)
+
+func TestDeleteUserAuditsTasks(t *testing.T) {
+    s, ctx := newTestStore(t)
+    bob, err := s.CreateUser(ctx, models.User{Name: "Bob", Email: "bob@example.com"})
+    if err != nil {
+        t.Fatalf("CreateUser: %v", err)
+    }
+    task, err := s.Create(WithActor(ctx, bob.ID), models.Task{Title: "Review", Status: "pending", AssigneeID: &bob.ID})
+    if err != nil {
+        t.Fatalf("Create: %v", err)
+    }
+    other := createTask(t, s, ctx, "Unrelated", nil)
+
+    if err := s.DeleteUser(ctx, bob.ID); err != nil {
+        t.Fatalf("DeleteUser: %v", err)
+    }
+
+    got, err := s.Get(ctx, task.ID)
+    if err != nil {
+        t.Fatalf("Get: %v", err)
+    }
+    if got.AssigneeID != nil || got.CreatedBy != nil || got.UpdatedBy != nil {
+        t.Errorf("task still references the deleted user: %+v", got)
+    }
+    if got.Version != task.Version+1 {
+        t.Errorf("version = %d, want %d", got.Version, task.Version+1)
+    }
+
+    history, err := s.TaskHistory(ctx, task.ID)
+    if err != nil {
+        t.Fatalf("TaskHistory: %v", err)
+    }
+    last := history[len(history)-1]
+    if last.Action != AuditUpdate || last.Before == nil || last.After == nil {
+        t.Fatalf("last history entry = %+v, want an update", last)
+    }
+    if last.Before.AssigneeID == nil || *last.Before.AssigneeID != bob.ID || last.After.AssigneeID != nil {
+        t.Errorf("audited assignee %v -> %v, want %d -> nil", last.Before.AssigneeID, last.After.AssigneeID, bob.ID)
+    }
+    if last.After.Version != got.Version {
+        t.Errorf("audited version = %d, want %d", last.After.Version, got.Version)
+    }
+
+    history, err = s.TaskHistory(ctx, other.ID)
+    if err != nil {
+        t.Fatalf("TaskHistory: %v", err)
+    }
+    if len(history) != 1 {
+        t.Errorf("unrelated task has %d history entries, want 1", len(history))
+    }
+}
+
+func TestUpdateUserPassword(t *testing.T) {
+    s, ctx := newTestStore(t)
+    user, err := s.CreateUser(ctx, models.User{Name: "Bob", Email: " Bob@Example.com", PasswordHash: "first"})
+    if err != nil {
+        t.Fatalf("CreateUser: %v", err)
+    }
+
+    hash := func() string {
+        t.Helper()
+        stored, err := s.UserByEmail(ctx, "BOB@example.com ")
+        if err != nil {
+            t.Fatalf("UserByEmail: %v", err)
+        }
+        return stored.PasswordHash
+    }
+    if got := hash(); got != "first" {
+        t.Fatalf("password hash = %q, want first", got)
+    }
+
+    // Updates without a hash keep the password
+    user.Name = "Robert"
+    if _, err := s.UpdateUser(ctx, user); err != nil {
+        t.Fatalf("UpdateUser: %v", err)
+    }
+    if got := hash(); got != "first" {
+        t.Errorf("password hash after renaming = %q, want first", got)
+    }
+
+    user.PasswordHash = "second"
+    if _, err := s.UpdateUser(ctx, user); err != nil {
+        t.Fatalf("UpdateUser: %v", err)
+    }
+    if got := hash(); got != "second" {
+        t.Errorf("password hash after reset = %q, want second", got)
+    }
+}
+
+func TestLastAdmin(t *testing.T) {
+    s, ctx := newTestStore(t)
+    admin := *Actor(ctx)
+
+    if _, err := s.SetUserRole(ctx, admin, "member"); !errors.Is(err, ErrLastAdmin) {
+        t.Errorf("demoting the last admin error = %v, want ErrLastAdmin", err)
+    }
+    if err := s.DeleteUser(ctx, admin); !errors.Is(err, ErrLastAdmin) {
+        t.Errorf("deleting the last admin error = %v, want ErrLastAdmin", err)
+    }
+
+    bob, err := s.CreateUser(ctx, models.User{Name: "Bob", Email: "bob@example.com"})
+    if err != nil {
+        t.Fatalf("CreateUser: %v", err)
+    }
+    if bob.Role != "member" {
+        t.Errorf("second user role = %q, want member", bob.Role)
+    }
+    if _, err := s.SetUserRole(ctx, bob.ID, "admin"); err != nil {
+        t.Fatalf("SetUserRole: %v", err)
+    }
+    demoted, err := s.SetUserRole(ctx, admin, "viewer")
+    if err != nil {
+        t.Fatalf("demoting an admin with another one left: %v", err)
+    }
+    if demoted.Role != "viewer" {
+        t.Errorf("demoted role = %q, want viewer", demoted.Role)
+    }
+    if err := s.DeleteUser(ctx, bob.ID); !errors.Is(err, ErrLastAdmin) {
+        t.Errorf("deleting the new last admin error = %v, want ErrLastAdmin", err)
+    }
+}
@@ -0,0 +1,204 @@
+package database
+
+import (
+    "context"
+    "database/sql"
+    "errors"
+    "fmt"
+    "strings"
+    "github.com/maazxenon/task-api/models"
+)
+
+// ErrWorkspaceNotFound is returned when a workspace doesn't exist
+var ErrWorkspaceNotFound = errors.New("workspace not found")
+
+// ErrWorkspaceExists is returned when creating a workspace with a slug already in use
+var ErrWorkspaceExists = errors.New("a workspace with this slug already exists")
+
+// ErrQuotaExceeded is matched by the QuotaError returned when a workspace is full
+var ErrQuotaExceeded = errors.New("workspace quota exceeded")
+
+// QuotaError reports which quota of a workspace a write would exceed
+type QuotaError struct {
+    Resource string
+    Limit    int
+}
+
+func (e *QuotaError) Error() string {
+    return fmt.Sprintf("workspace quota of %d %s reached", e.Limit, e.Resource)
+}
+
+// Is makes errors.Is(err, ErrQuotaExceeded) hold for a QuotaError
+func (e *QuotaError) Is(target error) bool {
+    return target == ErrQuotaExceeded
+}
+
+// Quota resources, named after the tables they count
+const (
+    QuotaUsers    = "users"
+    QuotaProjects = "projects"
+    QuotaTasks    = "tasks"
+)
+
+// WorkspaceStore is the persistence layer used to manage workspaces
+type WorkspaceStore interface {
+    // Workspaces returns all workspaces ordered by slug
+    Workspaces(ctx context.Context) ([]models.Workspace, error)
+    // GetWorkspace returns the workspace with the given ID or ErrWorkspaceNotFound
+    GetWorkspace(ctx context.Context, id int) (models.Workspace, error)
+    // WorkspaceBySlug returns the workspace with the given slug or ErrWorkspaceNotFound
+    WorkspaceBySlug(ctx context.Context, slug string) (models.Workspace, error)
+    // CreateWorkspace inserts a workspace with the default quotas and its first user, an admin
+    CreateWorkspace(ctx context.Context, workspace models.Workspace, admin models.User) (models.Workspace, models.User, error)
+    // SetQuota changes one quota of a workspace; 0 lifts it
+    SetQuota(ctx context.Context, id int, resource string, limit int) (models.Workspace, error)
+}
+
+type workspaceKey struct{}
+
+// WithWorkspace returns a context scoping the store to a workspace. Every query on users,
+// tasks, projects and tags only sees rows of that workspace.
+func WithWorkspace(ctx context.Context, id int) context.Context {
+    return context.WithValue(ctx, workspaceKey{}, id)
+}
+
+// WorkspaceID returns the workspace ctx is scoped to, or 0, which matches no rows
+func WorkspaceID(ctx context.Context) int {
+    id, _ := ctx.Value(workspaceKey{}).(int)
+    return id
+}
+
+// quotaColumns maps quota resources to the workspaces column holding their limit
+var quotaColumns = map[string]string{
+    QuotaUsers:    "max_users",
+    QuotaProjects: "max_projects",
+    QuotaTasks:    "max_tasks",
+}
+
+const workspaceColumns = `w.id, w.name, w.slug, w.max_users, w.max_projects, w.max_tasks,
+    (SELECT COUNT(*) FROM users u WHERE u.workspace_id = w.id),
+    (SELECT COUNT(*) FROM projects p WHERE p.workspace_id = w.id),
+    (SELECT COUNT(*) FROM tasks t WHERE t.workspace_id = w.id)`
+
+// scanWorkspace reads a row selected with workspaceColumns
+func scanWorkspace(row scanner) (models.Workspace, error) {
+    var w models.Workspace
+    err := row.Scan(&w.ID, &w.Name, &w.Slug, &w.Quotas.MaxUsers, &w.Quotas.MaxProjects, &w.Quotas.MaxTasks,
+        &w.Usage.Users, &w.Usage.Projects, &w.Usage.Tasks)
+    return w, err
+}
+
+// Workspaces returns all workspaces
+func (s *SQLStore) Workspaces(ctx context.Context) ([]models.Workspace, error) {
+    rows, err := s.query(ctx, "SELECT "+workspaceColumns+" FROM workspaces w ORDER BY w.slug")
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    workspaces := []models.Workspace{}
+    for rows.Next() {
+        w, err := scanWorkspace(rows)
+        if err != nil {
+            return nil, err
+        }
+        workspaces = append(workspaces, w)
+    }
+    return workspaces, rows.Err()
+}
+
+// GetWorkspace returns the workspace with the given ID
+func (s *SQLStore) GetWorkspace(ctx context.Context, id int) (models.Workspace, error) {
+    w, err := scanWorkspace(s.queryRow(ctx, "SELECT "+workspaceColumns+" FROM workspaces w WHERE w.id = ?", id))
+    if errors.Is(err, sql.ErrNoRows) {
+        return models.Workspace{}, ErrWorkspaceNotFound
+    }
+    return w, err
+}
+
+// WorkspaceBySlug returns the workspace with the given slug
+func (s *SQLStore) WorkspaceBySlug(ctx context.Context, slug string) (models.Workspace, error) {
+    w, err := scanWorkspace(s.queryRow(ctx, "SELECT "+workspaceColumns+" FROM workspaces w WHERE w.slug = ?", strings.ToLower(slug)))
+    if errors.Is(err, sql.ErrNoRows) {
+        return models.Workspace{}, ErrWorkspaceNotFound
+    }
+    return w, err
+}
+
+// CreateWorkspace inserts a new workspace and its admin
+func (s *SQLStore) CreateWorkspace(ctx context.Context, workspace models.Workspace, admin models.User) (models.Workspace, models.User, error) {
+    workspace.Slug = strings.ToLower(workspace.Slug)
+    err := s.withTx(ctx, func(tx *SQLStore) error {
+        var other int
+        err := tx.queryRow(ctx, "SELECT id FROM workspaces WHERE slug = ?", workspace.Slug).Scan(&other)
+        if err == nil {
+            return ErrWorkspaceExists
+        }
+        if !errors.Is(err, sql.ErrNoRows) {
+            return err
+        }
+
+        id, err := tx.insert(ctx, "INSERT INTO workspaces(name, slug) VALUES(?, ?)", workspace.Name, workspace.Slug)
+        if err != nil {
+            return err
+        }
+
+        ctx := WithWorkspace(ctx, id)
+        admin.Role = "admin"
+        if admin, err = tx.CreateUser(ctx, admin); err != nil {
+            return err
+        }
+        workspace, err = tx.GetWorkspace(ctx, id)
+        return err
+    })
+    if err != nil {
+        return models.Workspace{}, models.User{}, err
+    }
+    return workspace, admin, nil
+}
+
+// SetQuota changes one quota of a workspace
+func (s *SQLStore) SetQuota(ctx context.Context, id int, resource string, limit int) (models.Workspace, error) {
+    column, ok := quotaColumns[resource]
+    if !ok {
+        return models.Workspace{}, fmt.Errorf("unknown quota %q, expected users, projects or tasks", resource)
+    }
+    if limit < 0 {
+        return models.Workspace{}, errors.New("quota must not be negative")
+    }
+
+    result, err := s.exec(ctx, "UPDATE workspaces SET "+column+" = ? WHERE id = ?", limit, id)
+    if err != nil {
+        return models.Workspace{}, err
+    }
+    if err := checkAffected(result); err != nil {
+        return models.Workspace{}, ErrWorkspaceNotFound
+    }
+    return s.GetWorkspace(ctx, id)
+}
+
+// checkQuota returns a QuotaError if the workspace of ctx can't hold another resource
+func (s *SQLStore) checkQuota(ctx context.Context, resource string) error {
+    // Callers insert in the same transaction, so concurrent ones must not count the same
+    // rows. SQLite transactions begin with the write lock; PostgreSQL waits here on the
+    // workspace row, and the count below sees what the transactions before it committed.
+    if s.dialect == Postgres {
+        if _, err := s.exec(ctx, "SELECT id FROM workspaces WHERE id = ? FOR UPDATE", WorkspaceID(ctx)); err != nil {
+            return err
+        }
+    }
+
+    var limit, count int
+    err := s.queryRow(ctx, "SELECT "+quotaColumns[resource]+", (SELECT COUNT(*) FROM "+resource+" WHERE workspace_id = ?) FROM workspaces WHERE id = ?",
+        WorkspaceID(ctx), WorkspaceID(ctx)).Scan(&limit, &count)
+    if errors.Is(err, sql.ErrNoRows) {
+        return ErrWorkspaceNotFound
+    }
+    if err != nil {
+        return err
+    }
+    if limit > 0 && count >= limit {
+        return &QuotaError{Resource: resource, Limit: limit}
+    }
+    return nil
+}
@@ -0,0 +1,111 @@
+package database
+
+import (
+    "errors"
+    "sync"
+    "testing"
+    "github.com/maazxenon/task-api/models"
+)
+
+func TestWorkspaceIsolation(t *testing.T) {
+    s, acme := newTestStore(t)
+    globex := createWorkspace(t, s, "globex")
+
+    project, err := s.CreateProject(acme, models.Project{Name: "Launch"})
+    if err != nil {
+        t.Fatalf("CreateProject: %v", err)
+    }
+    user, err := s.CreateUser(acme, models.User{Name: "Alice", Email: "alice@example.com"})
+    if err != nil {
+        t.Fatalf("CreateUser: %v", err)
+    }
+    task := createTask(t, s, acme, "Ship it", nil)
+    own := createTask(t, s, globex, "Plan it", nil)
+
+    tests := []struct {
+        name    string
+        run     func() error
+        wantErr error
+    }{
+        {"get task", func() error { _, err := s.Get(globex, task.ID); return err }, ErrTaskNotFound},
+        {"update task", func() error {
+            _, err := s.Update(globex, models.Task{ID: task.ID, Title: "Taken", Status: "pending"}, 0)
+            return err
+        }, ErrTaskNotFound},
+        {"delete task", func() error { return s.Delete(globex, task.ID, DeleteOptions{}) }, ErrTaskNotFound},
+        {"parent in another workspace", func() error {
+            _, err := s.Create(globex, models.Task{Title: "Sub", Status: "pending", ParentID: &task.ID})
+            return err
+        }, ErrParentNotFound},
+        {"project in another workspace", func() error {
+            _, err := s.Create(globex, models.Task{Title: "Sub", Status: "pending", ProjectID: &project.ID})
+            return err
+        }, ErrInvalidProject},
+        {"assignee in another workspace", func() error {
+            _, err := s.Create(globex, models.Task{Title: "Sub", Status: "pending", AssigneeID: &user.ID})
+            return err
+        }, ErrInvalidAssignee},
+        {"blocker in another workspace", func() error { return s.AddDependency(globex, own.ID, task.ID) }, ErrBlockerNotFound},
+        {"get project", func() error { _, err := s.GetProject(globex, project.ID); return err }, ErrProjectNotFound},
+        {"delete project", func() error { return s.DeleteProject(globex, project.ID) }, ErrProjectNotFound},
+        {"get user", func() error { _, err := s.GetUser(globex, user.ID); return err }, ErrUserNotFound},
+        {"delete user", func() error { return s.DeleteUser(globex, user.ID) }, ErrUserNotFound},
+        {"restore task", func() error { _, err := s.Restore(globex, task.ID); return err }, ErrTaskNotFound},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            if err := tt.run(); !errors.Is(err, tt.wantErr) {
+                t.Errorf("error = %v, want %v", err, tt.wantErr)
+            }
+        })
+    }
+
+    page, err := s.List(globex, TaskFilter{})
+    if err != nil {
+        t.Fatalf("List: %v", err)
+    }
+    if got := taskIDs(page.Tasks); len(got) != 1 || got[0] != own.ID {
+        t.Errorf("List = %v, want only [%d]", got, own.ID)
+    }
+    if _, err := s.Get(acme, task.ID); err != nil {
+        t.Errorf("task changed by another workspace: %v", err)
+    }
+    // Emails are unique per workspace only
+    if _, err := s.CreateUser(globex, models.User{Name: "Alice", Email: "alice@example.com"}); err != nil {
+        t.Errorf("CreateUser with an email used in another workspace: %v", err)
+    }
+}
+
+func TestQuotaConcurrentCreates(t *testing.T) {
+    s, ctx := newTestStore(t)
+    const limit = 5
+    if _, err := s.SetQuota(ctx, WorkspaceID(ctx), QuotaTasks, limit); err != nil {
+        t.Fatalf("SetQuota: %v", err)
+    }
+
+    var wg sync.WaitGroup
+    errs := make(chan error, 4*limit)
+    for i := 0; i < 4*limit; i++ {
+        wg.Add(1)
+        go func() {
+            defer wg.Done()
+            _, err := s.Create(ctx, models.Task{Title: "Race", Status: "pending"})
+            errs <- err
+        }()
+    }
+    wg.Wait()
+    close(errs)
+
+    created := 0
+    for err := range errs {
+        switch {
+        case err == nil:
+            created++
+        case !errors.Is(err, ErrQuotaExceeded):
+            t.Errorf("Create error = %v, want nil or ErrQuotaExceeded", err)
+        }
+    }
+    if created != limit {
+        t.Errorf("created %d tasks, want the quota of %d", created, limit)
+    }
+}
@@ -0,0 +1,82 @@
+package dates
+
+import (
+    "context"
+    "errors"
+    "time"
+)
+
+// Layouts accepted for due dates. Date-only values mean midnight in the client's time zone.
+const (
+    DateLayout     = "2006-01-02"
+    DateTimeLayout = time.RFC3339
+)
+
+// storageLayout is how due dates are kept in the database: UTC with second precision,
+// so the stored text sorts chronologically
+const storageLayout = "2006-01-02T15:04:05Z"
+
+// ErrInvalid is returned for values that are neither an RFC 3339 timestamp nor a date
+var ErrInvalid = errors.New("must be an RFC 3339 timestamp or a date (YYYY-MM-DD)")
+
+// Parse reads an RFC 3339 timestamp or a date. Dates are taken as midnight in loc.
+func Parse(value string, loc *time.Location) (time.Time, error) {
+    if len(value) == len(DateLayout) {
+        if t, err := time.ParseInLocation(DateLayout, value, loc); err == nil {
+            return t, nil
+        }
+    } else if t, err := time.Parse(DateTimeLayout, value); err == nil {
+        return t, nil
+    }
+    return time.Time{}, ErrInvalid
+}
+
+// Valid reports whether value can be parsed as a due date
+func Valid(value string) bool {
+    _, err := Parse(value, time.UTC)
+    return err == nil
+}
+
+// Normalize converts a due date sent by a client in loc to its stored form.
+// The empty string stands for no due date and is returned unchanged.
+func Normalize(value string, loc *time.Location) (string, error) {
+    if value == "" {
+        return "", nil
+    }
+
+    t, err := Parse(value, loc)
+    if err != nil {
+        return "", err
+    }
+    return Format(t), nil
+}
+
+// Format returns the stored form of t
+func Format(t time.Time) string {
+    return t.UTC().Truncate(time.Second).Format(storageLayout)
+}
+
+// Render converts a stored due date to an RFC 3339 timestamp in loc. Values that
+// can't be parsed are returned unchanged.
+func Render(value string, loc *time.Location) string {
+    t, err := time.Parse(DateTimeLayout, value)
+    if err != nil {
+        return value
+    }
+    return t.In(loc).Format(DateTimeLayout)
+}
+
+type locationKey struct{}
+
+// WithLocation returns a context carrying the client's time zone
+func WithLocation(ctx context.Context, loc *time.Location) context.Context {
+    return context.WithValue(ctx, locationKey{}, loc)
+}
+
+// Location returns the client's time zone stored in ctx, or UTC
+func Location(ctx context.Context) *time.Location {
+    if loc, ok := ctx.Value(locationKey{}).(*time.Location); ok {
+        return loc
+    }
+    return time.UTC
+}
@@ -0,0 +1,266 @@
+package dates
+
+import (
+    "errors"
+    "regexp"
+    "strconv"
+    "strings"
+    "time"
+)
+
+// ErrUnrecognized is returned by ParseNatural for phrases it doesn't understand
+var ErrUnrecognized = errors.New("unrecognized date expression")
+
+var (
+    // clockPattern matches a time of day such as 5pm, 5:30 pm, 17:00 or noon, optionally after "at"
+    clockPattern = regexp.MustCompile(`(?:^|\s)(?:at\s+)?(?:(\d{1,2})(?::(\d{2}))?\s*(am|pm)|(\d{1,2}):(\d{2})|(noon|midnight))(?:\s|$)`)
+    // relativePattern matches "in 3 days" and "3 days from now"
+    relativePattern = regexp.MustCompile(`^(?:in\s+(\w+)\s+(\w+?)s?|(\w+)\s+(\w+?)s?\s+from\s+now)$`)
+    // monthDayPattern matches "dec 31", "december 31st 2024" and "31 december"
+    monthDayPattern = regexp.MustCompile(`^(?:([a-z]+)\s+(\d{1,2})(?:st|nd|rd|th)?|(\d{1,2})(?:st|nd|rd|th)?\s+(?:of\s+)?([a-z]+))(?:,?\s+(\d{4}))?$`)
+)
+
+var numberWords = map[string]int{
+    "a": 1, "an": 1, "one": 1, "two": 2, "three": 3, "four": 4, "five": 5, "six": 6,
+    "seven": 7, "eight": 8, "nine": 9, "ten": 10, "eleven": 11, "twelve": 12,
+}
+
+var weekdays = map[string]time.Weekday{
+    "sunday": time.Sunday, "sun": time.Sunday,
+    "monday": time.Monday, "mon": time.Monday,
+    "tuesday": time.Tuesday, "tue": time.Tuesday, "tues": time.Tuesday,
+    "wednesday": time.Wednesday, "wed": time.Wednesday,
+    "thursday": time.Thursday, "thu": time.Thursday, "thurs": time.Thursday,
+    "friday": time.Friday, "fri": time.Friday,
+    "saturday": time.Saturday, "sat": time.Saturday,
+}
+
+var months = map[string]time.Month{
+    "january": time.January, "jan": time.January,
+    "february": time.February, "feb": time.February,
+    "march": time.March, "mar": time.March,
+    "april": time.April, "apr": time.April,
+    "may": time.May,
+    "june": time.June, "jun": time.June,
+    "july": time.July, "jul": time.July,
+    "august": time.August, "aug": time.August,
+    "september": time.September, "sep": time.September, "sept": time.September,
+    "october": time.October, "oct": time.October,
+    "november": time.November, "nov": time.November,
+    "december": time.December, "dec": time.December,
+}
+
+// ParseNatural turns a natural-language expression such as "tomorrow", "next friday 5pm",
+// "in 3 days", "dec 31 at noon" or "5:30pm" into a time relative to ref, in ref's location.
+// Phrases naming a day without a time of day mean midnight at the start of that day;
+// "in N minutes" and "in N hours" keep the clock of ref. A bare time of day means its
+// next occurrence.
+func ParseNatural(phrase string, ref time.Time) (time.Time, error) {
+    s := strings.Join(strings.Fields(strings.ToLower(strings.Trim(phrase, " \t.!"))), " ")
+    s = strings.TrimPrefix(s, "due ")
+    if s == "" {
+        return time.Time{}, ErrUnrecognized
+    }
+    if s == "now" {
+        return ref, nil
+    }
+
+    hour, min, hasClock := -1, 0, false
+    if m := clockPattern.FindStringSubmatchIndex(s); m != nil {
+        var ok bool
+        hour, min, ok = parseClock(s, m)
+        if !ok {
+            return time.Time{}, ErrUnrecognized
+        }
+        hasClock = true
+        s = strings.TrimSpace(s[:m[0]] + " " + s[m[1]:])
+        s = strings.TrimSuffix(strings.TrimSpace(strings.TrimSuffix(s, " at")), " on")
+    }
+
+    today := time.Date(ref.Year(), ref.Month(), ref.Day(), 0, 0, 0, 0, ref.Location())
+    var day time.Time
+
+    switch {
+    case s == "":
+        if !hasClock {
+            return time.Time{}, ErrUnrecognized
+        }
+        day = today
+        if at(day, hour, min).Before(ref) {
+            day = day.AddDate(0, 0, 1)
+        }
+    case s == "today", s == "tonight":
+        day = today
+    case s == "tomorrow", s == "tmrw":
+        day = today.AddDate(0, 0, 1)
+    case s == "yesterday":
+        day = today.AddDate(0, 0, -1)
+    case s == "next week":
+        day = today.AddDate(0, 0, 7)
+    case s == "next month":
+        day = today.AddDate(0, 1, 0)
+    case s == "next year":
+        day = today.AddDate(1, 0, 0)
+    case s == "end of week":
+        day = today.AddDate(0, 0, (int(time.Sunday)-int(today.Weekday())+7)%7)
+    case s == "end of month":
+        day = today.AddDate(0, 1, -today.Day())
+    default:
+        var ok bool
+        if day, ok = parseWeekday(s, today); ok {
+            break
+        }
+        if day, ok = parseMonthDay(s, today); ok {
+            break
+        }
+
+        t, exact, ok := parseRelative(s, ref)
+        if !ok {
+            return time.Time{}, ErrUnrecognized
+        }
+        if exact && !hasClock {
+            return t, nil
+        }
+        day = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+    }
+
+    if !hasClock {
+        return day, nil
+    }
+    return at(day, hour, min), nil
+}
+
+// at returns the given time of day on day
+func at(day time.Time, hour, min int) time.Time {
+    return time.Date(day.Year(), day.Month(), day.Day(), hour, min, 0, 0, day.Location())
+}
+
+// parseClock reads the time of day matched by clockPattern at m
+func parseClock(s string, m []int) (hour, min int, ok bool) {
+    group := func(i int) string {
+        if m[2*i] < 0 {
+            return ""
+        }
+        return s[m[2*i]:m[2*i+1]]
+    }
+
+    switch {
+    case group(6) == "noon":
+        return 12, 0, true
+    case group(6) == "midnight":
+        return 0, 0, true
+    case group(3) != "":
+        hour, _ = strconv.Atoi(group(1))
+        if group(2) != "" {
+            min, _ = strconv.Atoi(group(2))
+        }
+        if hour < 1 || hour > 12 || min > 59 {
+            return 0, 0, false
+        }
+        hour %= 12
+        if group(3) == "pm" {
+            hour += 12
+        }
+        return hour, min, true
+    default:
+        hour, _ = strconv.Atoi(group(4))
+        min, _ = strconv.Atoi(group(5))
+        return hour, min, hour < 24 && min < 60
+    }
+}
+
+// parseWeekday reads "friday", "this friday" and "next friday". A bare or "this" weekday
+// is today or the next such day; "next" skips today.
+func parseWeekday(s string, today time.Time) (time.Time, bool) {
+    next := false
+    switch {
+    case strings.HasPrefix(s, "next "):
+        s, next = strings.TrimPrefix(s, "next "), true
+    case strings.HasPrefix(s, "this "):
+        s = strings.TrimPrefix(s, "this ")
+    case strings.HasPrefix(s, "on "):
+        s = strings.TrimPrefix(s, "on ")
+    }
+
+    wd, ok := weekdays[s]
+    if !ok {
+        return time.Time{}, false
+    }
+
+    days := (int(wd) - int(today.Weekday()) + 7) % 7
+    if days == 0 && next {
+        days = 7
+    }
+    return today.AddDate(0, 0, days), true
+}
+
+// parseMonthDay reads a calendar date such as "dec 31" or "31 december 2024". Without a
+// year it is the next such date, today included.
+func parseMonthDay(s string, today time.Time) (time.Time, bool) {
+    m := monthDayPattern.FindStringSubmatch(s)
+    if m == nil {
+        return time.Time{}, false
+    }
+
+    name, dayStr := m[1], m[2]
+    if name == "" {
+        name, dayStr = m[4], m[3]
+    }
+    month, ok := months[name]
+    if !ok {
+        return time.Time{}, false
+    }
+    day, _ := strconv.Atoi(dayStr)
+
+    year := today.Year()
+    if m[5] != "" {
+        year, _ = strconv.Atoi(m[5])
+    }
+
+    t := time.Date(year, month, day, 0, 0, 0, 0, today.Location())
+    if t.Day() != day {
+        // February 30th and the like
+        return time.Time{}, false
+    }
+    if m[5] == "" && t.Before(today) {
+        t = t.AddDate(1, 0, 0)
+    }
+    return t, true
+}
+
+// parseRelative reads "in 3 days" and "2 weeks from now". exact reports whether the
+// unit is shorter than a day, in which case the result keeps the clock of ref.
+func parseRelative(s string, ref time.Time) (t time.Time, exact, ok bool) {
+    m := relativePattern.FindStringSubmatch(s)
+    if m == nil {
+        return time.Time{}, false, false
+    }
+
+    count, unit := m[1], m[2]
+    if count == "" {
+        count, unit = m[3], m[4]
+    }
+    n, ok := numberWords[count]
+    if !ok {
+        var err error
+        if n, err = strconv.Atoi(count); err != nil {
+            return time.Time{}, false, false
+        }
+    }
+
+    switch unit {
+    case "minute", "min":
+        return ref.Add(time.Duration(n) * time.Minute), true, true
+    case "hour", "hr":
+        return ref.Add(time.Duration(n) * time.Hour), true, true
+    case "day":
+        return ref.AddDate(0, 0, n), false, true
+    case "week":
+        return ref.AddDate(0, 0, 7*n), false, true
+    case "month":
+        return ref.AddDate(0, n, 0), false, true
+    case "year":
+        return ref.AddDate(n, 0, 0), false, true
+    }
+    return time.Time{}, false, false
+}
@@ -0,0 +1,97 @@
+package dates
+
+import (
+    "errors"
+    "testing"
+    "time"
+)
+
+func TestParseNatural(t *testing.T) {
+    // Wednesday
+    ref := time.Date(2024, time.May, 15, 10, 30, 0, 0, time.UTC)
+    day := func(month time.Month, d, hour, min int) time.Time {
+        return time.Date(2024, month, d, hour, min, 0, 0, time.UTC)
+    }
+
+    tests := []struct {
+        phrase string
+        want   time.Time
+    }{
+        {"now", ref},
+        {"today", day(time.May, 15, 0, 0)},
+        {"Tomorrow.", day(time.May, 16, 0, 0)},
+        {"due tomorrow", day(time.May, 16, 0, 0)},
+        {"tmrw 9am", day(time.May, 16, 9, 0)},
+        {"yesterday", day(time.May, 14, 0, 0)},
+        {"tonight at 8pm", day(time.May, 15, 20, 0)},
+        {"next week", day(time.May, 22, 0, 0)},
+        {"next month", day(time.June, 15, 0, 0)},
+        {"next year", time.Date(2025, time.May, 15, 0, 0, 0, 0, time.UTC)},
+        {"end of week", day(time.May, 19, 0, 0)},
+        {"end of month", day(time.May, 31, 0, 0)},
+
+        {"friday", day(time.May, 17, 0, 0)},
+        {"this wednesday", day(time.May, 15, 0, 0)},
+        {"next wednesday", day(time.May, 22, 0, 0)},
+        {"next friday 5pm", day(time.May, 17, 17, 0)},
+        {"on mon at noon", day(time.May, 20, 12, 0)},
+
+        {"dec 31", day(time.December, 31, 0, 0)},
+        {"dec 31 at noon", day(time.December, 31, 12, 0)},
+        {"may 15", day(time.May, 15, 0, 0)},
+        {"jan 1", time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)},
+        {"31st of december, 2025", time.Date(2025, time.December, 31, 0, 0, 0, 0, time.UTC)},
+        {"june 3rd 2023", time.Date(2023, time.June, 3, 0, 0, 0, 0, time.UTC)},
+
+        {"in 3 days", day(time.May, 18, 0, 0)},
+        {"2 weeks from now", day(time.May, 29, 0, 0)},
+        {"in a month at 9:15am", day(time.June, 15, 9, 15)},
+        {"in an hour", day(time.May, 15, 11, 30)},
+        {"in 90 minutes", day(time.May, 15, 12, 0)},
+
+        {"5:30pm", day(time.May, 15, 17, 30)},
+        {"17:00", day(time.May, 15, 17, 0)},
+        {"9am", day(time.May, 16, 9, 0)},
+        {"12am", day(time.May, 16, 0, 0)},
+        {"midnight", day(time.May, 16, 0, 0)},
+    }
+    for _, tt := range tests {
+        t.Run(tt.phrase, func(t *testing.T) {
+            got, err := ParseNatural(tt.phrase, ref)
+            if err != nil {
+                t.Fatalf("ParseNatural(%q) error: %v", tt.phrase, err)
+            }
+            if !got.Equal(tt.want) {
+                t.Errorf("ParseNatural(%q) = %v, want %v", tt.phrase, got, tt.want)
+            }
+        })
+    }
+}
+
+func TestParseNaturalUnrecognized(t *testing.T) {
+    ref := time.Date(2024, time.May, 15, 10, 30, 0, 0, time.UTC)
+    for _, phrase := range []string{"", "  ", "someday", "at", "13pm", "25:00", "9:75am", "feb 30", "in three fortnights", "next funday"} {
+        t.Run(phrase, func(t *testing.T) {
+            if got, err := ParseNatural(phrase, ref); !errors.Is(err, ErrUnrecognized) {
+                t.Errorf("ParseNatural(%q) = %v, %v, want ErrUnrecognized", phrase, got, err)
+            }
+        })
+    }
+}
+
+func TestParseNaturalKeepsLocation(t *testing.T) {
+    // Late evening in UTC+10 is still the morning in UTC, so "tomorrow" depends on the zone
+    loc := time.FixedZone("UTC+10", 10*60*60)
+    ref := time.Date(2024, time.May, 15, 22, 0, 0, 0, loc)
+
+    got, err := ParseNatural("tomorrow 9am", ref)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if want := time.Date(2024, time.May, 16, 9, 0, 0, 0, loc); !got.Equal(want) || got.Location() != loc {
+        t.Errorf("got %v, want %v", got, want)
+    }
+    if s := Format(got); s != "2024-05-15T23:00:00Z" {
+        t.Errorf("Format = %s, want 2024-05-15T23:00:00Z", s)
+    }
+}
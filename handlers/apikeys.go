@@ -0,0 +1,144 @@
+package handlers
+
+import (
+    "net/http"
+    "strconv"
+    "time"
+    "github.com/gin-gonic/gin"
+    "github.com/maazxenon/task-api/auth"
+    "github.com/maazxenon/task-api/dates"
+    "github.com/maazxenon/task-api/models"
+)
+
+// ListAPIKeysHandler lists the API keys of the authenticated user
+// @Summary List API keys
+// @Description Get the API keys of the authenticated user, newest first, including revoked and expired ones. The keys themselves are never returned after creation.
+// @Tags api-keys
+// @Produce  json
+// @Success 200 {array} models.APIKey
+// @Failure 401 {object} Problem "Unauthorized"
+// @Failure 403 {object} Problem "Missing the admin scope"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /api-keys [get]
+func (h *Handler) ListAPIKeysHandler(c *gin.Context) {
+    keys, err := h.store.APIKeys(c.Request.Context(), *actingUser(c))
+    if err != nil {
+        storeError(c, err, "querying API keys")
+        return
+    }
+
+    for i := range keys {
+        localizeAPIKey(c, &keys[i])
+    }
+    c.JSON(http.StatusOK, keys)
+}
+
+// CreateAPIKeyHandler creates an API key for the authenticated user
+// @Summary Create an API key
+// @Description Create an API key acting on behalf of the authenticated user, limited to the given scopes: read allows GET requests, write allows changes too and admin also allows managing keys and users. The key is only returned in this response, so store it right away.
+// @Tags api-keys
+// @Accept  json
+// @Produce  json
+// @Param key body models.APIKey true "API key"
+// @Success 201 {object} models.APIKey
+// @Failure 400 {object} Problem "Bad Request"
+// @Failure 401 {object} Problem "Unauthorized"
+// @Failure 403 {object} Problem "Missing the admin scope"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /api-keys [post]
+func (h *Handler) CreateAPIKeyHandler(c *gin.Context) {
+    var req models.APIKey
+    if err := c.ShouldBindJSON(&req); err != nil {
+        bindError(c, err)
+        return
+    }
+
+    key := models.APIKey{Name: req.Name, Scopes: uniqueScopes(req.Scopes), UserID: *actingUser(c)}
+    if req.ExpiresAt != "" {
+        expiresAt, err := dates.Parse(req.ExpiresAt, clientLocation(c))
+        if err != nil {
+            problem(c, http.StatusBadRequest, "validation_failed", "expires_at must be an RFC 3339 timestamp or a date")
+            return
+        }
+        if !expiresAt.After(time.Now()) {
+            problem(c, http.StatusBadRequest, "validation_failed", "expires_at must be in the future")
+            return
+        }
+        key.ExpiresAt = dates.Format(expiresAt)
+    }
+
+    secret, prefix, hash, err := auth.GenerateAPIKey()
+    if err != nil {
+        storeError(c, err, "generating API key")
+        return
+    }
+    key.Prefix = prefix
+
+    key, err = h.store.CreateAPIKey(c.Request.Context(), key, hash)
+    if err != nil {
+        storeError(c, err, "creating API key")
+        return
+    }
+
+    key.Key = secret
+    localizeAPIKey(c, &key)
+    c.JSON(http.StatusCreated, key)
+}
+
+// RevokeAPIKeyHandler revokes an API key of the authenticated user
+// @Summary Revoke an API key
+// @Description Revoke an API key so it can no longer be used. The key stays listed with its revocation time.
+// @Tags api-keys
+// @Produce  json
+// @Param id path int true "API key ID"
+// @Success 200 {object} models.APIKey
+// @Failure 400 {object} Problem "Bad Request"
+// @Failure 401 {object} Problem "Unauthorized"
+// @Failure 403 {object} Problem "Missing the admin scope"
+// @Failure 404 {object} Problem "Not Found"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /api-keys/{id} [delete]
+func (h *Handler) RevokeAPIKeyHandler(c *gin.Context) {
+    id, err := strconv.Atoi(c.Param("id"))
+    if err != nil {
+        problem(c, http.StatusBadRequest, "invalid_id", "Invalid API key ID")
+        return
+    }
+
+    key, err := h.store.RevokeAPIKey(c.Request.Context(), *actingUser(c), id)
+    if err != nil {
+        storeError(c, err, "revoking API key")
+        return
+    }
+
+    localizeAPIKey(c, &key)
+    c.JSON(http.StatusOK, key)
+}
+
+// uniqueScopes returns scopes without duplicates, keeping their order
+func uniqueScopes(scopes []string) []string {
+    seen := map[string]bool{}
+    unique := []string{}
+    for _, scope := range scopes {
+        if !seen[scope] {
+            seen[scope] = true
+            unique = append(unique, scope)
+        }
+    }
+    return unique
+}
+
+// localizeAPIKey renders the timestamps of key in the client's time zone
+func localizeAPIKey(c *gin.Context, key *models.APIKey) {
+    loc := clientLocation(c)
+    key.CreatedAt = dates.Render(key.CreatedAt, loc)
+    key.LastUsedAt = dates.Render(key.LastUsedAt, loc)
+    key.ExpiresAt = dates.Render(key.ExpiresAt, loc)
+    key.RevokedAt = dates.Render(key.RevokedAt, loc)
+}
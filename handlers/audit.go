@@ -0,0 +1,193 @@
+package handlers
+
+import (
+    "crypto/rand"
+    "encoding/hex"
+    "errors"
+    "fmt"
+    "net/http"
+    "regexp"
+    "strconv"
+    "github.com/gin-gonic/gin"
+    "github.com/maazxenon/task-api/database"
+    "github.com/maazxenon/task-api/dates"
+    "github.com/maazxenon/task-api/models"
+)
+
+// RequestIDHeader carries the ID of a request. A client-supplied ID is kept, otherwise one
+// is generated; either way it is echoed in the response and recorded in the audit log.
+const RequestIDHeader = "X-Request-ID"
+
+// requestIDPattern limits client-supplied request IDs to something safe to log and store
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// auditCursorSort marks cursors issued by GET /audit, so task cursors can't be replayed there
+const auditCursorSort = "audit"
+
+// AuditListResponse is one page of the audit log
+type AuditListResponse struct {
+    Entries []models.AuditEntry `json:"entries"`
+    // NextCursor is passed back as ?cursor= to fetch the next page; it is omitted on the last page
+    NextCursor string `json:"next_cursor,omitempty" example:"eyJzIjoiYXVkaXQiLCJ2IjoiIiwiaWQiOjUwfQ"`
+}
+
+// RequestID assigns every request an ID, taken from the X-Request-ID header if it is usable
+func RequestID() gin.HandlerFunc {
+    return func(c *gin.Context) {
+        id := c.GetHeader(RequestIDHeader)
+        if !requestIDPattern.MatchString(id) {
+            b := make([]byte, 8)
+            rand.Read(b)
+            id = hex.EncodeToString(b)
+        }
+
+        c.Header(RequestIDHeader, id)
+        c.Request = c.Request.WithContext(database.WithRequestID(c.Request.Context(), id))
+        c.Next()
+    }
+}
+
+// TaskHistoryHandler lists the changes made to a task
+// @Summary Get the history of a task
+// @Description Get every change made to a task, oldest first, with who made it, when, in which request and the task before and after. The history of a deleted task stays available.
+// @Tags audit
+// @Produce  json
+// @Param id path int true "Task ID"
+// @Param tz query string false "IANA time zone to render timestamps in; also accepted as the X-Timezone header" default(UTC)
+// @Success 200 {array} models.AuditEntry
+// @Failure 400 {object} Problem "Bad Request"
+// @Failure 401 {object} Problem "Unauthorized"
+// @Failure 404 {object} Problem "Not Found"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /tasks/{id}/history [get]
+func (h *Handler) TaskHistoryHandler(c *gin.Context) {
+    id, err := strconv.Atoi(c.Param("id"))
+    if err != nil {
+        problem(c, http.StatusBadRequest, "invalid_id", "Invalid task ID")
+        return
+    }
+
+    entries, err := h.store.TaskHistory(c.Request.Context(), id)
+    if err != nil {
+        storeError(c, err, "querying task history")
+        return
+    }
+
+    localizeAuditEntries(c, entries)
+    c.JSON(http.StatusOK, entries)
+}
+
+// AuditLogHandler lists the changes made to tasks across the workspace
+// @Summary List the audit log
+// @Description Get a page of the changes made to tasks in the workspace, newest first, optionally filtered. Follow next_cursor or the Link header for the next page. Needs the admin role.
+// @Tags audit
+// @Produce  json
+// @Param task_id query int false "Only changes to this task"
+// @Param actor_id query int false "Only changes made by this user"
+// @Param action query string false "Only changes of this kind" Enums(create, update, delete, restore, purge)
+// @Param request_id query string false "Only changes made by this request"
+// @Param since query string false "Only changes made at or after this RFC 3339 timestamp or date"
+// @Param until query string false "Only changes made at or before this RFC 3339 timestamp or date (inclusive of the whole day)"
+// @Param limit query int false "Page size" minimum(1) maximum(200) default(50)
+// @Param cursor query string false "Cursor returned as next_cursor by the previous page"
+// @Param tz query string false "IANA time zone to render timestamps in and to read date-only values in; also accepted as the X-Timezone header" default(UTC)
+// @Success 200 {object} AuditListResponse
+// @Header 200 {string} Link "Link to the next page (rel=next) when another page follows"
+// @Failure 400 {object} Problem "Bad Request"
+// @Failure 401 {object} Problem "Unauthorized"
+// @Failure 403 {object} Problem "Forbidden"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /audit [get]
+func (h *Handler) AuditLogHandler(c *gin.Context) {
+    filter, err := parseAuditFilter(c)
+    if err != nil {
+        problem(c, http.StatusBadRequest, "invalid_parameter", err.Error())
+        return
+    }
+
+    page, err := h.store.AuditLog(c.Request.Context(), filter)
+    if err != nil {
+        storeError(c, err, "querying audit log")
+        return
+    }
+
+    var next string
+    if page.Next != 0 {
+        next = encodeCursor(cursorToken{Sort: auditCursorSort, Cursor: database.Cursor{ID: page.Next}})
+        setLink(c, next)
+    }
+
+    localizeAuditEntries(c, page.Entries)
+    c.JSON(http.StatusOK, AuditListResponse{Entries: page.Entries, NextCursor: next})
+}
+
+// parseAuditFilter reads the filtering and pagination query parameters of GET /audit
+func parseAuditFilter(c *gin.Context) (database.AuditFilter, error) {
+    filter := database.AuditFilter{
+        Action:    c.Query("action"),
+        RequestID: c.Query("request_id"),
+        Limit:     database.DefaultPageSize,
+    }
+
+    switch filter.Action {
+    case "", database.AuditCreate, database.AuditUpdate, database.AuditDelete, database.AuditRestore, database.AuditPurge:
+    default:
+        return filter, fmt.Errorf("invalid action %q, expected create, update, delete, restore or purge", filter.Action)
+    }
+
+    for name, target := range map[string]**int{"task_id": &filter.TaskID, "actor_id": &filter.ActorID} {
+        if value := c.Query(name); value != "" {
+            id, err := strconv.Atoi(value)
+            if err != nil {
+                return filter, fmt.Errorf("invalid %s %q", name, value)
+            }
+            *target = &id
+        }
+    }
+
+    loc := clientLocation(c)
+    var err error
+    if filter.Since, err = dueBound(c.Query("since"), loc, false); err != nil {
+        return filter, errors.New("since " + err.Error())
+    }
+    if filter.Until, err = dueBound(c.Query("until"), loc, true); err != nil {
+        return filter, errors.New("until " + err.Error())
+    }
+
+    if limit := c.Query("limit"); limit != "" {
+        n, err := strconv.Atoi(limit)
+        if err != nil || n < 1 || n > database.MaxPageSize {
+            return filter, fmt.Errorf("limit must be between 1 and %d", database.MaxPageSize)
+        }
+        filter.Limit = n
+    }
+
+    if cursor := c.Query("cursor"); cursor != "" {
+        token, err := decodeCursor(cursor)
+        if err != nil || token.Sort != auditCursorSort {
+            return filter, errInvalidCursor
+        }
+        filter.Before = token.ID
+    }
+
+    return filter, nil
+}
+
+// localizeAuditEntries renders the timestamps of entries and their snapshots in the
+// client's time zone
+func localizeAuditEntries(c *gin.Context, entries []models.AuditEntry) {
+    loc := clientLocation(c)
+    for i := range entries {
+        entries[i].CreatedAt = dates.Render(entries[i].CreatedAt, loc)
+        if entries[i].Before != nil {
+            localizeTask(c, entries[i].Before)
+        }
+        if entries[i].After != nil {
+            localizeTask(c, entries[i].After)
+        }
+    }
+}
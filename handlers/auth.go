@@ -0,0 +1,282 @@
+package handlers
+
+import (
+    "errors"
+    "log"
+    "net/http"
+    "strings"
+    "time"
+    "github.com/gin-gonic/gin"
+    "github.com/maazxenon/task-api/auth"
+    "github.com/maazxenon/task-api/database"
+    "github.com/maazxenon/task-api/dates"
+)
+
+// APIKeyHeader carries an API key; "Authorization: ApiKey <key>" is accepted too
+const APIKeyHeader = "X-API-Key"
+
+// claimsKey is the gin.Context key holding the *auth.Claims of a request authenticated with a token
+const claimsKey = "claims"
+
+// scopesKey is the gin.Context key holding the scopes granted to the request
+const scopesKey = "scopes"
+
+// LoginRequest holds the credentials exchanged for tokens
+type LoginRequest struct {
+    Email    string `json:"email" example:"ada@example.com" binding:"required"`
+    Password string `json:"password" example:"correct horse battery staple" binding:"required"`
+}
+
+// RefreshRequest holds a refresh token exchanged for new tokens
+type RefreshRequest struct {
+    RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// TokenResponse is returned by login and refresh
+type TokenResponse struct {
+    AccessToken  string `json:"access_token"`
+    RefreshToken string `json:"refresh_token"`
+    TokenType    string `json:"token_type" example:"Bearer"`
+    // ExpiresIn is the lifetime of the access token in seconds
+    ExpiresIn int `json:"expires_in" example:"900"`
+}
+
+// HealthResponse reports whether the service can reach its database
+type HealthResponse struct {
+    Status string `json:"status" example:"ok"`
+}
+
+// Authenticate rejects requests without a valid access token or API key and makes the
+// caller and their role available to the handlers. The request is scoped to the workspace
+// of the credentials. API keys are limited to their scopes:
+// reads need the read scope and anything else the write scope.
+func (h *Handler) Authenticate() gin.HandlerFunc {
+    return func(c *gin.Context) {
+        scheme, credential, _ := strings.Cut(c.GetHeader("Authorization"), " ")
+        credential = strings.TrimSpace(credential)
+        if key := c.GetHeader(APIKeyHeader); key != "" {
+            scheme, credential = "ApiKey", key
+        }
+
+        switch {
+        case strings.EqualFold(scheme, "ApiKey") && credential != "":
+            if !h.authenticateAPIKey(c, credential) {
+                return
+            }
+        case strings.EqualFold(scheme, "Bearer") && credential != "":
+            claims, err := h.tokens.Verify(credential, auth.AccessToken)
+            if err != nil {
+                unauthorized(c, "Invalid or expired token")
+                return
+            }
+            if !claimWorkspace(c, claims.WorkspaceID) {
+                return
+            }
+            c.Set(claimsKey, claims)
+            c.Set(userKey, claims.UserID)
+            c.Set(scopesKey, auth.AllScopes)
+            if !h.loadRole(c, claims.UserID) {
+                return
+            }
+        default:
+            unauthorized(c, "Missing bearer token or API key")
+            return
+        }
+
+        scope := auth.ScopeWrite
+        switch c.Request.Method {
+        case http.MethodGet, http.MethodHead, http.MethodOptions:
+            scope = auth.ScopeRead
+        }
+        if !hasScope(c, scope) {
+            forbidden(c, "API key lacks the "+scope+" scope")
+            return
+        }
+
+        c.Request = c.Request.WithContext(database.WithActor(c.Request.Context(), *actingUser(c)))
+        c.Next()
+    }
+}
+
+// authenticateAPIKey identifies the caller from an API key, answering the request if it
+// isn't usable
+func (h *Handler) authenticateAPIKey(c *gin.Context, secret string) bool {
+    key, err := h.store.APIKeyByHash(c.Request.Context(), auth.HashAPIKey(secret))
+    if errors.Is(err, database.ErrAPIKeyNotFound) {
+        unauthorized(c, "Invalid API key")
+        return false
+    }
+    if err != nil {
+        storeError(c, err, "querying API key")
+        c.Abort()
+        return false
+    }
+
+    now := time.Now()
+    if key.RevokedAt != "" {
+        unauthorized(c, "API key has been revoked")
+        return false
+    }
+    if expiresAt, err := dates.Parse(key.ExpiresAt, time.UTC); err == nil && !now.Before(expiresAt) {
+        unauthorized(c, "API key has expired")
+        return false
+    }
+
+    if !claimWorkspace(c, key.WorkspaceID) {
+        return false
+    }
+    if err := h.store.TouchAPIKey(c.Request.Context(), key.ID, now); err != nil {
+        log.Printf("Error recording API key use: %v", err)
+    }
+    c.Set(userKey, key.UserID)
+    c.Set(scopesKey, key.Scopes)
+    return h.loadRole(c, key.UserID)
+}
+
+// RequireScope rejects authenticated requests whose credentials lack scope
+func RequireScope(scope string) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        if !hasScope(c, scope) {
+            forbidden(c, "API key lacks the "+scope+" scope")
+            return
+        }
+        c.Next()
+    }
+}
+
+// hasScope reports whether the credentials of the request grant scope
+func hasScope(c *gin.Context, scope string) bool {
+    scopes, _ := c.Get(scopesKey)
+    granted, _ := scopes.([]string)
+    return auth.HasScope(granted, scope)
+}
+
+// unauthorized aborts the request with a 401 and a bearer challenge
+func unauthorized(c *gin.Context, message string) {
+    c.Header("WWW-Authenticate", `Bearer realm="task-api"`)
+    abortProblem(c, http.StatusUnauthorized, "unauthorized", message)
+}
+
+// forbidden aborts the request with a 403
+func forbidden(c *gin.Context, message string) {
+    abortProblem(c, http.StatusForbidden, "forbidden", message)
+}
+
+// tokenClaims returns the claims of the authenticated request, or nil
+func tokenClaims(c *gin.Context) *auth.Claims {
+    claims, ok := c.Get(claimsKey)
+    if !ok {
+        return nil
+    }
+    return claims.(*auth.Claims)
+}
+
+// LoginHandler exchanges an email and password for tokens
+// @Summary Log in
+// @Description Exchange an email and password for a short-lived access token and a long-lived refresh token. Emails are unique per workspace, so name the workspace with the X-Workspace header or a subdomain; without one the default workspace is used if it exists. The tokens only give access to that workspace.
+// @Tags auth
+// @Accept  json
+// @Produce  json
+// @Param credentials body LoginRequest true "Credentials"
+// @Param X-Workspace header string false "Workspace slug"
+// @Success 200 {object} TokenResponse
+// @Failure 400 {object} Problem "Bad Request"
+// @Failure 401 {object} Problem "Invalid email or password"
+// @Failure 404 {object} Problem "Workspace not found"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Router /auth/login [post]
+func (h *Handler) LoginHandler(c *gin.Context) {
+    var req LoginRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        bindError(c, err)
+        return
+    }
+    if !h.loginWorkspace(c) {
+        return
+    }
+
+    user, err := h.store.UserByEmail(c.Request.Context(), req.Email)
+    if err != nil && !errors.Is(err, database.ErrUserNotFound) {
+        storeError(c, err, "querying user")
+        return
+    }
+    if err := auth.CheckPassword(user.PasswordHash, req.Password); err != nil {
+        problem(c, http.StatusUnauthorized, "unauthorized", err.Error())
+        return
+    }
+
+    h.issueTokens(c, user.ID, user.Email)
+}
+
+// RefreshHandler exchanges a refresh token for new tokens
+// @Summary Refresh tokens
+// @Description Exchange a valid refresh token for a new access and refresh token
+// @Tags auth
+// @Accept  json
+// @Produce  json
+// @Param token body RefreshRequest true "Refresh token"
+// @Success 200 {object} TokenResponse
+// @Failure 400 {object} Problem "Bad Request"
+// @Failure 401 {object} Problem "Invalid or expired refresh token"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Router /auth/refresh [post]
+func (h *Handler) RefreshHandler(c *gin.Context) {
+    var req RefreshRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        bindError(c, err)
+        return
+    }
+
+    claims, err := h.tokens.Verify(req.RefreshToken, auth.RefreshToken)
+    if err != nil {
+        problem(c, http.StatusUnauthorized, "unauthorized", "Invalid or expired refresh token")
+        return
+    }
+
+    // Users deleted since the token was issued can't refresh it
+    setWorkspace(c, claims.WorkspaceID)
+    user, err := h.store.GetUser(c.Request.Context(), claims.UserID)
+    if errors.Is(err, database.ErrUserNotFound) {
+        problem(c, http.StatusUnauthorized, "unauthorized", "Invalid or expired refresh token")
+        return
+    }
+    if err != nil {
+        storeError(c, err, "querying user")
+        return
+    }
+
+    h.issueTokens(c, user.ID, user.Email)
+}
+
+func (h *Handler) issueTokens(c *gin.Context, userID int, email string) {
+    pair, err := h.tokens.Issue(userID, database.WorkspaceID(c.Request.Context()), email)
+    if err != nil {
+        storeError(c, err, "issuing tokens")
+        return
+    }
+
+    c.JSON(http.StatusOK, TokenResponse{
+        AccessToken:  pair.AccessToken,
+        RefreshToken: pair.RefreshToken,
+        TokenType:    "Bearer",
+        ExpiresIn:    int(pair.ExpiresIn.Seconds()),
+    })
+}
+
+// HealthHandler reports whether the service is up
+// @Summary Health check
+// @Description Check that the service is running and can reach its database. Doesn't require authentication.
+// @Tags health
+// @Produce  json
+// @Success 200 {object} HealthResponse
+// @Failure 503 {object} Problem "Database unreachable"
+// @Router /health [get]
+func (h *Handler) HealthHandler(c *gin.Context) {
+    if err := h.store.Ping(c.Request.Context()); err != nil {
+        log.Printf("Health check failed: %v", err)
+        problem(c, http.StatusServiceUnavailable, "unavailable", "Database unreachable")
+        return
+    }
+
+    c.JSON(http.StatusOK, HealthResponse{Status: "ok"})
+}
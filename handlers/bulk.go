@@ -0,0 +1,224 @@
+package handlers
+
+import (
+    "bytes"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "io"
+    "net/http"
+    "strconv"
+    "github.com/gin-gonic/gin"
+    "github.com/maazxenon/task-api/auth"
+    "github.com/maazxenon/task-api/database"
+    "github.com/maazxenon/task-api/models"
+)
+
+// Bulk modes
+const (
+    // bulkAtomic applies every operation or, as soon as one fails, none of them
+    bulkAtomic = "atomic"
+    // bulkPartial applies the operations that succeed and reports the ones that fail
+    bulkPartial = "partial"
+)
+
+// errBulkOperationFailed rolls back the transaction or savepoint of a failed operation
+var errBulkOperationFailed = errors.New("bulk operation failed")
+
+// BulkRequest holds a batch of task operations
+type BulkRequest struct {
+    // Mode is atomic (the default) to apply all operations or none, or partial to apply
+    // those that succeed
+    Mode       string          `json:"mode" example:"atomic" enums:"atomic,partial" binding:"omitempty,oneof=atomic partial"`
+    Operations []BulkOperation `json:"operations" binding:"required,min=1,max=200,dive"`
+}
+
+// BulkOperation is one create, update, patch or delete of a bulk request. Each behaves
+// like the corresponding single-task request.
+type BulkOperation struct {
+    Op string `json:"op" example:"update" enums:"create,update,patch,delete" binding:"required,oneof=create update patch delete"`
+    // ID is the task to update, patch or delete
+    ID int `json:"id" example:"1"`
+    // Body is the task to create or update, or the patch document
+    Body json.RawMessage `json:"body,omitempty" swaggertype:"object"`
+    // ContentType is the media type of a patch; merge patch by default
+    ContentType string `json:"content_type,omitempty" example:"application/merge-patch+json" enums:"application/merge-patch+json,application/json-patch+json"`
+    // IfMatch is the ETag the task must still have, like the If-Match header
+    IfMatch string `json:"if_match,omitempty" example:"\"3\""`
+    // Children is what happens to the subtasks of a deleted task, like ?children=
+    Children string `json:"children,omitempty" example:"orphan" enums:"orphan,cascade"`
+}
+
+// BulkResult is the outcome of one operation of a bulk request
+type BulkResult struct {
+    // Index is the position of the operation in the request
+    Index  int    `json:"index" example:"0"`
+    Op     string `json:"op" example:"update"`
+    // Status is the HTTP status the operation would have been answered with on its own
+    Status int    `json:"status" example:"200"`
+    // Task is the created or changed task
+    Task   *models.Task `json:"task,omitempty"`
+    // Error is the problem the operation failed with
+    Error  *Problem     `json:"error,omitempty"`
+}
+
+// BulkResponse reports the outcome of a bulk request
+type BulkResponse struct {
+    // Committed tells whether the successful operations were saved; an atomic request that
+    // failed saves nothing and only reports the operation that failed
+    Committed bool         `json:"committed" example:"true"`
+    Results   []BulkResult `json:"results"`
+}
+
+// BulkHandler applies a batch of task operations in one transaction
+// @Summary Apply task operations in bulk
+// @Description Create, update, patch and delete up to 200 tasks in one request and one database transaction. Each operation is validated and authorized exactly like the single-task request it stands for. In atomic mode (the default) the first failing operation rolls everything back and is reported with its status as the status of the response. In partial mode every operation runs in its own savepoint: failures are undone individually, the rest is committed and the response lists the outcome of each operation.
+// @Tags tasks
+// @Accept  json
+// @Produce  json
+// @Param request body BulkRequest true "Operations"
+// @Param tz query string false "IANA time zone to render due dates in and to read date-only values in; also accepted as the X-Timezone header" default(UTC)
+// @Param ref query string false "RFC 3339 time natural-language due dates are relative to; also accepted as the X-Reference-Time header (default: now)"
+// @Success 200 {object} BulkResponse
+// @Failure 400 {object} Problem "Bad Request; in atomic mode also an operation that failed validation, reported as BulkResponse"
+// @Failure 403 {object} BulkResponse "An operation the caller's role doesn't allow (atomic mode)"
+// @Failure 404 {object} BulkResponse "An operation on a task that doesn't exist (atomic mode)"
+// @Failure 409 {object} BulkResponse "An operation that conflicts with the stored tasks (atomic mode)"
+// @Failure 412 {object} BulkResponse "An operation whose if_match no longer holds (atomic mode)"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /tasks/bulk [post]
+func (h *Handler) BulkHandler(c *gin.Context) {
+    var req BulkRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        bindError(c, err)
+        return
+    }
+    for i, op := range req.Operations {
+        if err := checkBulkOperation(op); err != nil {
+            problem(c, http.StatusBadRequest, "invalid_request", fmt.Sprintf("operations[%d]: %v", i, err))
+            return
+        }
+    }
+
+    if req.Mode == "" {
+        req.Mode = bulkAtomic
+    }
+
+    results := make([]BulkResult, 0, len(req.Operations))
+    var failed *BulkResult
+    err := h.store.InTx(c.Request.Context(), func(store database.Store) error {
+        tx := *h
+        tx.store = store
+        for i, op := range req.Operations {
+            if req.Mode == bulkAtomic {
+                result := tx.runBulkOperation(c, i, op)
+                if result.Status >= http.StatusBadRequest {
+                    failed = &result
+                    return errBulkOperationFailed
+                }
+                results = append(results, result)
+                continue
+            }
+
+            var result BulkResult
+            err := store.Savepoint(c.Request.Context(), func() error {
+                result = tx.runBulkOperation(c, i, op)
+                if result.Status >= http.StatusBadRequest {
+                    return errBulkOperationFailed
+                }
+                return nil
+            })
+            if err != nil && !errors.Is(err, errBulkOperationFailed) {
+                return err
+            }
+            results = append(results, result)
+        }
+        return nil
+    })
+    if failed != nil {
+        c.JSON(failed.Status, BulkResponse{Results: []BulkResult{*failed}})
+        return
+    }
+    if err != nil {
+        storeError(c, err, "applying bulk operations")
+        return
+    }
+
+    c.JSON(http.StatusOK, BulkResponse{Committed: true, Results: results})
+}
+
+// checkBulkOperation rejects operations missing what their kind needs
+func checkBulkOperation(op BulkOperation) error {
+    if op.Op != "create" && op.ID <= 0 {
+        return fmt.Errorf("id is required to %s a task", op.Op)
+    }
+    if op.Op != "delete" && len(op.Body) == 0 {
+        return fmt.Errorf("body is required to %s a task", op.Op)
+    }
+    return nil
+}
+
+// runBulkOperation runs one operation through the handler of the equivalent single-task
+// request, with the credentials, workspace and query parameters of the bulk request
+func (h *Handler) runBulkOperation(c *gin.Context, index int, op BulkOperation) BulkResult {
+    req := c.Request.Clone(c.Request.Context())
+    req.Header.Set("Content-Type", "application/json")
+    req.Header.Del("If-Match")
+    req.Header.Del(IdempotencyKeyHeader)
+    if op.IfMatch != "" {
+        req.Header.Set("If-Match", op.IfMatch)
+    }
+    req.Body = io.NopCloser(bytes.NewReader(op.Body))
+    req.ContentLength = int64(len(op.Body))
+
+    var chain []gin.HandlerFunc
+    switch op.Op {
+    case "create":
+        chain = []gin.HandlerFunc{Require(auth.CreateTasks), h.CreateHandler}
+    case "update":
+        chain = []gin.HandlerFunc{h.UpdateTaskHandler}
+    case "patch":
+        contentType := op.ContentType
+        if contentType == "" {
+            contentType = mergePatchType
+        }
+        req.Header.Set("Content-Type", contentType)
+        chain = []gin.HandlerFunc{h.PatchTaskHandler}
+    case "delete":
+        query := req.URL.Query()
+        query.Del("children")
+        if op.Children != "" {
+            query.Set("children", op.Children)
+        }
+        req.URL.RawQuery = query.Encode()
+        chain = []gin.HandlerFunc{h.DeleteHandler}
+    }
+
+    rec := newResponseRecorder()
+    sub := c.Copy()
+    sub.Request = req
+    sub.Writer = rec
+    sub.Params = gin.Params{{Key: "id", Value: strconv.Itoa(op.ID)}}
+    for _, handler := range chain {
+        if rec.Written() {
+            break
+        }
+        handler(sub)
+    }
+
+    result := BulkResult{Index: index, Op: op.Op, Status: rec.Status()}
+    if result.Status >= http.StatusBadRequest {
+        var p Problem
+        if err := json.Unmarshal(rec.body.Bytes(), &p); err == nil {
+            result.Error = &p
+        }
+    } else if op.Op != "delete" {
+        var task models.Task
+        if err := json.Unmarshal(rec.body.Bytes(), &task); err == nil {
+            result.Task = &task
+        }
+    }
+    return result
+}
@@ -0,0 +1,104 @@
+package handlers
+
+import (
+    "net/http"
+    "time"
+    "github.com/gin-gonic/gin"
+    "github.com/go-playground/validator/v10"
+    "github.com/maazxenon/task-api/dates"
+    "github.com/maazxenon/task-api/models"
+)
+
+// TimezoneHeader names the client's IANA time zone; the tz query parameter takes precedence
+const TimezoneHeader = "X-Timezone"
+
+// Timezone reads the client's time zone from the tz query parameter or the X-Timezone
+// header and stores it on the request context. Due dates are rendered in that zone and
+// date-only values sent by the client are taken as midnight there. It defaults to UTC.
+func Timezone() gin.HandlerFunc {
+    return func(c *gin.Context) {
+        name := c.Query("tz")
+        if name == "" {
+            name = c.GetHeader(TimezoneHeader)
+        }
+        if name == "" {
+            c.Next()
+            return
+        }
+
+        loc, err := time.LoadLocation(name)
+        if err != nil {
+            abortProblem(c, http.StatusBadRequest, "invalid_parameter", "Unknown time zone "+name)
+            return
+        }
+
+        c.Request = c.Request.WithContext(dates.WithLocation(c.Request.Context(), loc))
+        c.Next()
+    }
+}
+
+// clientLocation returns the time zone chosen by the Timezone middleware
+func clientLocation(c *gin.Context) *time.Location {
+    return dates.Location(c.Request.Context())
+}
+
+// validateDueDate is a custom validation function for the due_date field
+func validateDueDate(fl validator.FieldLevel) bool {
+    return dates.Valid(fl.Field().String())
+}
+
+// ReferenceTimeHeader carries the RFC 3339 time natural-language due dates are relative to;
+// the ref query parameter takes precedence and both default to the current time
+const ReferenceTimeHeader = "X-Reference-Time"
+
+// resolveDueDate converts the due date of task to UTC for storage. Natural-language
+// phrases such as "next friday 5pm" are resolved against the reference time in the
+// client's time zone and kept in DueDatePhrase. Values that can't be read at all are
+// left for validateTask to reject. It reports false after answering a bad reference time.
+func resolveDueDate(c *gin.Context, task *models.Task) bool {
+    task.DueDatePhrase = ""
+    if task.DueDate == "" {
+        return true
+    }
+
+    loc := clientLocation(c)
+    if due, err := dates.Normalize(task.DueDate, loc); err == nil {
+        task.DueDate = due
+        return true
+    }
+
+    ref := time.Now()
+    value := c.Query("ref")
+    if value == "" {
+        value = c.GetHeader(ReferenceTimeHeader)
+    }
+    if value != "" {
+        var err error
+        if ref, err = time.Parse(time.RFC3339, value); err != nil {
+            problem(c, http.StatusBadRequest, "invalid_parameter", "Reference time must be an RFC 3339 timestamp")
+            return false
+        }
+    }
+
+    if due, err := dates.ParseNatural(task.DueDate, ref.In(loc)); err == nil {
+        task.DueDatePhrase = task.DueDate
+        task.DueDate = dates.Format(due)
+    }
+    return true
+}
+
+// localizeTasks renders the due dates and deletion times of tasks in the client's time zone
+func localizeTasks(c *gin.Context, tasks []models.Task) {
+    loc := clientLocation(c)
+    for i := range tasks {
+        tasks[i].DueDate = dates.Render(tasks[i].DueDate, loc)
+        tasks[i].DeletedAt = dates.Render(tasks[i].DeletedAt, loc)
+    }
+}
+
+// localizeTask renders the due date and deletion time of task in the client's time zone
+func localizeTask(c *gin.Context, task *models.Task) {
+    loc := clientLocation(c)
+    task.DueDate = dates.Render(task.DueDate, loc)
+    task.DeletedAt = dates.Render(task.DeletedAt, loc)
+}
@@ -0,0 +1,258 @@
+package handlers
+
+import (
+    "container/heap"
+    "context"
+    "errors"
+    "net/http"
+    "sort"
+    "strconv"
+    "github.com/gin-gonic/gin"
+    "github.com/go-playground/validator/v10"
+    "github.com/maazxenon/task-api/database"
+    "github.com/maazxenon/task-api/models"
+)
+
+// DependencyRequest names the task that blocks another one
+type DependencyRequest struct {
+    BlockedBy int `json:"blocked_by" example:"1" binding:"required"`
+}
+
+// openBlockersKey carries the IDs of a task's incomplete blockers to validateUnblocked
+type openBlockersKey struct{}
+
+// validateUnblocked rejects the completed status while the task has incomplete blockers
+func validateUnblocked(ctx context.Context, fl validator.FieldLevel) bool {
+    if fl.Field().String() != "completed" {
+        return true
+    }
+    open, _ := ctx.Value(openBlockersKey{}).([]int)
+    return len(open) == 0
+}
+
+// validateTask runs the struct validations on task. When an existing task is being
+// completed it first looks up its blockers so the unblocked rule can check them.
+func (h *Handler) validateTask(ctx context.Context, task models.Task) error {
+    if task.ID != 0 && task.Status == "completed" {
+        blockers, err := h.store.Blockers(ctx, task.ID)
+        if err != nil && !errors.Is(err, database.ErrTaskNotFound) {
+            return err
+        }
+
+        var open []int
+        for _, blocker := range blockers {
+            if blocker.Status != "completed" {
+                open = append(open, blocker.ID)
+            }
+        }
+        ctx = context.WithValue(ctx, openBlockersKey{}, open)
+    }
+
+    return validate.StructCtx(ctx, task)
+}
+
+// DependenciesHandler lists the tasks blocking a task
+// @Summary List blocking tasks
+// @Description Get the tasks that must be completed before this task can be completed
+// @Tags dependencies
+// @Produce  json
+// @Param id path int true "Task ID"
+// @Success 200 {array} models.Task
+// @Failure 400 {object} Problem "Bad Request"
+// @Failure 404 {object} Problem "Not Found"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /tasks/{id}/dependencies [get]
+func (h *Handler) DependenciesHandler(c *gin.Context) {
+    id, err := strconv.Atoi(c.Param("id"))
+    if err != nil {
+        problem(c, http.StatusBadRequest, "invalid_id", "Invalid task ID")
+        return
+    }
+
+    blockers, err := h.store.Blockers(c.Request.Context(), id)
+    if err != nil {
+        storeError(c, err, "querying dependencies")
+        return
+    }
+
+    localizeTasks(c, blockers)
+    c.JSON(http.StatusOK, blockers)
+}
+
+// AddDependencyHandler marks a task as blocked by another task
+// @Summary Add a dependency
+// @Description Mark the task as blocked by another task. Dependencies that would form a cycle are rejected.
+// @Tags dependencies
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Task ID"
+// @Param dependency body DependencyRequest true "Blocking task"
+// @Success 200 {object} models.Dependency
+// @Failure 400 {object} Problem "Bad Request"
+// @Failure 403 {object} Problem "Your role doesn't allow updating this task"
+// @Failure 404 {object} Problem "Not Found"
+// @Failure 409 {object} Problem "The dependency would create a cycle"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /tasks/{id}/dependencies [post]
+func (h *Handler) AddDependencyHandler(c *gin.Context) {
+    id, err := strconv.Atoi(c.Param("id"))
+    if err != nil {
+        problem(c, http.StatusBadRequest, "invalid_id", "Invalid task ID")
+        return
+    }
+
+    if !h.authorizeTask(c, id, updateTask) {
+        return
+    }
+
+    var req DependencyRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        bindError(c, err)
+        return
+    }
+
+    if err := h.store.AddDependency(c.Request.Context(), id, req.BlockedBy); err != nil {
+        storeError(c, err, "adding dependency")
+        return
+    }
+
+    c.JSON(http.StatusOK, models.Dependency{TaskID: id, BlockedByID: req.BlockedBy})
+}
+
+// RemoveDependencyHandler removes a dependency between two tasks
+// @Summary Remove a dependency
+// @Description Stop the task from being blocked by another task
+// @Tags dependencies
+// @Produce  json
+// @Param id path int true "Task ID"
+// @Param blocker_id path int true "Blocking task ID"
+// @Success 200 {object} map[string]string "message: Dependency removed"
+// @Failure 400 {object} Problem "Bad Request"
+// @Failure 403 {object} Problem "Your role doesn't allow updating this task"
+// @Failure 404 {object} Problem "Not Found"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /tasks/{id}/dependencies/{blocker_id} [delete]
+func (h *Handler) RemoveDependencyHandler(c *gin.Context) {
+    id, err := strconv.Atoi(c.Param("id"))
+    if err != nil {
+        problem(c, http.StatusBadRequest, "invalid_id", "Invalid task ID")
+        return
+    }
+    blockerID, err := strconv.Atoi(c.Param("blocker_id"))
+    if err != nil {
+        problem(c, http.StatusBadRequest, "invalid_id", "Invalid blocking task ID")
+        return
+    }
+
+    if !h.authorizeTask(c, id, updateTask) {
+        return
+    }
+
+    if err := h.store.RemoveDependency(c.Request.Context(), id, blockerID); err != nil {
+        storeError(c, err, "removing dependency")
+        return
+    }
+
+    c.JSON(http.StatusOK, map[string]string{"message": "Dependency removed"})
+}
+
+// PlanHandler orders tasks so that every task comes after the tasks blocking it
+// @Summary Plan tasks in dependency order
+// @Description Get tasks in topological order: every task is listed after all of its blockers, ties broken by ID. Completed tasks are left out unless include_completed is set.
+// @Tags dependencies
+// @Produce  json
+// @Param include_completed query bool false "Include completed tasks" default(false)
+// @Success 200 {array} models.PlannedTask
+// @Failure 400 {object} Problem "Bad Request"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /tasks/plan [get]
+func (h *Handler) PlanHandler(c *gin.Context) {
+    includeCompleted, err := strconv.ParseBool(c.DefaultQuery("include_completed", "false"))
+    if err != nil {
+        problem(c, http.StatusBadRequest, "invalid_parameter", "include_completed must be a boolean")
+        return
+    }
+
+    tasks, edges, err := h.store.DependencyGraph(c.Request.Context(), includeCompleted)
+    if err != nil {
+        storeError(c, err, "querying dependency graph")
+        return
+    }
+
+    localizeTasks(c, tasks)
+    c.JSON(http.StatusOK, topoSort(tasks, edges))
+}
+
+// topoSort orders tasks with Kahn's algorithm, always picking the lowest ready ID so the
+// result is deterministic. Edges to tasks outside the list are ignored.
+func topoSort(tasks []models.Task, edges []models.Dependency) []models.PlannedTask {
+    byID := make(map[int]models.Task, len(tasks))
+    for _, task := range tasks {
+        byID[task.ID] = task
+    }
+
+    blockedBy := map[int][]int{}
+    unblocks := map[int][]int{}
+    pending := map[int]int{}
+    for _, edge := range edges {
+        if _, ok := byID[edge.TaskID]; !ok {
+            continue
+        }
+        if _, ok := byID[edge.BlockedByID]; !ok {
+            continue
+        }
+        blockedBy[edge.TaskID] = append(blockedBy[edge.TaskID], edge.BlockedByID)
+        unblocks[edge.BlockedByID] = append(unblocks[edge.BlockedByID], edge.TaskID)
+        pending[edge.TaskID]++
+    }
+
+    ready := &idHeap{}
+    for _, task := range tasks {
+        if pending[task.ID] == 0 {
+            heap.Push(ready, task.ID)
+        }
+    }
+
+    plan := make([]models.PlannedTask, 0, len(tasks))
+    for ready.Len() > 0 {
+        id := heap.Pop(ready).(int)
+
+        blockers := blockedBy[id]
+        if blockers == nil {
+            blockers = []int{}
+        }
+        sort.Ints(blockers)
+        plan = append(plan, models.PlannedTask{Task: byID[id], BlockedBy: blockers})
+
+        for _, next := range unblocks[id] {
+            pending[next]--
+            if pending[next] == 0 {
+                heap.Push(ready, next)
+            }
+        }
+    }
+
+    return plan
+}
+
+// idHeap is a min-heap of task IDs
+type idHeap []int
+
+func (h idHeap) Len() int           { return len(h) }
+func (h idHeap) Less(i, j int) bool { return h[i] < h[j] }
+func (h idHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *idHeap) Push(x any)        { *h = append(*h, x.(int)) }
+func (h *idHeap) Pop() any {
+    old := *h
+    x := old[len(old)-1]
+    *h = old[:len(old)-1]
+    return x
+}
@@ -0,0 +1,300 @@
+package handlers
+
+import (
+    "encoding/json"
+    "errors"
+    "fmt"
+    "log"
+    "net/http"
+    "reflect"
+    "strings"
+    "github.com/gin-gonic/gin"
+    "github.com/go-playground/validator/v10"
+    "github.com/maazxenon/task-api/database"
+    "github.com/maazxenon/task-api/recurrence"
+)
+
+// ProblemContentType is the media type of error responses
+const ProblemContentType = "application/problem+json"
+
+// Problem is an RFC 7807 problem details error response
+type Problem struct {
+    // Type identifies the kind of problem: /problems/ followed by the code
+    Type  string `json:"type" example:"/problems/task_not_found"`
+    Title string `json:"title" example:"Task not found"`
+    // Status repeats the HTTP status code
+    Status int `json:"status" example:"404"`
+    // Detail explains this occurrence of the problem
+    Detail string `json:"detail,omitempty" example:"Task 42 not found"`
+    // Instance is the path of the request that failed
+    Instance string `json:"instance" example:"/tasks/42"`
+    // Code is a stable machine-readable name of the problem
+    Code string `json:"code" example:"task_not_found"`
+    // RequestID is the X-Request-ID of the request, to find it in the logs
+    RequestID string `json:"request_id,omitempty" example:"9f86d081884c7d65"`
+    // Errors lists the invalid fields of a validation_failed problem
+    Errors []FieldError `json:"errors,omitempty"`
+}
+
+// FieldError describes one invalid field of a request
+type FieldError struct {
+    // Field is the JSON path of the field, e.g. status or operations[2].op
+    Field string `json:"field" example:"status"`
+    // Rule is the validation rule the value broke
+    Rule    string `json:"rule" example:"status"`
+    Message string `json:"message" example:"must be pending, in progress or completed"`
+}
+
+// problemTitles are the titles of the problem codes; codes without one use the status text
+var problemTitles = map[string]string{
+    "invalid_request":             "Invalid request",
+    "invalid_id":                  "Invalid ID",
+    "invalid_parameter":           "Invalid query parameter",
+    "invalid_header":              "Invalid header",
+    "malformed_body":              "Malformed request body",
+    "validation_failed":           "Validation failed",
+    "unauthorized":                "Authentication required",
+    "forbidden":                   "Not allowed",
+    "invalid_current_password":    "Current password required",
+    "route_not_found":             "No such endpoint",
+    "task_not_found":              "Task not found",
+    "parent_not_found":            "Parent task not found",
+    "blocker_not_found":           "Blocking task not found",
+    "dependency_not_found":        "Dependency not found",
+    "tag_not_found":               "Tag not found",
+    "project_not_found":           "Project not found",
+    "user_not_found":              "User not found",
+    "api_key_not_found":           "API key not found",
+    "workspace_not_found":         "Workspace not found",
+    "version_conflict":            "Task has changed",
+    "hierarchy_cycle":             "Hierarchy cycle",
+    "dependency_cycle":            "Dependency cycle",
+    "tag_exists":                  "Tag already exists",
+    "project_archived":            "Project is archived",
+    "email_taken":                 "Email already in use",
+    "last_admin":                  "Last admin",
+    "workspace_exists":            "Workspace already exists",
+    "quota_exceeded":              "Quota exceeded",
+    "signup_disabled":             "Sign-up disabled",
+    "rate_limited":                "Too many requests",
+    "unsupported_media_type":      "Unsupported media type",
+    "patch_failed":                "Patch could not be applied",
+    "patch_test_failed":           "Patch test failed",
+    "idempotency_key_reused":      "Idempotency key reused",
+    "idempotency_key_in_progress": "Request in progress",
+    "unavailable":                 "Service unavailable",
+    "internal_error":              "Internal server error",
+}
+
+// newProblem returns the problem details of a failed request
+func newProblem(c *gin.Context, status int, code, detail string) Problem {
+    title, ok := problemTitles[code]
+    if !ok {
+        title = http.StatusText(status)
+    }
+    return Problem{
+        Type:      "/problems/" + code,
+        Title:     title,
+        Status:    status,
+        Detail:    detail,
+        Instance:  c.Request.URL.Path,
+        Code:      code,
+        RequestID: database.RequestID(c.Request.Context()),
+    }
+}
+
+// problem answers the request with an application/problem+json body
+func problem(c *gin.Context, status int, code, detail string) {
+    writeProblem(c, newProblem(c, status, code, detail))
+}
+
+// abortProblem answers like problem and stops the handler chain
+func abortProblem(c *gin.Context, status int, code, detail string) {
+    problem(c, status, code, detail)
+    c.Abort()
+}
+
+// writeProblem sends p with its status
+func writeProblem(c *gin.Context, p Problem) {
+    c.Header("Content-Type", ProblemContentType)
+    c.JSON(p.Status, p)
+}
+
+// storeError answers a failed TaskStore call. Anything unexpected is logged and answered
+// with a 500 that doesn't reveal the underlying error.
+func storeError(c *gin.Context, err error, action string) {
+    switch {
+    case errors.Is(err, database.ErrTaskNotFound):
+        problem(c, http.StatusNotFound, "task_not_found", "Task not found")
+    case errors.Is(err, database.ErrVersionConflict):
+        problem(c, http.StatusPreconditionFailed, "version_conflict", "Task has been modified since it was fetched")
+    case errors.Is(err, database.ErrParentNotFound):
+        problem(c, http.StatusBadRequest, "parent_not_found", "Parent task not found")
+    case errors.Is(err, database.ErrHierarchyCycle):
+        problem(c, http.StatusConflict, "hierarchy_cycle", err.Error())
+    case errors.Is(err, database.ErrDependencyCycle):
+        problem(c, http.StatusConflict, "dependency_cycle", err.Error())
+    case errors.Is(err, database.ErrBlockerNotFound):
+        problem(c, http.StatusNotFound, "blocker_not_found", "Blocking task not found")
+    case errors.Is(err, database.ErrDependencyNotFound):
+        problem(c, http.StatusNotFound, "dependency_not_found", "Dependency not found")
+    case errors.Is(err, database.ErrTagNotFound):
+        problem(c, http.StatusNotFound, "tag_not_found", "Tag not found")
+    case errors.Is(err, database.ErrTagExists):
+        problem(c, http.StatusConflict, "tag_exists", "A tag with this name already exists")
+    case errors.Is(err, database.ErrProjectNotFound):
+        problem(c, http.StatusNotFound, "project_not_found", "Project not found")
+    case errors.Is(err, database.ErrInvalidProject):
+        problem(c, http.StatusBadRequest, "project_not_found", "Project not found")
+    case errors.Is(err, database.ErrProjectArchived):
+        problem(c, http.StatusConflict, "project_archived", "Project is archived")
+    case errors.Is(err, database.ErrUserNotFound):
+        problem(c, http.StatusNotFound, "user_not_found", "User not found")
+    case errors.Is(err, database.ErrInvalidAssignee):
+        problem(c, http.StatusBadRequest, "user_not_found", "Assignee not found")
+    case errors.Is(err, database.ErrEmailTaken):
+        problem(c, http.StatusConflict, "email_taken", "A user with this email already exists")
+    case errors.Is(err, database.ErrLastAdmin):
+        problem(c, http.StatusConflict, "last_admin", "The last admin can't be removed or demoted")
+    case errors.Is(err, database.ErrAPIKeyNotFound):
+        problem(c, http.StatusNotFound, "api_key_not_found", "API key not found")
+    case errors.Is(err, database.ErrWorkspaceNotFound):
+        problem(c, http.StatusNotFound, "workspace_not_found", "Workspace not found")
+    case errors.Is(err, database.ErrWorkspaceExists):
+        problem(c, http.StatusConflict, "workspace_exists", "A workspace with this slug already exists")
+    case errors.Is(err, database.ErrQuotaExceeded):
+        var quota *database.QuotaError
+        errors.As(err, &quota)
+        problem(c, http.StatusForbidden, "quota_exceeded", fmt.Sprintf("The workspace has reached its quota of %d %s", quota.Limit, quota.Resource))
+    case errors.Is(err, recurrence.ErrInvalidDueDate):
+        problem(c, http.StatusBadRequest, "validation_failed", err.Error())
+    default:
+        log.Printf("Error %s in request %s: %v", action, database.RequestID(c.Request.Context()), err)
+        problem(c, http.StatusInternalServerError, "internal_error", "The request could not be completed; quote the request_id when reporting this")
+    }
+}
+
+// bindError answers a failed ShouldBindJSON call
+func bindError(c *gin.Context, err error) {
+    var verrs validator.ValidationErrors
+    if errors.As(err, &verrs) {
+        validationError(c, err)
+        return
+    }
+
+    var typeErr *json.UnmarshalTypeError
+    if errors.As(err, &typeErr) && typeErr.Field != "" {
+        p := newProblem(c, http.StatusBadRequest, "validation_failed", typeErr.Field+" must be a "+jsonKind(typeErr.Type))
+        p.Errors = []FieldError{{Field: typeErr.Field, Rule: "type", Message: "must be a " + jsonKind(typeErr.Type)}}
+        writeProblem(c, p)
+        return
+    }
+
+    problem(c, http.StatusBadRequest, "malformed_body", "The request body is not valid JSON: "+err.Error())
+}
+
+// jsonKind names the JSON type a Go type is decoded from
+func jsonKind(t reflect.Type) string {
+    switch t.Kind() {
+    case reflect.String:
+        return "string"
+    case reflect.Bool:
+        return "boolean"
+    case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+        reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+        return "integer"
+    case reflect.Float32, reflect.Float64:
+        return "number"
+    case reflect.Slice, reflect.Array:
+        return "array"
+    case reflect.Ptr:
+        return jsonKind(t.Elem())
+    }
+    return "object"
+}
+
+// validationError answers a failed validateTask or validate.Struct call, listing every
+// invalid field
+func validationError(c *gin.Context, err error) {
+    var verrs validator.ValidationErrors
+    if !errors.As(err, &verrs) {
+        storeError(c, err, "validating task")
+        return
+    }
+
+    fields := make([]FieldError, len(verrs))
+    for i, verr := range verrs {
+        fields[i] = FieldError{Field: fieldPath(verr), Rule: verr.Tag(), Message: ruleMessage(verr)}
+    }
+
+    detail := fmt.Sprintf("%d fields are invalid", len(fields))
+    if len(fields) == 1 {
+        detail = fields[0].Field + " " + fields[0].Message
+    }
+    p := newProblem(c, http.StatusBadRequest, "validation_failed", detail)
+    p.Errors = fields
+    writeProblem(c, p)
+}
+
+// fieldPath returns the JSON path of an invalid field, without the name of the root struct
+func fieldPath(verr validator.FieldError) string {
+    _, path, ok := strings.Cut(verr.Namespace(), ".")
+    if !ok {
+        return verr.Field()
+    }
+    return path
+}
+
+// ruleMessage explains a broken validation rule
+func ruleMessage(verr validator.FieldError) string {
+    unit := ""
+    switch verr.Kind() {
+    case reflect.String:
+        unit = " characters"
+    case reflect.Slice, reflect.Array, reflect.Map:
+        unit = " items"
+    }
+
+    switch verr.Tag() {
+    case "required":
+        return "is required"
+    case "oneof":
+        return "must be one of " + strings.ReplaceAll(verr.Param(), " ", ", ")
+    case "min":
+        return "must be at least " + verr.Param() + unit
+    case "max":
+        return "must be at most " + verr.Param() + unit
+    case "email":
+        return "must be an email address"
+    case "status":
+        return "must be pending, in progress or completed"
+    case "unblocked":
+        return "can't be completed while the task is blocked by incomplete tasks"
+    case "duedate":
+        return "must be an RFC 3339 timestamp, a date, or a phrase such as \"next friday 5pm\" or \"in 3 days\""
+    case "tagname":
+        return "must be 1 to 50 characters without commas"
+    case "rrule":
+        return "must be a valid RRULE such as FREQ=WEEKLY;BYDAY=MO"
+    }
+    return "breaks the " + verr.Tag() + " rule"
+}
+
+// jsonFieldName names struct fields by their JSON key in validation errors
+func jsonFieldName(field reflect.StructField) string {
+    name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+    if name == "-" {
+        return ""
+    }
+    return name
+}
+
+// Recovered answers requests whose handler panicked
+func Recovered(c *gin.Context, err any) {
+    abortProblem(c, http.StatusInternalServerError, "internal_error", "The request could not be completed; quote the request_id when reporting this")
+}
+
+// RouteNotFound answers requests to paths without an endpoint
+func RouteNotFound(c *gin.Context) {
+    problem(c, http.StatusNotFound, "route_not_found", "No endpoint "+c.Request.Method+" "+c.Request.URL.Path)
+}
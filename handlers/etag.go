@@ -0,0 +1,61 @@
+package handlers
+
+import (
+    "net/http"
+    "strconv"
+    "strings"
+    "github.com/gin-gonic/gin"
+    "github.com/maazxenon/task-api/models"
+)
+
+// etag returns the strong entity tag of a task revision
+func etag(task models.Task) string {
+    return `"` + strconv.Itoa(task.Version) + `"`
+}
+
+// etagMatches reports whether tag is listed in an If-Match or If-None-Match header.
+// Strong comparison (If-Match) never matches weak W/ tags; weak comparison ignores the prefix.
+func etagMatches(header, tag string, weak bool) bool {
+    for _, candidate := range strings.Split(header, ",") {
+        candidate = strings.TrimSpace(candidate)
+        if candidate == "*" {
+            return true
+        }
+        if weak {
+            candidate = strings.TrimPrefix(candidate, "W/")
+        }
+        if candidate == tag {
+            return true
+        }
+    }
+    return false
+}
+
+// checkIfMatch evaluates the If-Match precondition of a write to task id. It returns the
+// version the write must be conditioned on (0 when the request has no If-Match), or
+// ok=false once it has written an error response.
+func (h *Handler) checkIfMatch(c *gin.Context, id int) (version int, ok bool) {
+    header := c.GetHeader("If-Match")
+    if header == "" {
+        return 0, true
+    }
+
+    current, err := h.store.Get(c.Request.Context(), id)
+    if err != nil {
+        storeError(c, err, "querying task")
+        return 0, false
+    }
+
+    if !etagMatches(header, etag(current), false) {
+        preconditionFailed(c, current)
+        return 0, false
+    }
+
+    return current.Version, true
+}
+
+// preconditionFailed answers 412 and tells the client which revision is current
+func preconditionFailed(c *gin.Context, current models.Task) {
+    c.Header("ETag", etag(current))
+    problem(c, http.StatusPreconditionFailed, "version_conflict", "Task has been modified since it was fetched")
+}
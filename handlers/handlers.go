@@ -1,23 +1,32 @@
 package handlers
 
 import (
-    "database/sql"
-    "fmt"
+    "errors"
     "net/http"
     "strconv"
+    "time"
     "github.com/gin-gonic/gin"
+    "github.com/gin-gonic/gin/binding"
     "github.com/go-playground/validator/v10"
     _ "github.com/maazxenon/task-api/docs"
     "github.com/maazxenon/task-api/database"
-    "log"
     "github.com/maazxenon/task-api/models"
+    "github.com/maazxenon/task-api/recurrence"
+    "github.com/maazxenon/task-api/auth"
 )
 
-// Task represents a task in the task list
-type Task models.Task
+// Handler serves the task endpoints using the injected Store
+type Handler struct {
+    store  database.Store
+    tokens *auth.Manager
+    // idempotencyWindow is how long the response to an Idempotency-Key is replayed
+    idempotencyWindow time.Duration
+}
 
-type ErrorResponse struct {
-    Message string `json:"message"`
+// NewHandler returns a Handler that reads and writes tasks through store, authenticates
+// requests with tokens and remembers idempotency keys for idempotencyWindow
+func NewHandler(store database.Store, tokens *auth.Manager, idempotencyWindow time.Duration) *Handler {
+    return &Handler{store: store, tokens: tokens, idempotencyWindow: idempotencyWindow}
 }
 
 // Validator instance
@@ -26,12 +35,30 @@ var validate *validator.Validate
 // init initializes the validator
 func init() {
     validate = validator.New()
+    validate.RegisterTagNameFunc(jsonFieldName)
+    if engine, ok := binding.Validator.Engine().(*validator.Validate); ok {
+        engine.RegisterTagNameFunc(jsonFieldName)
+    }
     validate.RegisterValidation("status", validateStatus)
+    validate.RegisterValidationCtx("unblocked", validateUnblocked)
+    validate.RegisterValidation("rrule", validateRRule)
+    validate.RegisterValidation("duedate", validateDueDate)
+    validate.RegisterValidation("tagname", validateTagName)
 }
 
 // validateStatus is a custom validation function for the status field
 func validateStatus(fl validator.FieldLevel) bool {
-    status := fl.Field().String()
+    return isValidStatus(fl.Field().String())
+}
+
+// validateRRule is a custom validation function for the recurrence field
+func validateRRule(fl validator.FieldLevel) bool {
+    _, err := recurrence.Parse(fl.Field().String())
+    return err == nil
+}
+
+// isValidStatus reports whether status is one of the known task statuses
+func isValidStatus(status string) bool {
     switch status {
     case "pending", "completed", "in progress":
         return true
@@ -39,44 +66,50 @@ func validateStatus(fl validator.FieldLevel) bool {
     return false
 }
 
-// DB is a global variable for the SQLite database connection
-var DB *sql.DB = database.DB
-
-// IndexHandler serves the main page and displays all tasks
-// @Summary Get all tasks
-// @Description Get a list of all tasks
+// IndexHandler lists tasks one page at a time
+// @Summary List tasks
+// @Description Get a page of tasks, optionally filtered and sorted. Follow next_cursor or the Link header for the next page.
 // @Tags tasks
 // @Produce  json
-// @Success 200 {array} Task
-// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Param status query string false "Only tasks with this status" Enums(pending, completed, in progress)
+// @Param due_after query string false "Only tasks due on or after this RFC 3339 timestamp or date"
+// @Param due_before query string false "Only tasks due on or before this RFC 3339 timestamp or date (inclusive of the whole day)"
+// @Param title query string false "Only tasks whose title contains this text (case-insensitive)"
+// @Param project_id query int false "Only tasks in this project"
+// @Param assignee query string false "Only tasks assigned to this user ID, to the acting user (me) or to nobody (none)"
+// @Param include_archived query bool false "Include tasks of archived projects" default(false)
+// @Param tags_any query string false "Comma separated tags; only tasks carrying at least one of them"
+// @Param tags_all query string false "Comma separated tags; only tasks carrying all of them"
+// @Param sort query string false "Field to sort by" Enums(id, title, due_date, status) default(id)
+// @Param order query string false "Sort direction" Enums(asc, desc) default(asc)
+// @Param limit query int false "Page size" minimum(1) maximum(200) default(50)
+// @Param cursor query string false "Cursor returned as next_cursor by the previous page"
+// @Param tz query string false "IANA time zone to render due dates in and to read date-only values in; also accepted as the X-Timezone header" default(UTC)
+// @Success 200 {object} TaskListResponse
+// @Header 200 {string} Link "Link to the next page (rel=next) when another page follows"
+// @Failure 400 {object} Problem "Bad Request"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Security BearerAuth
+// @Security ApiKeyAuth
 // @Router /tasks [get]
-func IndexHandler(c *gin.Context) {
-    rows, err := database.DB.Query("SELECT id, title, description, due_date, status FROM tasks")
+func (h *Handler) IndexHandler(c *gin.Context) {
+    filter, err := parseTaskFilter(c)
     if err != nil {
-        log.Printf("Error querying tasks: %v", err)
-        c.JSON(http.StatusInternalServerError, ErrorResponse{Message: err.Error()})
+        problem(c, http.StatusBadRequest, "invalid_parameter", err.Error())
         return
     }
-    defer rows.Close()
-
-    tasks := []Task{}
-    for rows.Next() {
-        var task Task
-        if err := rows.Scan(&task.ID, &task.Title, &task.Description, &task.DueDate, &task.Status); err != nil {
-            log.Printf("Error scanning task: %v", err)
-            c.JSON(http.StatusInternalServerError, ErrorResponse{Message: err.Error()})
-            return
-        }
-        tasks = append(tasks, task)
-    }
 
-    if err = rows.Err(); err != nil {
-        log.Printf("Error with rows: %v", err)
-        c.JSON(http.StatusInternalServerError, ErrorResponse{Message: err.Error()})
+    page, err := h.store.List(c.Request.Context(), filter)
+    if err != nil {
+        storeError(c, err, "querying tasks")
         return
     }
 
-    c.JSON(http.StatusOK, tasks)
+    localizeTasks(c, page.Tasks)
+    c.JSON(http.StatusOK, TaskListResponse{
+        Tasks:      page.Tasks,
+        NextCursor: setNextLink(c, filter, page.Next),
+    })
 }
 // GetTaskHandler handles fetching details of a specific task by ID
 // @Summary Get task details
@@ -84,179 +117,224 @@ func IndexHandler(c *gin.Context) {
 // @Tags tasks
 // @Produce  json
 // @Param id path int true "Task ID"
-// @Success 200 {object} Task
-// @Failure 400 {object} ErrorResponse "Bad Request"
-// @Failure 404 {object} ErrorResponse "Not Found"
-// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Param If-None-Match header string false "ETag of a cached copy; answered with 304 if still current"
+// @Param tz query string false "IANA time zone to render due dates in and to read date-only values in; also accepted as the X-Timezone header" default(UTC)
+// @Success 200 {object} models.Task
+// @Header 200 {string} ETag "Current revision of the task"
+// @Success 304 "Not Modified"
+// @Failure 400 {object} Problem "Bad Request"
+// @Failure 404 {object} Problem "Not Found"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Security BearerAuth
+// @Security ApiKeyAuth
 // @Router /tasks/{id} [get]
-func GetTaskHandler(c *gin.Context) {
-    id := c.Param("id")
+func (h *Handler) GetTaskHandler(c *gin.Context) {
+    id, err := strconv.Atoi(c.Param("id"))
+    if err != nil {
+        problem(c, http.StatusBadRequest, "invalid_id", "Invalid task ID")
+        return
+    }
 
-    var task Task
-    err := database.DB.QueryRow("SELECT id, title, description, due_date, status FROM tasks WHERE id = ?", id).Scan(&task.ID, &task.Title, &task.Description, &task.DueDate, &task.Status)
+    task, err := h.store.Get(c.Request.Context(), id)
     if err != nil {
-        if err == sql.ErrNoRows {
-            c.JSON(http.StatusNotFound, ErrorResponse{Message: "Task not found"})
-        } else {
-            log.Printf("Error querying task: %v", err)
-            c.JSON(http.StatusInternalServerError, ErrorResponse{Message: err.Error()})
-        }
+        storeError(c, err, "querying task")
         return
     }
 
+    c.Header("ETag", etag(task))
+    if match := c.GetHeader("If-None-Match"); match != "" && etagMatches(match, etag(task), true) {
+        c.Status(http.StatusNotModified)
+        return
+    }
+
+    localizeTask(c, &task)
     c.JSON(http.StatusOK, task)
 }
 // CreateHandler handles the creation of a new task
 // @Summary Create a new task
-// @Description Create a new task with the provided details
+// @Description Create a new task with the provided details. Tasks created in a project without a status get the project's default status. due_date also accepts phrases such as "next friday 5pm" or "in 3 days", resolved in the client's time zone; the phrase is echoed as due_date_phrase. Send an Idempotency-Key to retry safely: a retry with the same key, query string and body gets the original response replayed instead of creating another task.
 // @Tags tasks
 // @Accept  json
 // @Produce  json
-// @Param task body Task true "Task"
-// @Success 200 {object} Task
-// @Failure 400 {object} ErrorResponse "Bad Request"
-// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Param task body models.Task true "Task"
+// @Param tz query string false "IANA time zone to render due dates in and to read date-only values in; also accepted as the X-Timezone header" default(UTC)
+// @Param ref query string false "RFC 3339 time natural-language due dates are relative to; also accepted as the X-Reference-Time header (default: now)"
+// @Param Idempotency-Key header string false "Unique key of this request, up to 255 printable ASCII characters; remembered for 24 hours by default"
+// @Success 200 {object} models.Task
+// @Header 200 {string} Idempotent-Replayed "true when the response is replayed for a retried request"
+// @Failure 400 {object} Problem "Bad Request"
+// @Failure 403 {object} Problem "Your role doesn't allow this"
+// @Failure 409 {object} Problem "Conflict, or a request with the same Idempotency-Key is still in progress"
+// @Failure 422 {object} Problem "The Idempotency-Key was already used with a different request"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Security BearerAuth
+// @Security ApiKeyAuth
 // @Router /tasks [post]
-func CreateHandler(c *gin.Context) {
-    var task Task
-    if err := c.ShouldBindJSON(&task); err != nil {
-        c.JSON(http.StatusBadRequest, ErrorResponse{Message: err.Error()})
+func (h *Handler) CreateHandler(c *gin.Context) {
+    h.idempotent(c, func(h *Handler, c *gin.Context) {
+        var task models.Task
+        if err := c.ShouldBindJSON(&task); err != nil {
+            bindError(c, err)
+            return
+        }
+
+        h.createTask(c, task)
+    })
+}
+
+// createTask validates and stores a new task bound from the request body
+func (h *Handler) createTask(c *gin.Context, task models.Task) {
+    if !resolveDueDate(c, &task) {
         return
     }
 
-    // Validate the task struct
-    if err := validate.Struct(task); err != nil {
-        c.JSON(http.StatusBadRequest, ErrorResponse{Message: err.Error()})
-        return
+    if task.Status == "" && task.ProjectID != nil {
+        project, err := h.store.GetProject(c.Request.Context(), *task.ProjectID)
+        if errors.Is(err, database.ErrProjectNotFound) {
+            err = database.ErrInvalidProject
+        }
+        if err != nil {
+            storeError(c, err, "querying project")
+            return
+        }
+        task.Status = project.DefaultStatus
     }
 
-    result, err := database.DB.Exec("INSERT INTO tasks(title, description, due_date, status) VALUES(?, ?, ?, ?)", task.Title, task.Description, task.DueDate, task.Status)
-    if err != nil {
-        log.Printf("Error executing query: %v", err)
-        c.JSON(http.StatusInternalServerError, ErrorResponse{Message: err.Error()})
+    // Validate the task struct
+    if err := h.validateTask(c.Request.Context(), task); err != nil {
+        validationError(c, err)
         return
     }
 
-    id, err := result.LastInsertId()
+    task.CreatedBy = actingUser(c)
+    task, err := h.store.Create(c.Request.Context(), task)
     if err != nil {
-        log.Printf("Error getting last insert ID: %v", err)
-        c.JSON(http.StatusInternalServerError, ErrorResponse{Message: err.Error()})
+        storeError(c, err, "creating task")
         return
     }
 
-    task.ID = int(id)
+    c.Header("ETag", etag(task))
+    localizeTask(c, &task)
     c.JSON(http.StatusOK, task)
 }
 
 // UpdateTaskHandler handles updating an existing task
 // @Summary Update a task
-// @Description Update the details of an existing task by ID
+// @Description Update the details of an existing task by ID. Completing a task with a recurrence rule creates its next occurrence, linked through next_occurrence_id; when the workspace has used up its task quota the completion is refused with 403. Members may only update tasks they created or are assigned to; admins may update any task.
 // @Tags tasks
 // @Accept  json
 // @Produce  json
 // @Param id path int true "Task ID"
-// @Param task body Task true "Task"
-// @Success 200 {object} Task
-// @Failure 400 {object} ErrorResponse "Bad Request"
-// @Failure 404 {object} ErrorResponse "Not Found"
-// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Param If-Match header string false "ETag the task must still have for the update to apply"
+// @Param task body models.Task true "Task"
+// @Param tz query string false "IANA time zone to render due dates in and to read date-only values in; also accepted as the X-Timezone header" default(UTC)
+// @Param ref query string false "RFC 3339 time natural-language due dates are relative to; also accepted as the X-Reference-Time header (default: now)"
+// @Success 200 {object} models.Task
+// @Header 200 {string} ETag "New revision of the task"
+// @Failure 400 {object} Problem "Bad Request"
+// @Failure 403 {object} Problem "Your role doesn't allow updating this task, or completing it would exceed the task quota"
+// @Failure 404 {object} Problem "Not Found"
+// @Failure 409 {object} Problem "The new parent would create a cycle or the task's project is archived"
+// @Failure 412 {object} Problem "Precondition Failed"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Security BearerAuth
+// @Security ApiKeyAuth
 // @Router /tasks/{id} [put]
-func UpdateTaskHandler(c *gin.Context) {
+func (h *Handler) UpdateTaskHandler(c *gin.Context) {
     idStr := c.Param("id")
     id, err := strconv.Atoi(idStr)
     if err != nil {
-        c.JSON(http.StatusBadRequest, ErrorResponse{Message: "Invalid task ID"})
+        problem(c, http.StatusBadRequest, "invalid_id", "Invalid task ID")
         return
     }
 
-    var task Task
+    if !h.authorizeTask(c, id, updateTask) {
+        return
+    }
+
+    var task models.Task
     if err := c.ShouldBindJSON(&task); err != nil {
-        c.JSON(http.StatusBadRequest, ErrorResponse{Message: err.Error()})
+        bindError(c, err)
         return
     }
 
-    // Validate the task struct
-    if err := validate.Struct(task); err != nil {
-        c.JSON(http.StatusBadRequest, ErrorResponse{Message: err.Error()})
+    task.ID = id
+    if !resolveDueDate(c, &task) {
         return
     }
 
-    result, err := database.DB.Exec("UPDATE tasks SET title = ?, description = ?, due_date = ?, status = ? WHERE id = ?", task.Title, task.Description, task.DueDate, task.Status, id)
-    if err != nil {
-        log.Printf("Error executing query: %v", err)
-        c.JSON(http.StatusInternalServerError, ErrorResponse{Message: err.Error()})
+    // Validate the task struct
+    if err := h.validateTask(c.Request.Context(), task); err != nil {
+        validationError(c, err)
         return
     }
 
-    rowsAffected, err := result.RowsAffected()
-    if err != nil {
-        log.Printf("Error getting rows affected: %v", err)
-        c.JSON(http.StatusInternalServerError, ErrorResponse{Message: err.Error()})
+    version, ok := h.checkIfMatch(c, id)
+    if !ok {
         return
     }
 
-    if rowsAffected == 0 {
-        c.JSON(http.StatusNotFound, ErrorResponse{Message: "Task not found"})
+    task.UpdatedBy = actingUser(c)
+    task, err = h.store.Update(c.Request.Context(), task, version)
+    if err != nil {
+        storeError(c, err, "updating task")
         return
     }
 
-    task.ID = id
+    c.Header("ETag", etag(task))
+    localizeTask(c, &task)
     c.JSON(http.StatusOK, task)
 }
 
 // DeleteHandler handles the deletion
 // @Summary Delete a task
-// @Description Delete a task by ID
+// @Description Move a task to the trash, from where POST /tasks/{id}/restore brings it back until it is purged. Its subtasks are either trashed with it or become top-level tasks. Members may only delete tasks they created, including every subtask deleted with cascade; admins may delete any task.
 // @Tags tasks
 // @Produce  json
 // @Param id path int true "Task ID"
-// @Success 200 {object} map[string]string "message: Task deleted"
-// @Failure 400 {object} ErrorResponse "Bad Request"
-// @Failure 404 {object} ErrorResponse "Not Found"
-// @Failure 500 {object} ErrorResponse "Internal Server Error"
+// @Param children query string false "What happens to subtasks" Enums(orphan, cascade) default(orphan)
+// @Param If-Match header string false "ETag the task must still have for the delete to apply"
+// @Success 200 {object} map[string]string "message: Task moved to trash"
+// @Failure 400 {object} Problem "Bad Request"
+// @Failure 403 {object} Problem "Your role doesn't allow deleting this task"
+// @Failure 404 {object} Problem "Not Found"
+// @Failure 412 {object} Problem "Precondition Failed"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Security BearerAuth
+// @Security ApiKeyAuth
 // @Router /tasks/{id} [delete]
-func DeleteHandler(c *gin.Context) {
+func (h *Handler) DeleteHandler(c *gin.Context) {
     idStr := c.Param("id")
     id, err := strconv.Atoi(idStr)
     if err != nil {
-        c.JSON(http.StatusBadRequest, ErrorResponse{Message: "Invalid task ID"})
+        problem(c, http.StatusBadRequest, "invalid_id", "Invalid task ID")
         return
     }
 
-    err = deleteTaskByID(id)
-    if err != nil {
-        if err == ErrTaskNotFound {
-            c.JSON(http.StatusNotFound, ErrorResponse{Message: "Task not found"})
-        } else {
-            log.Printf("Error deleting task: %v", err)
-            c.JSON(http.StatusInternalServerError, ErrorResponse{Message: "Internal server error"})
-        }
+    var cascade bool
+    switch children := c.DefaultQuery("children", "orphan"); children {
+    case "orphan":
+    case "cascade":
+        cascade = true
+    default:
+        problem(c, http.StatusBadRequest, "invalid_parameter", "children must be orphan or cascade")
         return
     }
 
-    c.JSON(http.StatusOK, map[string]string{"message": "Task deleted"})
-}
-
-// deleteTaskByID deletes a task by its ID from the data store
-func deleteTaskByID(id int) error {
-    result, err := database.DB.Exec("DELETE FROM tasks WHERE id = ?", id)
-    if err != nil {
-        log.Printf("Error executing delete query: %v", err)
-        return err
+    if !h.authorizeDelete(c, id, cascade) {
+        return
     }
 
-    rowsAffected, err := result.RowsAffected()
-    if err != nil {
-        log.Printf("Error getting rows affected: %v", err)
-        return err
+    version, ok := h.checkIfMatch(c, id)
+    if !ok {
+        return
     }
 
-    if rowsAffected == 0 {
-        return ErrTaskNotFound
+    err = h.store.Delete(c.Request.Context(), id, database.DeleteOptions{Version: version, Cascade: cascade})
+    if err != nil {
+        storeError(c, err, "deleting task")
+        return
     }
 
-    return nil
+    c.JSON(http.StatusOK, map[string]string{"message": "Task moved to trash"})
 }
-
-// ErrTaskNotFound is an error returned when a task is not found
-var ErrTaskNotFound = fmt.Errorf("task not found")
\ No newline at end of file
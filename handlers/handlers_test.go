@@ -0,0 +1,318 @@
+package handlers
+
+import (
+    "context"
+    "encoding/json"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "testing"
+    "time"
+    "github.com/gin-gonic/gin"
+    "github.com/maazxenon/task-api/auth"
+    "github.com/maazxenon/task-api/models"
+)
+
+// Users of the handler tests
+const (
+    admin = 1
+    alice = 2
+    bob   = 3
+)
+
+// newTestStore returns the tasks the handler tests start from: alice owns 1 and 4, bob owns
+// the rest, 2 is assigned to alice, 5 is a subtask of 4 and 1 is blocked by 3
+func newTestStore() *fakeStore {
+    s := newFakeStore(
+        models.Task{ID: 1, Title: "Write tests", Status: "pending", CreatedBy: intPtr(alice)},
+        models.Task{ID: 2, Title: "Review tests", Status: "pending", DueDate: "2024-05-16T09:00:00Z", CreatedBy: intPtr(bob), AssigneeID: intPtr(alice)},
+        models.Task{ID: 3, Title: "Set up CI", Status: "pending", CreatedBy: intPtr(bob)},
+        models.Task{ID: 4, Title: "Release", Status: "pending", CreatedBy: intPtr(alice)},
+        models.Task{ID: 5, Title: "Tag the release", Status: "pending", CreatedBy: intPtr(bob), ParentID: intPtr(4)},
+    )
+    s.blockers[1] = []int{3}
+    return s
+}
+
+func intPtr(n int) *int {
+    return &n
+}
+
+// newTestRouter serves the task routes the way routes.Setup does, with the authenticated
+// user and role set directly instead of by a token
+func newTestRouter(store *fakeStore, userID int, role string) *gin.Engine {
+    gin.SetMode(gin.TestMode)
+    h := NewHandler(store, nil, time.Hour)
+
+    r := gin.New()
+    r.Use(Timezone(), func(c *gin.Context) {
+        c.Set(userKey, userID)
+        c.Set(roleKey, role)
+    })
+    r.GET("/tasks", h.IndexHandler)
+    r.POST("/tasks", Require(auth.CreateTasks), h.CreateHandler)
+    r.GET("/tasks/:id", h.GetTaskHandler)
+    r.PUT("/tasks/:id", h.UpdateTaskHandler)
+    r.PATCH("/tasks/:id", h.PatchTaskHandler)
+    r.DELETE("/tasks/:id", h.DeleteHandler)
+    return r
+}
+
+func TestTaskHandlers(t *testing.T) {
+    tests := []struct {
+        name       string
+        user       int
+        role       string
+        method     string
+        target     string
+        header     map[string]string
+        body       string
+        wantStatus int
+        // wantCode is the problem code of a failed request
+        wantCode string
+        check    func(t *testing.T, store *fakeStore, body map[string]any)
+    }{
+        {
+            name: "get", user: bob, role: auth.RoleViewer,
+            method: http.MethodGet, target: "/tasks/1",
+            wantStatus: http.StatusOK,
+            check: func(t *testing.T, store *fakeStore, body map[string]any) {
+                if body["title"] != "Write tests" {
+                    t.Errorf("title = %v", body["title"])
+                }
+            },
+        },
+        {
+            name: "get renders the due date in the client's time zone", user: bob, role: auth.RoleViewer,
+            method: http.MethodGet, target: "/tasks/2?tz=Asia/Tokyo",
+            wantStatus: http.StatusOK,
+            check: func(t *testing.T, store *fakeStore, body map[string]any) {
+                if body["due_date"] != "2024-05-16T18:00:00+09:00" {
+                    t.Errorf("due_date = %v", body["due_date"])
+                }
+            },
+        },
+        {
+            name: "get missing task", user: bob, role: auth.RoleViewer,
+            method: http.MethodGet, target: "/tasks/99",
+            wantStatus: http.StatusNotFound, wantCode: "task_not_found",
+        },
+        {
+            name: "get invalid id", user: bob, role: auth.RoleViewer,
+            method: http.MethodGet, target: "/tasks/one",
+            wantStatus: http.StatusBadRequest, wantCode: "invalid_id",
+        },
+        {
+            name: "get unchanged task", user: bob, role: auth.RoleViewer,
+            method: http.MethodGet, target: "/tasks/1", header: map[string]string{"If-None-Match": `"1"`},
+            wantStatus: http.StatusNotModified,
+        },
+        {
+            name: "list first page", user: bob, role: auth.RoleViewer,
+            method: http.MethodGet, target: "/tasks?limit=2",
+            wantStatus: http.StatusOK,
+            check: func(t *testing.T, store *fakeStore, body map[string]any) {
+                if tasks := body["tasks"].([]any); len(tasks) != 2 {
+                    t.Errorf("got %d tasks, want 2", len(tasks))
+                }
+                token, err := decodeCursor(body["next_cursor"].(string))
+                if err != nil || token.Sort != "id" || token.ID != 2 {
+                    t.Errorf("next_cursor = %+v, %v", token, err)
+                }
+            },
+        },
+        {
+            name: "list with invalid cursor", user: bob, role: auth.RoleViewer,
+            method: http.MethodGet, target: "/tasks?cursor=abc",
+            wantStatus: http.StatusBadRequest, wantCode: "invalid_parameter",
+        },
+        {
+            name: "list with cursor of another sort", user: bob, role: auth.RoleViewer,
+            method: http.MethodGet, target: "/tasks?sort=title&cursor=" + encodeCursor(cursorToken{Sort: "id"}),
+            wantStatus: http.StatusBadRequest, wantCode: "invalid_parameter",
+        },
+        {
+            name: "create", user: alice, role: auth.RoleMember,
+            method: http.MethodPost, target: "/tasks", body: `{"title": "Fix flaky test", "status": "pending"}`,
+            wantStatus: http.StatusOK,
+            check: func(t *testing.T, store *fakeStore, body map[string]any) {
+                task, err := store.Get(context.Background(), 6)
+                if err != nil || task.Title != "Fix flaky test" || task.CreatedBy == nil || *task.CreatedBy != alice {
+                    t.Errorf("stored %+v, %v", task, err)
+                }
+            },
+        },
+        {
+            name: "create with a natural due date", user: alice, role: auth.RoleMember,
+            method: http.MethodPost, target: "/tasks?ref=2024-05-15T10:30:00Z",
+            body:       `{"title": "Fix flaky test", "status": "pending", "due_date": "tomorrow 9am"}`,
+            wantStatus: http.StatusOK,
+            check: func(t *testing.T, store *fakeStore, body map[string]any) {
+                if body["due_date"] != "2024-05-16T09:00:00Z" || body["due_date_phrase"] != "tomorrow 9am" {
+                    t.Errorf("due_date = %v, due_date_phrase = %v", body["due_date"], body["due_date_phrase"])
+                }
+            },
+        },
+        {
+            name: "create without status", user: alice, role: auth.RoleMember,
+            method: http.MethodPost, target: "/tasks", body: `{"title": "Fix flaky test"}`,
+            wantStatus: http.StatusBadRequest, wantCode: "validation_failed",
+        },
+        {
+            name: "create with malformed body", user: alice, role: auth.RoleMember,
+            method: http.MethodPost, target: "/tasks", body: `{"title": `,
+            wantStatus: http.StatusBadRequest,
+        },
+        {
+            name: "viewers can't create", user: bob, role: auth.RoleViewer,
+            method: http.MethodPost, target: "/tasks", body: `{"title": "Fix flaky test", "status": "pending"}`,
+            wantStatus: http.StatusForbidden, wantCode: "forbidden",
+        },
+        {
+            name: "update own task", user: alice, role: auth.RoleMember,
+            method: http.MethodPut, target: "/tasks/1", body: `{"title": "Write more tests", "status": "in progress"}`,
+            wantStatus: http.StatusOK,
+            check: func(t *testing.T, store *fakeStore, body map[string]any) {
+                task, _ := store.Get(context.Background(), 1)
+                if task.Title != "Write more tests" || task.Version != 2 || task.UpdatedBy == nil || *task.UpdatedBy != alice {
+                    t.Errorf("stored %+v", task)
+                }
+            },
+        },
+        {
+            name: "update assigned task", user: alice, role: auth.RoleMember,
+            method: http.MethodPut, target: "/tasks/2", body: `{"title": "Review tests", "status": "completed"}`,
+            wantStatus: http.StatusOK,
+        },
+        {
+            name: "update another member's task", user: alice, role: auth.RoleMember,
+            method: http.MethodPut, target: "/tasks/3", body: `{"title": "Set up CI", "status": "completed"}`,
+            wantStatus: http.StatusForbidden, wantCode: "forbidden",
+        },
+        {
+            name: "admins update any task", user: admin, role: auth.RoleAdmin,
+            method: http.MethodPut, target: "/tasks/3", body: `{"title": "Set up CI", "status": "completed"}`,
+            wantStatus: http.StatusOK,
+        },
+        {
+            name: "update stale version", user: alice, role: auth.RoleMember,
+            method: http.MethodPut, target: "/tasks/1", header: map[string]string{"If-Match": `"7"`},
+            body:       `{"title": "Write more tests", "status": "pending"}`,
+            wantStatus: http.StatusPreconditionFailed,
+        },
+        {
+            name: "complete blocked task", user: alice, role: auth.RoleMember,
+            method: http.MethodPut, target: "/tasks/1", body: `{"title": "Write tests", "status": "completed"}`,
+            wantStatus: http.StatusBadRequest, wantCode: "validation_failed",
+        },
+        {
+            name: "merge patch", user: alice, role: auth.RoleMember,
+            method: http.MethodPatch, target: "/tasks/1", header: map[string]string{"Content-Type": mergePatchType},
+            body:       `{"status": "in progress"}`,
+            wantStatus: http.StatusOK,
+            check: func(t *testing.T, store *fakeStore, body map[string]any) {
+                task, _ := store.Get(context.Background(), 1)
+                if task.Title != "Write tests" || task.Status != "in progress" || task.Version != 2 {
+                    t.Errorf("stored %+v", task)
+                }
+            },
+        },
+        {
+            name: "json patch failing test", user: alice, role: auth.RoleMember,
+            method: http.MethodPatch, target: "/tasks/1", header: map[string]string{"Content-Type": jsonPatchType},
+            body:       `[{"op": "test", "path": "/status", "value": "completed"}]`,
+            wantStatus: http.StatusConflict, wantCode: "patch_test_failed",
+        },
+        {
+            name: "patch as plain JSON", user: alice, role: auth.RoleMember,
+            method: http.MethodPatch, target: "/tasks/1", body: `{"status": "in progress"}`,
+            wantStatus: http.StatusUnsupportedMediaType, wantCode: "unsupported_media_type",
+        },
+        {
+            name: "patch another member's task", user: alice, role: auth.RoleMember,
+            method: http.MethodPatch, target: "/tasks/3", header: map[string]string{"Content-Type": mergePatchType},
+            body:       `{"status": "in progress"}`,
+            wantStatus: http.StatusForbidden, wantCode: "forbidden",
+        },
+        {
+            name: "delete own task", user: alice, role: auth.RoleMember,
+            method: http.MethodDelete, target: "/tasks/1",
+            wantStatus: http.StatusOK,
+            check: func(t *testing.T, store *fakeStore, body map[string]any) {
+                if _, err := store.Get(context.Background(), 1); err == nil {
+                    t.Error("task 1 still exists")
+                }
+            },
+        },
+        {
+            name: "assignees can't delete", user: alice, role: auth.RoleMember,
+            method: http.MethodDelete, target: "/tasks/2",
+            wantStatus: http.StatusForbidden, wantCode: "forbidden",
+        },
+        {
+            name: "delete keeps subtasks", user: alice, role: auth.RoleMember,
+            method: http.MethodDelete, target: "/tasks/4",
+            wantStatus: http.StatusOK,
+            check: func(t *testing.T, store *fakeStore, body map[string]any) {
+                if task, err := store.Get(context.Background(), 5); err != nil || task.ParentID != nil {
+                    t.Errorf("subtask %+v, %v", task, err)
+                }
+            },
+        },
+        {
+            name: "cascade over another member's subtask", user: alice, role: auth.RoleMember,
+            method: http.MethodDelete, target: "/tasks/4?children=cascade",
+            wantStatus: http.StatusForbidden, wantCode: "forbidden",
+            check: func(t *testing.T, store *fakeStore, body map[string]any) {
+                if _, err := store.Get(context.Background(), 4); err != nil {
+                    t.Error("task 4 was deleted")
+                }
+            },
+        },
+        {
+            name: "admins cascade", user: admin, role: auth.RoleAdmin,
+            method: http.MethodDelete, target: "/tasks/4?children=cascade",
+            wantStatus: http.StatusOK,
+            check: func(t *testing.T, store *fakeStore, body map[string]any) {
+                if _, err := store.Get(context.Background(), 5); err == nil {
+                    t.Error("subtask 5 still exists")
+                }
+            },
+        },
+        {
+            name: "delete with unknown children mode", user: admin, role: auth.RoleAdmin,
+            method: http.MethodDelete, target: "/tasks/4?children=adopt",
+            wantStatus: http.StatusBadRequest, wantCode: "invalid_parameter",
+        },
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            store := newTestStore()
+            req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+            if tt.body != "" {
+                req.Header.Set("Content-Type", "application/json")
+            }
+            for name, value := range tt.header {
+                req.Header.Set(name, value)
+            }
+            rec := httptest.NewRecorder()
+            newTestRouter(store, tt.user, tt.role).ServeHTTP(rec, req)
+
+            if rec.Code != tt.wantStatus {
+                t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body)
+            }
+            var body map[string]any
+            if rec.Body.Len() > 0 {
+                if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+                    t.Fatalf("response is not a JSON object: %s", rec.Body)
+                }
+            }
+            if tt.wantCode != "" && body["code"] != tt.wantCode {
+                t.Errorf("code = %v, want %s", body["code"], tt.wantCode)
+            }
+            if tt.check != nil {
+                tt.check(t, store, body)
+            }
+        })
+    }
+}
@@ -0,0 +1,105 @@
+package handlers
+
+import (
+    "math"
+    "net/http"
+    "strconv"
+    "github.com/gin-gonic/gin"
+    "github.com/maazxenon/task-api/models"
+)
+
+// ChildrenHandler lists the direct subtasks of a task
+// @Summary List subtasks
+// @Description Get the direct subtasks of a task
+// @Tags tasks
+// @Produce  json
+// @Param id path int true "Task ID"
+// @Success 200 {array} models.Task
+// @Failure 400 {object} Problem "Bad Request"
+// @Failure 404 {object} Problem "Not Found"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /tasks/{id}/children [get]
+func (h *Handler) ChildrenHandler(c *gin.Context) {
+    id, err := strconv.Atoi(c.Param("id"))
+    if err != nil {
+        problem(c, http.StatusBadRequest, "invalid_id", "Invalid task ID")
+        return
+    }
+
+    children, err := h.store.Children(c.Request.Context(), id)
+    if err != nil {
+        storeError(c, err, "querying subtasks")
+        return
+    }
+
+    localizeTasks(c, children)
+    c.JSON(http.StatusOK, children)
+}
+
+// TreeHandler returns a task with all of its descendants nested below it
+// @Summary Get task tree
+// @Description Get a task and all of its descendants as nested JSON, with completion progress rolled up from the subtasks
+// @Tags tasks
+// @Produce  json
+// @Param id path int true "Task ID"
+// @Success 200 {object} models.TaskNode
+// @Failure 400 {object} Problem "Bad Request"
+// @Failure 404 {object} Problem "Not Found"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /tasks/{id}/tree [get]
+func (h *Handler) TreeHandler(c *gin.Context) {
+    id, err := strconv.Atoi(c.Param("id"))
+    if err != nil {
+        problem(c, http.StatusBadRequest, "invalid_id", "Invalid task ID")
+        return
+    }
+
+    tasks, err := h.store.Subtree(c.Request.Context(), id)
+    if err != nil {
+        storeError(c, err, "querying task tree")
+        return
+    }
+
+    localizeTasks(c, tasks)
+    c.JSON(http.StatusOK, buildTree(tasks))
+}
+
+// buildTree nests tasks under their parents. tasks[0] is the root and every
+// other task comes after its parent, as returned by TaskStore.Subtree.
+func buildTree(tasks []models.Task) *models.TaskNode {
+    nodes := make(map[int]*models.TaskNode, len(tasks))
+    root := &models.TaskNode{Task: tasks[0], Children: []*models.TaskNode{}}
+    nodes[root.ID] = root
+
+    for _, task := range tasks[1:] {
+        node := &models.TaskNode{Task: task, Children: []*models.TaskNode{}}
+        nodes[task.ID] = node
+        parent := nodes[*task.ParentID]
+        parent.Children = append(parent.Children, node)
+    }
+
+    rollupProgress(root)
+    return root
+}
+
+// rollupProgress fills in Progress bottom-up and returns the node's unrounded progress
+func rollupProgress(node *models.TaskNode) float64 {
+    var progress float64
+    if len(node.Children) == 0 {
+        if node.Status == "completed" {
+            progress = 100
+        }
+    } else {
+        for _, child := range node.Children {
+            progress += rollupProgress(child)
+        }
+        progress /= float64(len(node.Children))
+    }
+
+    node.Progress = math.Round(progress*10) / 10
+    return progress
+}
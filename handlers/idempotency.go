@@ -0,0 +1,94 @@
+package handlers
+
+import (
+    "bytes"
+    "crypto/sha256"
+    "encoding/hex"
+    "errors"
+    "io"
+    "net/http"
+    "regexp"
+    "time"
+    "github.com/gin-gonic/gin"
+    "github.com/maazxenon/task-api/database"
+)
+
+// IdempotencyKeyHeader lets clients retry a request without applying it twice
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// IdempotentReplayedHeader is set on responses replayed for a retried request
+const IdempotentReplayedHeader = "Idempotent-Replayed"
+
+// idempotencyKeyPattern limits keys to printable ASCII such as a UUID
+var idempotencyKeyPattern = regexp.MustCompile(`^[\x21-\x7e]{1,255}$`)
+
+// errNotStored rolls back the claim of an idempotency key whose request failed
+var errNotStored = errors.New("response not stored")
+
+// idempotent runs handler once per Idempotency-Key of the acting user. The response of a
+// successful request is stored together with the key in the same transaction and replayed
+// for retries of the same request; failed requests are not stored, so they can be retried
+// with the same key. Requests without the header just run handler.
+func (h *Handler) idempotent(c *gin.Context, handler func(h *Handler, c *gin.Context)) {
+    key := c.GetHeader(IdempotencyKeyHeader)
+    if key == "" {
+        handler(h, c)
+        return
+    }
+    if !idempotencyKeyPattern.MatchString(key) {
+        problem(c, http.StatusBadRequest, "invalid_header", IdempotencyKeyHeader+" must be 1 to 255 printable ASCII characters")
+        return
+    }
+
+    body, err := io.ReadAll(c.Request.Body)
+    if err != nil {
+        problem(c, http.StatusBadRequest, "malformed_body", err.Error())
+        return
+    }
+    c.Request.Body = io.NopCloser(bytes.NewReader(body))
+    // The query string counts too: tz and ref change how due dates resolve
+    fingerprint := sha256.Sum256(append([]byte(c.Request.Method+" "+c.Request.URL.Path+"?"+c.Request.URL.RawQuery+"\n"), body...))
+
+    ctx := c.Request.Context()
+    rec := newResponseRecorder()
+    var replay *database.IdempotentResponse
+    err = h.store.InTx(ctx, func(store database.Store) error {
+        stored, err := store.ClaimIdempotencyKey(ctx, key, hex.EncodeToString(fingerprint[:]), time.Now().Add(-h.idempotencyWindow))
+        if err != nil || stored != nil {
+            replay = stored
+            return err
+        }
+
+        tx := *h
+        tx.store = store
+        sub := c.Copy()
+        sub.Writer = rec
+        handler(&tx, sub)
+
+        if rec.Status() >= http.StatusMultipleChoices {
+            return errNotStored
+        }
+        return store.SaveIdempotentResponse(ctx, key, database.IdempotentResponse{
+            Status: rec.Status(),
+            ETag:   rec.Header().Get("ETag"),
+            Body:   rec.body.Bytes(),
+        })
+    })
+
+    switch {
+    case err == nil && replay != nil:
+        if replay.ETag != "" {
+            c.Header("ETag", replay.ETag)
+        }
+        c.Header(IdempotentReplayedHeader, "true")
+        c.Data(replay.Status, "application/json; charset=utf-8", replay.Body)
+    case err == nil, errors.Is(err, errNotStored):
+        rec.writeTo(c)
+    case errors.Is(err, database.ErrIdempotencyKeyReused):
+        problem(c, http.StatusUnprocessableEntity, "idempotency_key_reused", IdempotencyKeyHeader+" was already used with a different request")
+    case errors.Is(err, database.ErrIdempotencyKeyInProgress):
+        problem(c, http.StatusConflict, "idempotency_key_in_progress", "A request with this "+IdempotencyKeyHeader+" is still in progress, retry later")
+    default:
+        storeError(c, err, "running idempotent request")
+    }
+}
@@ -0,0 +1,177 @@
+package handlers
+
+import (
+    "encoding/base64"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "strconv"
+    "time"
+    "github.com/gin-gonic/gin"
+    "github.com/maazxenon/task-api/database"
+    "github.com/maazxenon/task-api/dates"
+    "github.com/maazxenon/task-api/models"
+)
+
+// TaskListResponse is one page of tasks
+type TaskListResponse struct {
+    Tasks []models.Task `json:"tasks"`
+    // NextCursor is passed back as ?cursor= to fetch the next page; it is omitted on the last page
+    NextCursor string `json:"next_cursor,omitempty" example:"eyJzIjoiaWQiLCJ2IjoiIiwiaWQiOjUwfQ"`
+}
+
+// cursorToken is the payload of the opaque cursor handed to clients. It records the
+// ordering it was issued for so it can't be replayed against a different sort.
+type cursorToken struct {
+    Sort string `json:"s"`
+    Desc bool   `json:"d,omitempty"`
+    database.Cursor
+}
+
+var errInvalidCursor = errors.New("invalid cursor")
+
+func encodeCursor(token cursorToken) string {
+    b, _ := json.Marshal(token)
+    return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeCursor(s string) (cursorToken, error) {
+    var token cursorToken
+    b, err := base64.RawURLEncoding.DecodeString(s)
+    if err != nil {
+        return token, errInvalidCursor
+    }
+    if err := json.Unmarshal(b, &token); err != nil {
+        return token, errInvalidCursor
+    }
+    return token, nil
+}
+
+// parseTaskFilter reads the filtering, sorting and pagination query parameters of GET /tasks
+func parseTaskFilter(c *gin.Context) (database.TaskFilter, error) {
+    filter := database.TaskFilter{
+        Status:    c.Query("status"),
+        DueAfter:  c.Query("due_after"),
+        DueBefore: c.Query("due_before"),
+        Title:     c.Query("title"),
+        TagsAny:   splitTags(c.QueryArray("tags_any")),
+        TagsAll:   splitTags(c.QueryArray("tags_all")),
+        Sort:      c.DefaultQuery("sort", "id"),
+        Limit:     database.DefaultPageSize,
+    }
+
+    if filter.Status != "" && !isValidStatus(filter.Status) {
+        return filter, fmt.Errorf("invalid status %q", filter.Status)
+    }
+
+    if projectID := c.Query("project_id"); projectID != "" {
+        id, err := strconv.Atoi(projectID)
+        if err != nil {
+            return filter, fmt.Errorf("invalid project_id %q", projectID)
+        }
+        filter.ProjectID = &id
+    }
+
+    switch assignee := c.Query("assignee"); assignee {
+    case "":
+    case "none":
+        filter.Unassigned = true
+    case "me":
+        if filter.AssigneeID = actingUser(c); filter.AssigneeID == nil {
+            return filter, errors.New("assignee=me requires an acting user")
+        }
+    default:
+        id, err := strconv.Atoi(assignee)
+        if err != nil {
+            return filter, fmt.Errorf("invalid assignee %q, expected a user ID, me or none", assignee)
+        }
+        filter.AssigneeID = &id
+    }
+
+    if archived := c.Query("include_archived"); archived != "" {
+        include, err := strconv.ParseBool(archived)
+        if err != nil {
+            return filter, errors.New("include_archived must be a boolean")
+        }
+        filter.IncludeArchived = include
+    }
+
+    loc := clientLocation(c)
+    var err error
+    if filter.DueAfter, err = dueBound(filter.DueAfter, loc, false); err != nil {
+        return filter, errors.New("due_after " + err.Error())
+    }
+    if filter.DueBefore, err = dueBound(filter.DueBefore, loc, true); err != nil {
+        return filter, errors.New("due_before " + err.Error())
+    }
+
+    if _, ok := database.SortFields[filter.Sort]; !ok {
+        return filter, fmt.Errorf("invalid sort field %q", filter.Sort)
+    }
+
+    switch order := c.DefaultQuery("order", "asc"); order {
+    case "asc":
+    case "desc":
+        filter.Desc = true
+    default:
+        return filter, fmt.Errorf("invalid order %q, expected asc or desc", order)
+    }
+
+    if limit := c.Query("limit"); limit != "" {
+        n, err := strconv.Atoi(limit)
+        if err != nil || n < 1 || n > database.MaxPageSize {
+            return filter, fmt.Errorf("limit must be between 1 and %d", database.MaxPageSize)
+        }
+        filter.Limit = n
+    }
+
+    if cursor := c.Query("cursor"); cursor != "" {
+        token, err := decodeCursor(cursor)
+        if err != nil {
+            return filter, err
+        }
+        if token.Sort != filter.Sort || token.Desc != filter.Desc {
+            return filter, errors.New("cursor was issued for a different sort order")
+        }
+        filter.After = &token.Cursor
+    }
+
+    return filter, nil
+}
+
+// dueBound converts a due date filter to its stored form. A date-only upper bound
+// covers the whole day, so due_before=2023-12-31 includes tasks due that evening.
+func dueBound(value string, loc *time.Location, upper bool) (string, error) {
+    if value == "" {
+        return "", nil
+    }
+
+    t, err := dates.Parse(value, loc)
+    if err != nil {
+        return "", err
+    }
+    if upper && len(value) == len(dates.DateLayout) {
+        t = t.AddDate(0, 0, 1).Add(-time.Second)
+    }
+    return dates.Format(t), nil
+}
+
+// setNextLink advertises the next page in a Link header (RFC 8288) and returns its cursor
+func setNextLink(c *gin.Context, filter database.TaskFilter, next *database.Cursor) string {
+    if next == nil {
+        return ""
+    }
+
+    cursor := encodeCursor(cursorToken{Sort: filter.Sort, Desc: filter.Desc, Cursor: *next})
+    setLink(c, cursor)
+    return cursor
+}
+
+// setLink points a Link header at the current URL continued from cursor
+func setLink(c *gin.Context, cursor string) {
+    u := *c.Request.URL
+    q := u.Query()
+    q.Set("cursor", cursor)
+    u.RawQuery = q.Encode()
+    c.Header("Link", fmt.Sprintf(`<%s>; rel="next"`, u.RequestURI()))
+}
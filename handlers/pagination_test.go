@@ -0,0 +1,56 @@
+package handlers
+
+import (
+    "encoding/base64"
+    "errors"
+    "testing"
+    "github.com/maazxenon/task-api/database"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+    tests := []struct {
+        name  string
+        token cursorToken
+    }{
+        {"by id", cursorToken{Sort: "id", Cursor: database.Cursor{ID: 50}}},
+        {"descending", cursorToken{Sort: "due_date", Desc: true, Cursor: database.Cursor{Value: "2024-05-16T09:00:00Z", ID: 7}}},
+        {"empty value", cursorToken{Sort: "due_date", Cursor: database.Cursor{ID: 3}}},
+        {"unicode title", cursorToken{Sort: "title", Cursor: database.Cursor{Value: "Café \"déjà vu\" & more", ID: 12}}},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            encoded := encodeCursor(tt.token)
+            if _, err := base64.RawURLEncoding.DecodeString(encoded); err != nil {
+                t.Fatalf("cursor %q is not URL-safe base64: %v", encoded, err)
+            }
+
+            got, err := decodeCursor(encoded)
+            if err != nil {
+                t.Fatalf("decodeCursor(%q): %v", encoded, err)
+            }
+            if got != tt.token {
+                t.Errorf("decodeCursor = %+v, want %+v", got, tt.token)
+            }
+        })
+    }
+}
+
+func TestDecodeCursorInvalid(t *testing.T) {
+    tests := []struct {
+        name   string
+        cursor string
+    }{
+        {"empty", ""},
+        {"not base64", "not a cursor!"},
+        {"padded", base64.URLEncoding.EncodeToString([]byte(`{"s":"id","id":1}`))},
+        {"not json", base64.RawURLEncoding.EncodeToString([]byte("id=1"))},
+        {"wrong types", base64.RawURLEncoding.EncodeToString([]byte(`{"s":"id","id":"one"}`))},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            if _, err := decodeCursor(tt.cursor); !errors.Is(err, errInvalidCursor) {
+                t.Errorf("decodeCursor(%q) error = %v, want errInvalidCursor", tt.cursor, err)
+            }
+        })
+    }
+}
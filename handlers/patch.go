@@ -0,0 +1,175 @@
+package handlers
+
+import (
+    "bytes"
+    "encoding/json"
+    "errors"
+    "io"
+    "net/http"
+    "strconv"
+    jsonpatch "github.com/evanphx/json-patch/v5"
+    "github.com/gin-gonic/gin"
+    "github.com/gin-gonic/gin/binding"
+    "github.com/maazxenon/task-api/database"
+    "github.com/maazxenon/task-api/models"
+)
+
+// Media types accepted by PatchTaskHandler
+const (
+    mergePatchType = "application/merge-patch+json"
+    jsonPatchType  = "application/json-patch+json"
+)
+
+// PatchTaskHandler applies a partial update to an existing task
+// @Summary Partially update a task
+// @Description Apply a JSON Merge Patch (RFC 7396, application/merge-patch+json) or a JSON Patch (RFC 6902, application/json-patch+json) to a task. The patched task is validated like a PUT body, and completing a recurring task creates its next occurrence.
+// @Tags tasks
+// @Accept application/merge-patch+json,application/json-patch+json
+// @Produce  json
+// @Param id path int true "Task ID"
+// @Param If-Match header string false "ETag the task must still have for the patch to apply"
+// @Param patch body object true "Merge patch document or array of JSON Patch operations"
+// @Param tz query string false "IANA time zone to render due dates in and to read date-only values in; also accepted as the X-Timezone header" default(UTC)
+// @Param ref query string false "RFC 3339 time natural-language due dates are relative to; also accepted as the X-Reference-Time header (default: now)"
+// @Success 200 {object} models.Task
+// @Header 200 {string} ETag "New revision of the task"
+// @Failure 400 {object} Problem "Bad Request"
+// @Failure 404 {object} Problem "Not Found"
+// @Failure 409 {object} Problem "A JSON Patch test operation failed, the task changed while being patched or the new parent would create a cycle"
+// @Failure 412 {object} Problem "Precondition Failed"
+// @Failure 403 {object} Problem "Your role doesn't allow updating this task, or completing it would exceed the task quota"
+// @Failure 415 {object} Problem "Unsupported Media Type"
+// @Failure 422 {object} Problem "The patch could not be applied"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /tasks/{id} [patch]
+func (h *Handler) PatchTaskHandler(c *gin.Context) {
+    id, err := strconv.Atoi(c.Param("id"))
+    if err != nil {
+        problem(c, http.StatusBadRequest, "invalid_id", "Invalid task ID")
+        return
+    }
+
+    contentType := c.ContentType()
+    if contentType != mergePatchType && contentType != jsonPatchType {
+        c.Header("Accept-Patch", mergePatchType+", "+jsonPatchType)
+        problem(c, http.StatusUnsupportedMediaType, "unsupported_media_type", "Content-Type must be "+mergePatchType+" or "+jsonPatchType)
+        return
+    }
+
+    body, err := io.ReadAll(c.Request.Body)
+    if err != nil {
+        problem(c, http.StatusBadRequest, "malformed_body", err.Error())
+        return
+    }
+
+    ctx := c.Request.Context()
+    current, err := h.store.Get(ctx, id)
+    if err != nil {
+        storeError(c, err, "querying task")
+        return
+    }
+
+    ifMatch := c.GetHeader("If-Match")
+    if ifMatch != "" && !etagMatches(ifMatch, etag(current), false) {
+        preconditionFailed(c, current)
+        return
+    }
+
+    if !authorizeTaskChange(c, current, updateTask) {
+        return
+    }
+
+    task, status, err := applyPatch(current, contentType, body)
+    if err != nil {
+        switch status {
+        case http.StatusBadRequest:
+            problem(c, status, "malformed_body", err.Error())
+        case http.StatusConflict:
+            problem(c, status, "patch_test_failed", err.Error())
+        case http.StatusUnprocessableEntity:
+            problem(c, status, "patch_failed", err.Error())
+        default:
+            storeError(c, err, "applying patch")
+        }
+        return
+    }
+
+    if !resolveDueDate(c, &task) {
+        return
+    }
+
+    // Validate the patched task the same way PUT validates its body
+    if err := binding.Validator.ValidateStruct(&task); err != nil {
+        validationError(c, err)
+        return
+    }
+    if err := h.validateTask(ctx, task); err != nil {
+        validationError(c, err)
+        return
+    }
+
+    // The patch was computed against current, so only write it if nobody changed the task since
+    task.UpdatedBy = actingUser(c)
+    task, err = h.store.Update(ctx, task, current.Version)
+    if err != nil {
+        if errors.Is(err, database.ErrVersionConflict) && ifMatch == "" {
+            problem(c, http.StatusConflict, "version_conflict", "Task was modified while the patch was applied, retry the request")
+        } else {
+            storeError(c, err, "updating task")
+        }
+        return
+    }
+
+    c.Header("ETag", etag(task))
+    localizeTask(c, &task)
+    c.JSON(http.StatusOK, task)
+}
+
+// applyPatch applies a patch document of the given media type to task. On failure it
+// also returns the HTTP status that describes the problem.
+func applyPatch(task models.Task, contentType string, patch []byte) (models.Task, int, error) {
+    doc, err := json.Marshal(task)
+    if err != nil {
+        return task, http.StatusInternalServerError, err
+    }
+
+    var patched []byte
+    switch contentType {
+    case mergePatchType:
+        if !json.Valid(patch) || !bytes.HasPrefix(bytes.TrimSpace(patch), []byte("{")) {
+            return task, http.StatusBadRequest, errors.New("merge patch must be a JSON object")
+        }
+        patched, err = jsonpatch.MergePatch(doc, patch)
+        if err != nil {
+            return task, http.StatusBadRequest, err
+        }
+    case jsonPatchType:
+        ops, err := jsonpatch.DecodePatch(patch)
+        if err != nil {
+            return task, http.StatusBadRequest, err
+        }
+        patched, err = ops.Apply(doc)
+        if errors.Is(err, jsonpatch.ErrTestFailed) {
+            return task, http.StatusConflict, err
+        }
+        if err != nil {
+            return task, http.StatusUnprocessableEntity, err
+        }
+    }
+
+    var result models.Task
+    dec := json.NewDecoder(bytes.NewReader(patched))
+    dec.DisallowUnknownFields()
+    if err := dec.Decode(&result); err != nil {
+        return task, http.StatusUnprocessableEntity, err
+    }
+
+    if result.ID != task.ID {
+        return task, http.StatusUnprocessableEntity, errors.New("id cannot be changed")
+    }
+    result.Version = task.Version
+
+    return result, 0, nil
+}
@@ -0,0 +1,153 @@
+package handlers
+
+import (
+    "net/http"
+    "reflect"
+    "testing"
+    "github.com/maazxenon/task-api/models"
+)
+
+func TestApplyPatch(t *testing.T) {
+    project := 2
+    current := models.Task{
+        ID:        1,
+        Title:     "Write tests",
+        DueDate:   "2024-05-16T09:00:00Z",
+        Status:    "pending",
+        Version:   3,
+        ProjectID: &project,
+        Tags:      []string{"go"},
+    }
+    with := func(change func(task *models.Task)) models.Task {
+        task := current
+        task.Tags = append([]string(nil), current.Tags...)
+        change(&task)
+        return task
+    }
+    assignee := 7
+
+    tests := []struct {
+        name        string
+        contentType string
+        patch       string
+        want        models.Task
+        wantStatus  int
+    }{
+        {
+            name:        "merge title",
+            contentType: mergePatchType,
+            patch:       `{"title": "Write more tests"}`,
+            want:        with(func(task *models.Task) { task.Title = "Write more tests" }),
+        },
+        {
+            name:        "merge null clears a field",
+            contentType: mergePatchType,
+            patch:       `{"project_id": null, "due_date": null}`,
+            want:        with(func(task *models.Task) { task.ProjectID, task.DueDate = nil, "" }),
+        },
+        {
+            name:        "merge sets a pointer",
+            contentType: mergePatchType,
+            patch:       `{"assignee_id": 7}`,
+            want:        with(func(task *models.Task) { task.AssigneeID = &assignee }),
+        },
+        {
+            name:        "merge replaces arrays",
+            contentType: mergePatchType,
+            patch:       `{"tags": ["home", "errands"]}`,
+            want:        with(func(task *models.Task) { task.Tags = []string{"home", "errands"} }),
+        },
+        {
+            name:        "version is kept",
+            contentType: mergePatchType,
+            patch:       `{"version": 9, "status": "completed"}`,
+            want:        with(func(task *models.Task) { task.Status = "completed" }),
+        },
+        {
+            name:        "merge patch must be an object",
+            contentType: mergePatchType,
+            patch:       `["title"]`,
+            wantStatus:  http.StatusBadRequest,
+        },
+        {
+            name:        "merge patch must be JSON",
+            contentType: mergePatchType,
+            patch:       `{"title": `,
+            wantStatus:  http.StatusBadRequest,
+        },
+        {
+            name:        "unknown field",
+            contentType: mergePatchType,
+            patch:       `{"colour": "red"}`,
+            wantStatus:  http.StatusUnprocessableEntity,
+        },
+        {
+            name:        "wrong type",
+            contentType: mergePatchType,
+            patch:       `{"title": 5}`,
+            wantStatus:  http.StatusUnprocessableEntity,
+        },
+        {
+            name:        "id cannot change",
+            contentType: mergePatchType,
+            patch:       `{"id": 2}`,
+            wantStatus:  http.StatusUnprocessableEntity,
+        },
+        {
+            name:        "json patch replace",
+            contentType: jsonPatchType,
+            patch:       `[{"op": "replace", "path": "/status", "value": "in progress"}]`,
+            want:        with(func(task *models.Task) { task.Status = "in progress" }),
+        },
+        {
+            name:        "json patch append tag",
+            contentType: jsonPatchType,
+            patch:       `[{"op": "add", "path": "/tags/-", "value": "tests"}]`,
+            want:        with(func(task *models.Task) { task.Tags = []string{"go", "tests"} }),
+        },
+        {
+            name:        "json patch passing test",
+            contentType: jsonPatchType,
+            patch:       `[{"op": "test", "path": "/status", "value": "pending"}, {"op": "replace", "path": "/status", "value": "completed"}]`,
+            want:        with(func(task *models.Task) { task.Status = "completed" }),
+        },
+        {
+            name:        "json patch failing test",
+            contentType: jsonPatchType,
+            patch:       `[{"op": "test", "path": "/status", "value": "completed"}, {"op": "remove", "path": "/due_date"}]`,
+            wantStatus:  http.StatusConflict,
+        },
+        {
+            name:        "json patch missing path",
+            contentType: jsonPatchType,
+            patch:       `[{"op": "remove", "path": "/assignee/name"}]`,
+            wantStatus:  http.StatusUnprocessableEntity,
+        },
+        {
+            name:        "json patch must be an array",
+            contentType: jsonPatchType,
+            patch:       `{"op": "remove", "path": "/title"}`,
+            wantStatus:  http.StatusBadRequest,
+        },
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            got, status, err := applyPatch(current, tt.contentType, []byte(tt.patch))
+            if status != tt.wantStatus {
+                t.Fatalf("applyPatch status = %d (%v), want %d", status, err, tt.wantStatus)
+            }
+            if tt.wantStatus != 0 {
+                if err == nil {
+                    t.Error("applyPatch returned a status without an error")
+                }
+                return
+            }
+            if err != nil {
+                t.Fatalf("applyPatch: %v", err)
+            }
+            if !reflect.DeepEqual(got, tt.want) {
+                t.Errorf("applyPatch = %+v, want %+v", got, tt.want)
+            }
+        })
+    }
+}
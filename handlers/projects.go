@@ -0,0 +1,384 @@
+package handlers
+
+import (
+    "net/http"
+    "strconv"
+    "github.com/gin-gonic/gin"
+    "github.com/maazxenon/task-api/dates"
+    "github.com/maazxenon/task-api/models"
+)
+
+// MoveTasksRequest lists the tasks to move into a project
+type MoveTasksRequest struct {
+    TaskIDs []int `json:"task_ids" example:"1,2" binding:"required,min=1"`
+}
+
+// ListProjectsHandler lists projects
+// @Summary List projects
+// @Description Get all projects ordered by name with their task counts. Archived projects are left out unless include_archived is set.
+// @Tags projects
+// @Produce  json
+// @Param include_archived query bool false "Include archived projects" default(false)
+// @Success 200 {array} models.Project
+// @Failure 400 {object} Problem "Bad Request"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /projects [get]
+func (h *Handler) ListProjectsHandler(c *gin.Context) {
+    includeArchived, err := strconv.ParseBool(c.DefaultQuery("include_archived", "false"))
+    if err != nil {
+        problem(c, http.StatusBadRequest, "invalid_parameter", "include_archived must be a boolean")
+        return
+    }
+
+    projects, err := h.store.Projects(c.Request.Context(), includeArchived)
+    if err != nil {
+        storeError(c, err, "querying projects")
+        return
+    }
+
+    for i := range projects {
+        localizeProject(c, &projects[i])
+    }
+    c.JSON(http.StatusOK, projects)
+}
+
+// GetProjectHandler returns a single project
+// @Summary Get a project
+// @Description Get a project and its task count by ID
+// @Tags projects
+// @Produce  json
+// @Param id path int true "Project ID"
+// @Success 200 {object} models.Project
+// @Failure 400 {object} Problem "Bad Request"
+// @Failure 404 {object} Problem "Not Found"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /projects/{id} [get]
+func (h *Handler) GetProjectHandler(c *gin.Context) {
+    id, ok := projectID(c)
+    if !ok {
+        return
+    }
+
+    project, err := h.store.GetProject(c.Request.Context(), id)
+    if err != nil {
+        storeError(c, err, "querying project")
+        return
+    }
+
+    localizeProject(c, &project)
+    c.JSON(http.StatusOK, project)
+}
+
+// CreateProjectHandler creates a project
+// @Summary Create a project
+// @Description Create a project. default_status is given to tasks created in it without a status and defaults to pending.
+// @Tags projects
+// @Accept  json
+// @Produce  json
+// @Param project body models.Project true "Project"
+// @Success 200 {object} models.Project
+// @Failure 400 {object} Problem "Bad Request"
+// @Failure 403 {object} Problem "Your role doesn't allow this"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /projects [post]
+func (h *Handler) CreateProjectHandler(c *gin.Context) {
+    var project models.Project
+    if !bindProject(c, &project) {
+        return
+    }
+
+    project, err := h.store.CreateProject(c.Request.Context(), project)
+    if err != nil {
+        storeError(c, err, "creating project")
+        return
+    }
+
+    c.JSON(http.StatusOK, project)
+}
+
+// UpdateProjectHandler updates a project
+// @Summary Update a project
+// @Description Update the name, description and default status of a project. Use the archive endpoints to change its archived state.
+// @Tags projects
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Project ID"
+// @Param project body models.Project true "Project"
+// @Success 200 {object} models.Project
+// @Failure 400 {object} Problem "Bad Request"
+// @Failure 403 {object} Problem "Your role doesn't allow this"
+// @Failure 404 {object} Problem "Not Found"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /projects/{id} [put]
+func (h *Handler) UpdateProjectHandler(c *gin.Context) {
+    id, ok := projectID(c)
+    if !ok {
+        return
+    }
+
+    var project models.Project
+    if !bindProject(c, &project) {
+        return
+    }
+    project.ID = id
+
+    project, err := h.store.UpdateProject(c.Request.Context(), project)
+    if err != nil {
+        storeError(c, err, "updating project")
+        return
+    }
+
+    localizeProject(c, &project)
+    c.JSON(http.StatusOK, project)
+}
+
+// DeleteProjectHandler deletes a project
+// @Summary Delete a project
+// @Description Delete a project. Its tasks are kept and no longer belong to any project.
+// @Tags projects
+// @Produce  json
+// @Param id path int true "Project ID"
+// @Success 200 {object} map[string]string "message: Project deleted"
+// @Failure 400 {object} Problem "Bad Request"
+// @Failure 403 {object} Problem "Your role doesn't allow this"
+// @Failure 404 {object} Problem "Not Found"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /projects/{id} [delete]
+func (h *Handler) DeleteProjectHandler(c *gin.Context) {
+    id, ok := projectID(c)
+    if !ok {
+        return
+    }
+
+    if err := h.store.DeleteProject(c.Request.Context(), id); err != nil {
+        storeError(c, err, "deleting project")
+        return
+    }
+
+    c.JSON(http.StatusOK, map[string]string{"message": "Project deleted"})
+}
+
+// ArchiveProjectHandler archives a project with all of its tasks
+// @Summary Archive a project
+// @Description Archive a project together with its tasks. They are left out of task listings, search and planning and can't be updated until the project is unarchived.
+// @Tags projects
+// @Produce  json
+// @Param id path int true "Project ID"
+// @Success 200 {object} models.Project
+// @Failure 400 {object} Problem "Bad Request"
+// @Failure 403 {object} Problem "Your role doesn't allow this"
+// @Failure 404 {object} Problem "Not Found"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /projects/{id}/archive [post]
+func (h *Handler) ArchiveProjectHandler(c *gin.Context) {
+    h.setArchived(c, true)
+}
+
+// UnarchiveProjectHandler restores an archived project with all of its tasks
+// @Summary Unarchive a project
+// @Description Restore an archived project together with its tasks
+// @Tags projects
+// @Produce  json
+// @Param id path int true "Project ID"
+// @Success 200 {object} models.Project
+// @Failure 400 {object} Problem "Bad Request"
+// @Failure 403 {object} Problem "Your role doesn't allow this"
+// @Failure 404 {object} Problem "Not Found"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /projects/{id}/unarchive [post]
+func (h *Handler) UnarchiveProjectHandler(c *gin.Context) {
+    h.setArchived(c, false)
+}
+
+func (h *Handler) setArchived(c *gin.Context, archived bool) {
+    id, ok := projectID(c)
+    if !ok {
+        return
+    }
+
+    project, err := h.store.ArchiveProject(c.Request.Context(), id, archived)
+    if err != nil {
+        storeError(c, err, "archiving project")
+        return
+    }
+
+    localizeProject(c, &project)
+    c.JSON(http.StatusOK, project)
+}
+
+// ProjectTasksHandler lists the tasks of a project
+// @Summary List project tasks
+// @Description Get a page of the tasks in a project, including those of an archived project. Accepts the filtering, sorting and pagination parameters of GET /tasks.
+// @Tags projects
+// @Produce  json
+// @Param id path int true "Project ID"
+// @Param cursor query string false "Cursor returned as next_cursor by the previous page"
+// @Param limit query int false "Page size" minimum(1) maximum(200) default(50)
+// @Success 200 {object} TaskListResponse
+// @Header 200 {string} Link "Link to the next page (rel=next) when another page follows"
+// @Failure 400 {object} Problem "Bad Request"
+// @Failure 404 {object} Problem "Not Found"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /projects/{id}/tasks [get]
+func (h *Handler) ProjectTasksHandler(c *gin.Context) {
+    id, ok := projectID(c)
+    if !ok {
+        return
+    }
+
+    filter, err := parseTaskFilter(c)
+    if err != nil {
+        problem(c, http.StatusBadRequest, "invalid_parameter", err.Error())
+        return
+    }
+    filter.ProjectID = &id
+    filter.IncludeArchived = true
+
+    if _, err := h.store.GetProject(c.Request.Context(), id); err != nil {
+        storeError(c, err, "querying project")
+        return
+    }
+
+    page, err := h.store.List(c.Request.Context(), filter)
+    if err != nil {
+        storeError(c, err, "querying tasks")
+        return
+    }
+
+    localizeTasks(c, page.Tasks)
+    c.JSON(http.StatusOK, TaskListResponse{
+        Tasks:      page.Tasks,
+        NextCursor: setNextLink(c, filter, page.Next),
+    })
+}
+
+// CreateProjectTaskHandler creates a task in a project
+// @Summary Create a task in a project
+// @Description Create a task in the project, which overrides any project_id in the body. Without a status the task gets the project's default status.
+// @Tags projects
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Project ID"
+// @Param task body models.Task true "Task"
+// @Success 200 {object} models.Task
+// @Failure 400 {object} Problem "Bad Request"
+// @Failure 403 {object} Problem "Your role doesn't allow this"
+// @Failure 404 {object} Problem "Not Found"
+// @Failure 409 {object} Problem "The project is archived"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /projects/{id}/tasks [post]
+func (h *Handler) CreateProjectTaskHandler(c *gin.Context) {
+    id, ok := projectID(c)
+    if !ok {
+        return
+    }
+
+    var task models.Task
+    if err := c.ShouldBindJSON(&task); err != nil {
+        bindError(c, err)
+        return
+    }
+
+    if _, err := h.store.GetProject(c.Request.Context(), id); err != nil {
+        storeError(c, err, "querying project")
+        return
+    }
+    task.ProjectID = &id
+
+    h.createTask(c, task)
+}
+
+// MoveTasksHandler moves tasks into a project
+// @Summary Move tasks into a project
+// @Description Move tasks from wherever they are into this project. All tasks are moved or none is.
+// @Tags projects
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Project ID"
+// @Param tasks body MoveTasksRequest true "Tasks to move"
+// @Success 200 {array} models.Task
+// @Failure 400 {object} Problem "Bad Request"
+// @Failure 403 {object} Problem "Your role doesn't allow updating one of the tasks"
+// @Failure 404 {object} Problem "Not Found"
+// @Failure 409 {object} Problem "The project or a task's current project is archived"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /projects/{id}/tasks/move [post]
+func (h *Handler) MoveTasksHandler(c *gin.Context) {
+    id, ok := projectID(c)
+    if !ok {
+        return
+    }
+
+    var req MoveTasksRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        bindError(c, err)
+        return
+    }
+
+    if _, err := h.store.GetProject(c.Request.Context(), id); err != nil {
+        storeError(c, err, "querying project")
+        return
+    }
+    for _, taskID := range req.TaskIDs {
+        if !h.authorizeTask(c, taskID, updateTask) {
+            return
+        }
+    }
+
+    tasks, err := h.store.MoveTasks(c.Request.Context(), &id, req.TaskIDs)
+    if err != nil {
+        storeError(c, err, "moving tasks")
+        return
+    }
+
+    localizeTasks(c, tasks)
+    c.JSON(http.StatusOK, tasks)
+}
+
+// projectID reads the project ID path parameter, answering the request if it is invalid
+func projectID(c *gin.Context) (int, bool) {
+    id, err := strconv.Atoi(c.Param("id"))
+    if err != nil {
+        problem(c, http.StatusBadRequest, "invalid_id", "Invalid project ID")
+        return 0, false
+    }
+    return id, true
+}
+
+// bindProject reads and validates a project from the request body, answering the request on failure
+func bindProject(c *gin.Context, project *models.Project) bool {
+    if err := c.ShouldBindJSON(project); err != nil {
+        bindError(c, err)
+        return false
+    }
+    if err := validate.Struct(project); err != nil {
+        validationError(c, err)
+        return false
+    }
+    return true
+}
+
+// localizeProject renders the archive time of project in the client's time zone
+func localizeProject(c *gin.Context, project *models.Project) {
+    project.ArchivedAt = dates.Render(project.ArchivedAt, clientLocation(c))
+}
@@ -0,0 +1,54 @@
+package handlers
+
+import (
+    "net/http"
+    "strconv"
+    "sync"
+    "time"
+    "github.com/gin-gonic/gin"
+)
+
+// rateWindow counts the requests of one client in the current window
+type rateWindow struct {
+    start time.Time
+    count int
+}
+
+// RateLimit lets each client IP make limit requests per window and answers 429 beyond that.
+// A limit of 0 lets every request through. Counts are kept in memory, per instance.
+func RateLimit(limit int, window time.Duration) gin.HandlerFunc {
+    var mu sync.Mutex
+    windows := map[string]*rateWindow{}
+    return func(c *gin.Context) {
+        if limit <= 0 {
+            c.Next()
+            return
+        }
+
+        now := time.Now()
+        mu.Lock()
+        // Forget finished windows now and then so idle clients don't pile up
+        if len(windows) >= 1024 {
+            for ip, w := range windows {
+                if now.Sub(w.start) >= window {
+                    delete(windows, ip)
+                }
+            }
+        }
+        w, ok := windows[c.ClientIP()]
+        if !ok || now.Sub(w.start) >= window {
+            w = &rateWindow{start: now}
+            windows[c.ClientIP()] = w
+        }
+        w.count++
+        count, reset := w.count, w.start.Add(window)
+        mu.Unlock()
+
+        if count > limit {
+            c.Header("Retry-After", strconv.Itoa(int(reset.Sub(now).Seconds())+1))
+            abortProblem(c, http.StatusTooManyRequests, "rate_limited", "Too many requests; retry after "+reset.UTC().Format(time.RFC3339))
+            return
+        }
+        c.Next()
+    }
+}
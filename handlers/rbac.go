@@ -0,0 +1,131 @@
+package handlers
+
+import (
+    "errors"
+    "net/http"
+    "github.com/gin-gonic/gin"
+    "github.com/maazxenon/task-api/auth"
+    "github.com/maazxenon/task-api/database"
+    "github.com/maazxenon/task-api/models"
+)
+
+// roleKey is the gin.Context key holding the role of the authenticated user
+const roleKey = "role"
+
+// RoleRequest holds the new role of a user
+type RoleRequest struct {
+    Role string `json:"role" example:"viewer" enums:"viewer,member,admin" binding:"required,oneof=viewer member admin"`
+}
+
+// loadRole looks up the role of the authenticated user, answering the request if the user
+// no longer exists
+func (h *Handler) loadRole(c *gin.Context, userID int) bool {
+    user, err := h.store.GetUser(c.Request.Context(), userID)
+    if errors.Is(err, database.ErrUserNotFound) {
+        unauthorized(c, "User no longer exists")
+        return false
+    }
+    if err != nil {
+        storeError(c, err, "querying user")
+        c.Abort()
+        return false
+    }
+
+    c.Set(roleKey, user.Role)
+    return true
+}
+
+// Require rejects requests from users whose role doesn't grant perm
+func Require(perm auth.Permission) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        if !can(c, perm) {
+            forbidden(c, "Your role doesn't allow "+string(perm))
+            return
+        }
+        c.Next()
+    }
+}
+
+// can reports whether the role of the authenticated user grants perm
+func can(c *gin.Context, perm auth.Permission) bool {
+    return auth.Can(c.GetString(roleKey), perm)
+}
+
+// taskAction is a change to an existing task that depends on who owns it
+type taskAction struct {
+    verb string
+    any  auth.Permission
+    own  auth.Permission
+    // assigneeOwns lets the assignee act as an owner besides the creator
+    assigneeOwns bool
+}
+
+var (
+    updateTask = taskAction{verb: "update", any: auth.UpdateAnyTask, own: auth.UpdateOwnTasks, assigneeOwns: true}
+    deleteTask = taskAction{verb: "delete", any: auth.DeleteAnyTask, own: auth.DeleteOwnTasks}
+    // restoring undoes a delete, so it takes the same permissions
+    restoreTask = taskAction{verb: "restore", any: auth.DeleteAnyTask, own: auth.DeleteOwnTasks}
+)
+
+// authorizeTask loads task id and checks that the caller may apply action to it, answering
+// the request otherwise
+func (h *Handler) authorizeTask(c *gin.Context, id int, action taskAction) bool {
+    task, err := h.store.Get(c.Request.Context(), id)
+    if err != nil {
+        storeError(c, err, "querying task")
+        return false
+    }
+    return authorizeTaskChange(c, task, action)
+}
+
+// authorizeDelete checks that the caller may delete task id, and with cascade all of its
+// descendants too
+func (h *Handler) authorizeDelete(c *gin.Context, id int, cascade bool) bool {
+    if !cascade || can(c, deleteTask.any) {
+        return h.authorizeTask(c, id, deleteTask)
+    }
+
+    tasks, err := h.store.Subtree(c.Request.Context(), id)
+    if err != nil {
+        storeError(c, err, "querying task")
+        return false
+    }
+    for _, task := range tasks {
+        if !authorizeTaskChange(c, task, deleteTask) {
+            return false
+        }
+    }
+    return true
+}
+
+// authorizeTaskChange checks that the caller may apply action to task, answering 403 otherwise
+func authorizeTaskChange(c *gin.Context, task models.Task, action taskAction) bool {
+    if can(c, action.any) {
+        return true
+    }
+    if can(c, action.own) && ownsTask(c, task, action.assigneeOwns) {
+        return true
+    }
+
+    message := "You don't have permission to " + action.verb + " this task"
+    if can(c, action.own) {
+        message += "; your role only allows it on tasks you created"
+        if action.assigneeOwns {
+            message += " or are assigned to"
+        }
+    }
+    problem(c, http.StatusForbidden, "forbidden", message)
+    return false
+}
+
+// ownsTask reports whether the caller created task, or is assigned to it if assignee is set
+func ownsTask(c *gin.Context, task models.Task, assignee bool) bool {
+    user := actingUser(c)
+    if user == nil {
+        return false
+    }
+    if task.CreatedBy != nil && *task.CreatedBy == *user {
+        return true
+    }
+    return assignee && task.AssigneeID != nil && *task.AssigneeID == *user
+}
@@ -0,0 +1,66 @@
+package handlers
+
+import (
+    "bytes"
+    "net/http"
+    "github.com/gin-gonic/gin"
+)
+
+// responseRecorder captures a response instead of sending it, so a handler can be run as
+// part of another request
+type responseRecorder struct {
+    // ResponseWriter is nil; the handlers only use the methods implemented below
+    gin.ResponseWriter
+    header http.Header
+    status int
+    body   bytes.Buffer
+}
+
+func newResponseRecorder() *responseRecorder {
+    return &responseRecorder{header: http.Header{}}
+}
+
+func (r *responseRecorder) Header() http.Header {
+    return r.header
+}
+
+func (r *responseRecorder) WriteHeader(code int) {
+    if !r.Written() {
+        r.status = code
+    }
+}
+
+func (r *responseRecorder) WriteHeaderNow() {}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+    return r.body.Write(b)
+}
+
+func (r *responseRecorder) WriteString(s string) (int, error) {
+    return r.body.WriteString(s)
+}
+
+func (r *responseRecorder) Status() int {
+    if r.status == 0 {
+        return http.StatusOK
+    }
+    return r.status
+}
+
+func (r *responseRecorder) Size() int {
+    return r.body.Len()
+}
+
+func (r *responseRecorder) Written() bool {
+    return r.body.Len() > 0
+}
+
+// writeTo sends the recorded response on c
+func (r *responseRecorder) writeTo(c *gin.Context) {
+    for name, values := range r.header {
+        for _, value := range values {
+            c.Writer.Header().Add(name, value)
+        }
+    }
+    c.Data(r.Status(), r.header.Get("Content-Type"), r.body.Bytes())
+}
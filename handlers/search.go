@@ -0,0 +1,80 @@
+package handlers
+
+import (
+    "errors"
+    "fmt"
+    "net/http"
+    "strconv"
+    "github.com/gin-gonic/gin"
+    "github.com/maazxenon/task-api/database"
+    "github.com/maazxenon/task-api/models"
+)
+
+// Bounds on the number of search results per request
+const (
+    defaultSearchLimit = 20
+    maxSearchLimit     = 100
+)
+
+// SearchResponse holds the results of a full-text search
+type SearchResponse struct {
+    Query   string             `json:"query" example:"groc*"`
+    Results []models.TaskMatch `json:"results"`
+}
+
+// SearchHandler runs a full-text search over task titles and descriptions
+// @Summary Search tasks
+// @Description Full-text search over titles and descriptions, ranked by relevance with title matches weighted higher. All terms must match; use "quoted phrases" for exact sequences and a trailing * for prefixes. title_highlight and snippet are HTML-escaped with matches wrapped in <mark> tags, so they can be shown as HTML.
+// @Tags tasks
+// @Produce  json
+// @Param q query string true "Search query, e.g. groc* \"weekly report\""
+// @Param limit query int false "Maximum number of results" minimum(1) maximum(100) default(20)
+// @Param offset query int false "Number of results to skip" minimum(0) default(0)
+// @Success 200 {object} SearchResponse
+// @Failure 400 {object} Problem "Bad Request"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /tasks/search [get]
+func (h *Handler) SearchHandler(c *gin.Context) {
+    q := c.Query("q")
+    if q == "" {
+        problem(c, http.StatusBadRequest, "invalid_parameter", "Query parameter q is required")
+        return
+    }
+
+    limit := defaultSearchLimit
+    if v := c.Query("limit"); v != "" {
+        n, err := strconv.Atoi(v)
+        if err != nil || n < 1 || n > maxSearchLimit {
+            problem(c, http.StatusBadRequest, "invalid_parameter", fmt.Sprintf("limit must be between 1 and %d", maxSearchLimit))
+            return
+        }
+        limit = n
+    }
+
+    offset := 0
+    if v := c.Query("offset"); v != "" {
+        n, err := strconv.Atoi(v)
+        if err != nil || n < 0 {
+            problem(c, http.StatusBadRequest, "invalid_parameter", "offset must be a non-negative integer")
+            return
+        }
+        offset = n
+    }
+
+    results, err := h.store.Search(c.Request.Context(), q, limit, offset)
+    if err != nil {
+        if errors.Is(err, database.ErrEmptyQuery) {
+            problem(c, http.StatusBadRequest, "invalid_parameter", err.Error())
+        } else {
+            storeError(c, err, "searching tasks")
+        }
+        return
+    }
+
+    for i := range results {
+        localizeTask(c, &results[i].Task)
+    }
+    c.JSON(http.StatusOK, SearchResponse{Query: q, Results: results})
+}
@@ -0,0 +1,143 @@
+package handlers
+
+import (
+    "context"
+    "sort"
+    "github.com/maazxenon/task-api/database"
+    "github.com/maazxenon/task-api/models"
+)
+
+// fakeStore keeps tasks in memory for handler tests. Only the TaskStore methods are
+// implemented; the rest of database.Store is embedded as nil, so a handler reaching for
+// them panics and points at the method the fake is missing.
+type fakeStore struct {
+    database.Store
+    tasks  map[int]models.Task
+    nextID int
+    // blockers maps a task to the tasks it is blocked by
+    blockers map[int][]int
+}
+
+// newFakeStore returns a store holding tasks; tasks without a version start at 1
+func newFakeStore(tasks ...models.Task) *fakeStore {
+    s := &fakeStore{tasks: map[int]models.Task{}, blockers: map[int][]int{}, nextID: 1}
+    for _, task := range tasks {
+        if task.Version == 0 {
+            task.Version = 1
+        }
+        s.tasks[task.ID] = task
+        if task.ID >= s.nextID {
+            s.nextID = task.ID + 1
+        }
+    }
+    return s
+}
+
+// sorted returns the tasks accepted by keep ordered by ID
+func (s *fakeStore) sorted(keep func(models.Task) bool) []models.Task {
+    tasks := []models.Task{}
+    for _, task := range s.tasks {
+        if keep(task) {
+            tasks = append(tasks, task)
+        }
+    }
+    sort.Slice(tasks, func(i, j int) bool { return tasks[i].ID < tasks[j].ID })
+    return tasks
+}
+
+func (s *fakeStore) List(ctx context.Context, filter database.TaskFilter) (database.TaskPage, error) {
+    tasks := s.sorted(func(task models.Task) bool {
+        return (filter.Status == "" || task.Status == filter.Status) && (filter.After == nil || task.ID > filter.After.ID)
+    })
+    var page database.TaskPage
+    if filter.Limit > 0 && len(tasks) > filter.Limit {
+        tasks = tasks[:filter.Limit]
+        page.Next = &database.Cursor{ID: tasks[len(tasks)-1].ID}
+    }
+    page.Tasks = tasks
+    return page, nil
+}
+
+func (s *fakeStore) Get(ctx context.Context, id int) (models.Task, error) {
+    task, ok := s.tasks[id]
+    if !ok {
+        return models.Task{}, database.ErrTaskNotFound
+    }
+    return task, nil
+}
+
+func (s *fakeStore) Create(ctx context.Context, task models.Task) (models.Task, error) {
+    task.ID, task.Version = s.nextID, 1
+    s.nextID++
+    s.tasks[task.ID] = task
+    return task, nil
+}
+
+func (s *fakeStore) Update(ctx context.Context, task models.Task, version int) (models.Task, error) {
+    stored, ok := s.tasks[task.ID]
+    if !ok {
+        return models.Task{}, database.ErrTaskNotFound
+    }
+    if version != 0 && version != stored.Version {
+        return models.Task{}, database.ErrVersionConflict
+    }
+    task.Version = stored.Version + 1
+    task.CreatedBy = stored.CreatedBy
+    s.tasks[task.ID] = task
+    return task, nil
+}
+
+func (s *fakeStore) Delete(ctx context.Context, id int, opts database.DeleteOptions) error {
+    stored, ok := s.tasks[id]
+    if !ok {
+        return database.ErrTaskNotFound
+    }
+    if opts.Version != 0 && opts.Version != stored.Version {
+        return database.ErrVersionConflict
+    }
+
+    subtree, _ := s.Subtree(ctx, id)
+    for _, task := range subtree[1:] {
+        if opts.Cascade {
+            delete(s.tasks, task.ID)
+        } else if *task.ParentID == id {
+            task.ParentID = nil
+            s.tasks[task.ID] = task
+        }
+    }
+    delete(s.tasks, id)
+    return nil
+}
+
+func (s *fakeStore) Children(ctx context.Context, id int) ([]models.Task, error) {
+    if _, ok := s.tasks[id]; !ok {
+        return nil, database.ErrTaskNotFound
+    }
+    return s.sorted(func(task models.Task) bool {
+        return task.ParentID != nil && *task.ParentID == id
+    }), nil
+}
+
+func (s *fakeStore) Subtree(ctx context.Context, id int) ([]models.Task, error) {
+    root, err := s.Get(ctx, id)
+    if err != nil {
+        return nil, err
+    }
+    tasks := []models.Task{root}
+    for i := 0; i < len(tasks); i++ {
+        children, _ := s.Children(ctx, tasks[i].ID)
+        tasks = append(tasks, children...)
+    }
+    return tasks, nil
+}
+
+func (s *fakeStore) Blockers(ctx context.Context, id int) ([]models.Task, error) {
+    if _, ok := s.tasks[id]; !ok {
+        return nil, database.ErrTaskNotFound
+    }
+    var tasks []models.Task
+    for _, blockerID := range s.blockers[id] {
+        tasks = append(tasks, s.tasks[blockerID])
+    }
+    return tasks, nil
+}
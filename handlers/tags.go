@@ -0,0 +1,193 @@
+package handlers
+
+import (
+    "net/http"
+    "strconv"
+    "strings"
+    "github.com/gin-gonic/gin"
+    "github.com/go-playground/validator/v10"
+    "github.com/maazxenon/task-api/models"
+)
+
+// maxTagLength bounds the length of a tag name
+const maxTagLength = 50
+
+// validateTagName is a custom validation function for tag names. Commas are reserved
+// as the separator of the tags_any and tags_all filters.
+func validateTagName(fl validator.FieldLevel) bool {
+    name := strings.TrimSpace(fl.Field().String())
+    return name != "" && len(name) <= maxTagLength && !strings.Contains(name, ",")
+}
+
+// splitTags reads tag filter values given either comma separated or as repeated parameters
+func splitTags(values []string) []string {
+    var tags []string
+    for _, value := range values {
+        for _, tag := range strings.Split(value, ",") {
+            if tag = strings.TrimSpace(tag); tag != "" {
+                tags = append(tags, tag)
+            }
+        }
+    }
+    return tags
+}
+
+// ListTagsHandler lists all tags with their task counts
+// @Summary List tags
+// @Description Get all tags ordered by name, with the number of tasks carrying each
+// @Tags tags
+// @Produce  json
+// @Success 200 {array} models.Tag
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /tags [get]
+func (h *Handler) ListTagsHandler(c *gin.Context) {
+    tags, err := h.store.Tags(c.Request.Context())
+    if err != nil {
+        storeError(c, err, "querying tags")
+        return
+    }
+
+    c.JSON(http.StatusOK, tags)
+}
+
+// GetTagHandler returns a single tag
+// @Summary Get a tag
+// @Description Get a tag and its task count by ID
+// @Tags tags
+// @Produce  json
+// @Param id path int true "Tag ID"
+// @Success 200 {object} models.Tag
+// @Failure 400 {object} Problem "Bad Request"
+// @Failure 404 {object} Problem "Not Found"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /tags/{id} [get]
+func (h *Handler) GetTagHandler(c *gin.Context) {
+    id, err := strconv.Atoi(c.Param("id"))
+    if err != nil {
+        problem(c, http.StatusBadRequest, "invalid_id", "Invalid tag ID")
+        return
+    }
+
+    tag, err := h.store.GetTag(c.Request.Context(), id)
+    if err != nil {
+        storeError(c, err, "querying tag")
+        return
+    }
+
+    c.JSON(http.StatusOK, tag)
+}
+
+// CreateTagHandler creates a tag
+// @Summary Create a tag
+// @Description Create a tag. Names are trimmed and lower-cased, must be unique and can't contain commas.
+// @Tags tags
+// @Accept  json
+// @Produce  json
+// @Param tag body models.Tag true "Tag"
+// @Success 200 {object} models.Tag
+// @Failure 400 {object} Problem "Bad Request"
+// @Failure 403 {object} Problem "Your role doesn't allow this"
+// @Failure 409 {object} Problem "A tag with this name already exists"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /tags [post]
+func (h *Handler) CreateTagHandler(c *gin.Context) {
+    var tag models.Tag
+    if !bindTag(c, &tag) {
+        return
+    }
+
+    tag, err := h.store.CreateTag(c.Request.Context(), tag)
+    if err != nil {
+        storeError(c, err, "creating tag")
+        return
+    }
+
+    c.JSON(http.StatusOK, tag)
+}
+
+// UpdateTagHandler renames a tag
+// @Summary Rename a tag
+// @Description Rename a tag; every task carrying it shows the new name
+// @Tags tags
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Tag ID"
+// @Param tag body models.Tag true "Tag"
+// @Success 200 {object} models.Tag
+// @Failure 400 {object} Problem "Bad Request"
+// @Failure 403 {object} Problem "Your role doesn't allow this"
+// @Failure 404 {object} Problem "Not Found"
+// @Failure 409 {object} Problem "A tag with this name already exists"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /tags/{id} [put]
+func (h *Handler) UpdateTagHandler(c *gin.Context) {
+    id, err := strconv.Atoi(c.Param("id"))
+    if err != nil {
+        problem(c, http.StatusBadRequest, "invalid_id", "Invalid tag ID")
+        return
+    }
+
+    var tag models.Tag
+    if !bindTag(c, &tag) {
+        return
+    }
+    tag.ID = id
+
+    tag, err = h.store.UpdateTag(c.Request.Context(), tag)
+    if err != nil {
+        storeError(c, err, "updating tag")
+        return
+    }
+
+    c.JSON(http.StatusOK, tag)
+}
+
+// DeleteTagHandler deletes a tag
+// @Summary Delete a tag
+// @Description Delete a tag and remove it from every task carrying it
+// @Tags tags
+// @Produce  json
+// @Param id path int true "Tag ID"
+// @Success 200 {object} map[string]string "message: Tag deleted"
+// @Failure 400 {object} Problem "Bad Request"
+// @Failure 403 {object} Problem "Your role doesn't allow this"
+// @Failure 404 {object} Problem "Not Found"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /tags/{id} [delete]
+func (h *Handler) DeleteTagHandler(c *gin.Context) {
+    id, err := strconv.Atoi(c.Param("id"))
+    if err != nil {
+        problem(c, http.StatusBadRequest, "invalid_id", "Invalid tag ID")
+        return
+    }
+
+    if err := h.store.DeleteTag(c.Request.Context(), id); err != nil {
+        storeError(c, err, "deleting tag")
+        return
+    }
+
+    c.JSON(http.StatusOK, map[string]string{"message": "Tag deleted"})
+}
+
+// bindTag reads and validates a tag from the request body, answering the request on failure
+func bindTag(c *gin.Context, tag *models.Tag) bool {
+    if err := c.ShouldBindJSON(tag); err != nil {
+        bindError(c, err)
+        return false
+    }
+    if err := validate.Struct(tag); err != nil {
+        validationError(c, err)
+        return false
+    }
+    return true
+}
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+    "net/http"
+    "strconv"
+    "github.com/gin-gonic/gin"
+)
+
+// TrashHandler lists the trashed tasks
+// @Summary List the trash
+// @Description Get the deleted tasks of the workspace that haven't been purged yet, most recently deleted first. Trashed tasks are purged for good once they have been in the trash for the configured retention period.
+// @Tags trash
+// @Produce  json
+// @Param tz query string false "IANA time zone to render timestamps in; also accepted as the X-Timezone header" default(UTC)
+// @Success 200 {array} models.Task
+// @Failure 401 {object} Problem "Unauthorized"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /trash [get]
+func (h *Handler) TrashHandler(c *gin.Context) {
+    tasks, err := h.store.Trash(c.Request.Context())
+    if err != nil {
+        storeError(c, err, "querying trash")
+        return
+    }
+
+    localizeTasks(c, tasks)
+    c.JSON(http.StatusOK, tasks)
+}
+
+// RestoreHandler takes a task out of the trash
+// @Summary Restore a task
+// @Description Restore a deleted task together with the subtasks deleted along with it. If its parent is still in the trash it becomes a top-level task. Members may only restore tasks they created, including every subtask that would come back with it; admins may restore any task.
+// @Tags trash
+// @Produce  json
+// @Param id path int true "Task ID"
+// @Param tz query string false "IANA time zone to render timestamps in; also accepted as the X-Timezone header" default(UTC)
+// @Success 200 {object} models.Task
+// @Header 200 {string} ETag "Version of the task, for If-Match"
+// @Failure 400 {object} Problem "Bad Request"
+// @Failure 401 {object} Problem "Unauthorized"
+// @Failure 403 {object} Problem "Your role doesn't allow restoring this task"
+// @Failure 404 {object} Problem "The task isn't in the trash"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /tasks/{id}/restore [post]
+func (h *Handler) RestoreHandler(c *gin.Context) {
+    id, err := strconv.Atoi(c.Param("id"))
+    if err != nil {
+        problem(c, http.StatusBadRequest, "invalid_id", "Invalid task ID")
+        return
+    }
+
+    // Subtasks deleted along with the task come back too, so each must be the caller's to restore
+    trashed, err := h.store.TrashedSubtree(c.Request.Context(), id)
+    if err != nil {
+        storeError(c, err, "querying trash")
+        return
+    }
+    for _, task := range trashed {
+        if !authorizeTaskChange(c, task, restoreTask) {
+            return
+        }
+    }
+
+    task, err := h.store.Restore(c.Request.Context(), id)
+    if err != nil {
+        storeError(c, err, "restoring task")
+        return
+    }
+
+    c.Header("ETag", etag(task))
+    localizeTask(c, &task)
+    c.JSON(http.StatusOK, task)
+}
@@ -0,0 +1,322 @@
+package handlers
+
+import (
+    "net/http"
+    "strconv"
+    "strings"
+    "github.com/gin-gonic/gin"
+    "github.com/maazxenon/task-api/auth"
+    "github.com/maazxenon/task-api/models"
+)
+
+// userKey is the gin.Context key holding the ID of the authenticated user
+const userKey = "user_id"
+
+// actingUser returns the ID of the authenticated user, or nil on public routes
+func actingUser(c *gin.Context) *int {
+    id, ok := c.Get(userKey)
+    if !ok {
+        return nil
+    }
+    userID := id.(int)
+    return &userID
+}
+
+// ListUsersHandler lists users
+// @Summary List users
+// @Description Get all users ordered by name
+// @Tags users
+// @Produce  json
+// @Success 200 {array} models.User
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /users [get]
+func (h *Handler) ListUsersHandler(c *gin.Context) {
+    users, err := h.store.Users(c.Request.Context())
+    if err != nil {
+        storeError(c, err, "querying users")
+        return
+    }
+
+    c.JSON(http.StatusOK, users)
+}
+
+// GetUserHandler returns a single user
+// @Summary Get a user
+// @Description Get a user by ID
+// @Tags users
+// @Produce  json
+// @Param id path int true "User ID"
+// @Success 200 {object} models.User
+// @Failure 400 {object} Problem "Bad Request"
+// @Failure 404 {object} Problem "Not Found"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /users/{id} [get]
+func (h *Handler) GetUserHandler(c *gin.Context) {
+    id, ok := userID(c)
+    if !ok {
+        return
+    }
+
+    user, err := h.store.GetUser(c.Request.Context(), id)
+    if err != nil {
+        storeError(c, err, "querying user")
+        return
+    }
+
+    c.JSON(http.StatusOK, user)
+}
+
+// CreateUserHandler creates a user
+// @Summary Create a user
+// @Description Add a member to the workspace of the authenticated admin. Emails are case-insensitive and must be unique within the workspace. A password of at least 8 characters is needed to log in; it is never returned. New teams sign up with POST /workspaces instead.
+// @Tags users
+// @Accept  json
+// @Produce  json
+// @Param user body models.User true "User"
+// @Success 200 {object} models.User
+// @Failure 400 {object} Problem "Bad Request"
+// @Failure 401 {object} Problem "Unauthorized"
+// @Failure 403 {object} Problem "Forbidden or user quota reached"
+// @Failure 409 {object} Problem "A user with this email already exists"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /users [post]
+func (h *Handler) CreateUserHandler(c *gin.Context) {
+    var user models.User
+    if err := c.ShouldBindJSON(&user); err != nil {
+        bindError(c, err)
+        return
+    }
+    user.Role = ""
+    if !hashPassword(c, &user) {
+        return
+    }
+
+    user, err := h.store.CreateUser(c.Request.Context(), user)
+    if err != nil {
+        storeError(c, err, "creating user")
+        return
+    }
+
+    c.JSON(http.StatusOK, user)
+}
+
+// UpdateUserHandler updates a user
+// @Summary Update a user
+// @Description Update the name and email of a user, and the password if one is given. Users may update themselves; updating others needs the admin role. Users changing their own email or password must confirm current_password; admins reset the passwords of others without it.
+// @Tags users
+// @Accept  json
+// @Produce  json
+// @Param id path int true "User ID"
+// @Param user body models.User true "User"
+// @Success 200 {object} models.User
+// @Failure 400 {object} Problem "Bad Request"
+// @Failure 403 {object} Problem "Forbidden or current_password missing or wrong"
+// @Failure 404 {object} Problem "Not Found"
+// @Failure 409 {object} Problem "A user with this email already exists"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /users/{id} [put]
+func (h *Handler) UpdateUserHandler(c *gin.Context) {
+    id, ok := userID(c)
+    if !ok {
+        return
+    }
+    if self := actingUser(c); (self == nil || *self != id) && !can(c, auth.ManageUsers) {
+        forbidden(c, "You can only update your own profile")
+        return
+    }
+
+    var user models.User
+    if err := c.ShouldBindJSON(&user); err != nil {
+        bindError(c, err)
+        return
+    }
+    user.ID = id
+    if self := actingUser(c); self != nil && *self == id && !h.confirmPassword(c, user) {
+        return
+    }
+    if !hashPassword(c, &user) {
+        return
+    }
+
+    user, err := h.store.UpdateUser(c.Request.Context(), user)
+    if err != nil {
+        storeError(c, err, "updating user")
+        return
+    }
+
+    c.JSON(http.StatusOK, user)
+}
+
+// DeleteUserHandler deletes a user
+// @Summary Delete a user
+// @Description Delete a user along with their API keys. Their tasks are kept but become unassigned, and creator or last-modifier references to them are cleared. Needs the admin role.
+// @Tags users
+// @Produce  json
+// @Param id path int true "User ID"
+// @Success 200 {object} map[string]string "message: User deleted"
+// @Failure 400 {object} Problem "Bad Request"
+// @Failure 403 {object} Problem "Forbidden"
+// @Failure 404 {object} Problem "Not Found"
+// @Failure 409 {object} Problem "The last admin can't be removed"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /users/{id} [delete]
+func (h *Handler) DeleteUserHandler(c *gin.Context) {
+    id, ok := userID(c)
+    if !ok {
+        return
+    }
+
+    if err := h.store.DeleteUser(c.Request.Context(), id); err != nil {
+        storeError(c, err, "deleting user")
+        return
+    }
+
+    c.JSON(http.StatusOK, map[string]string{"message": "User deleted"})
+}
+
+// SetUserRoleHandler changes the role of a user
+// @Summary Change a user's role
+// @Description Make a user a viewer, who can only read, a member, who can create tasks and change the tasks they created or are assigned to, or an admin, who can change and delete anything and manage users. Needs the admin role.
+// @Tags users
+// @Accept  json
+// @Produce  json
+// @Param id path int true "User ID"
+// @Param role body RoleRequest true "New role"
+// @Success 200 {object} models.User
+// @Failure 400 {object} Problem "Bad Request"
+// @Failure 403 {object} Problem "Forbidden"
+// @Failure 404 {object} Problem "Not Found"
+// @Failure 409 {object} Problem "The last admin can't be demoted"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /users/{id}/role [put]
+func (h *Handler) SetUserRoleHandler(c *gin.Context) {
+    id, ok := userID(c)
+    if !ok {
+        return
+    }
+
+    var req RoleRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        bindError(c, err)
+        return
+    }
+
+    user, err := h.store.SetUserRole(c.Request.Context(), id, req.Role)
+    if err != nil {
+        storeError(c, err, "changing user role")
+        return
+    }
+
+    c.JSON(http.StatusOK, user)
+}
+
+// UserTasksHandler lists the tasks assigned to a user
+// @Summary List a user's tasks
+// @Description Get a page of the tasks assigned to a user. Accepts the filtering, sorting and pagination parameters of GET /tasks.
+// @Tags users
+// @Produce  json
+// @Param id path int true "User ID"
+// @Param cursor query string false "Cursor returned as next_cursor by the previous page"
+// @Param limit query int false "Page size" minimum(1) maximum(200) default(50)
+// @Success 200 {object} TaskListResponse
+// @Header 200 {string} Link "Link to the next page (rel=next) when another page follows"
+// @Failure 400 {object} Problem "Bad Request"
+// @Failure 404 {object} Problem "Not Found"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /users/{id}/tasks [get]
+func (h *Handler) UserTasksHandler(c *gin.Context) {
+    id, ok := userID(c)
+    if !ok {
+        return
+    }
+
+    filter, err := parseTaskFilter(c)
+    if err != nil {
+        problem(c, http.StatusBadRequest, "invalid_parameter", err.Error())
+        return
+    }
+    filter.AssigneeID = &id
+    filter.Unassigned = false
+
+    if _, err := h.store.GetUser(c.Request.Context(), id); err != nil {
+        storeError(c, err, "querying user")
+        return
+    }
+
+    page, err := h.store.List(c.Request.Context(), filter)
+    if err != nil {
+        storeError(c, err, "querying tasks")
+        return
+    }
+
+    localizeTasks(c, page.Tasks)
+    c.JSON(http.StatusOK, TaskListResponse{
+        Tasks:      page.Tasks,
+        NextCursor: setNextLink(c, filter, page.Next),
+    })
+}
+
+// confirmPassword checks the current password of users changing their own credentials, so
+// a stolen access token can't be turned into a permanent takeover of the account. It
+// answers the request when the password is missing or wrong.
+func (h *Handler) confirmPassword(c *gin.Context, update models.User) bool {
+    current, err := h.store.GetUser(c.Request.Context(), update.ID)
+    if err != nil {
+        storeError(c, err, "querying user")
+        return false
+    }
+    if update.Password == "" && strings.EqualFold(strings.TrimSpace(update.Email), current.Email) {
+        return true
+    }
+
+    stored, err := h.store.UserByEmail(c.Request.Context(), current.Email)
+    if err != nil {
+        storeError(c, err, "querying user")
+        return false
+    }
+    if update.CurrentPassword == "" || auth.CheckPassword(stored.PasswordHash, update.CurrentPassword) != nil {
+        problem(c, http.StatusForbidden, "invalid_current_password", "Changing your email or password requires your current password in current_password")
+        return false
+    }
+    return true
+}
+
+// hashPassword replaces the plain password of user with its hash, answering the request on failure
+func hashPassword(c *gin.Context, user *models.User) bool {
+    if user.Password == "" {
+        return true
+    }
+
+    hash, err := auth.HashPassword(user.Password)
+    if err != nil {
+        storeError(c, err, "hashing password")
+        return false
+    }
+    user.PasswordHash = hash
+    user.Password = ""
+    return true
+}
+
+// userID reads the user ID path parameter, answering the request if it is invalid
+func userID(c *gin.Context) (int, bool) {
+    id, err := strconv.Atoi(c.Param("id"))
+    if err != nil {
+        problem(c, http.StatusBadRequest, "invalid_id", "Invalid user ID")
+        return 0, false
+    }
+    return id, true
+}
@@ -0,0 +1,211 @@
+package handlers
+
+import (
+    "errors"
+    "net"
+    "net/http"
+    "regexp"
+    "strings"
+    "github.com/gin-gonic/gin"
+    "github.com/maazxenon/task-api/database"
+    "github.com/maazxenon/task-api/models"
+)
+
+// WorkspaceHeader names the workspace of a request by its slug. A subdomain of the
+// configured workspace domain works too; the header takes precedence.
+const WorkspaceHeader = "X-Workspace"
+
+// DefaultWorkspace is the slug of the workspace holding the data created before workspaces
+// existed. Logins that don't name a workspace use it if it exists.
+const DefaultWorkspace = "default"
+
+// workspaceKey is the gin.Context key holding the ID of the workspace named by the request
+const workspaceKey = "workspace_id"
+
+// slugPattern matches valid workspace slugs, which must also work as a DNS label
+var slugPattern = regexp.MustCompile(`^[a-z0-9](?:[a-z0-9-]{0,38}[a-z0-9])?$`)
+
+// ValidSlug reports whether slug can name a workspace
+func ValidSlug(slug string) bool {
+    return slugPattern.MatchString(slug)
+}
+
+// CreateWorkspaceRequest holds a new workspace and its first user
+type CreateWorkspaceRequest struct {
+    Name string `json:"name" example:"Acme" binding:"required"`
+    // Slug is 1 to 40 lowercase letters, digits and inner hyphens
+    Slug  string      `json:"slug" example:"acme" binding:"required"`
+    Admin models.User `json:"admin"`
+}
+
+// CreateWorkspaceResponse is the created workspace and its admin
+type CreateWorkspaceResponse struct {
+    Workspace models.Workspace `json:"workspace"`
+    Admin     models.User      `json:"admin"`
+}
+
+// ResolveWorkspace reads the workspace named by the X-Workspace header, or by the subdomain
+// of domain the request was sent to, and scopes the request to it. Authenticated routes
+// check it against the workspace of the credentials.
+func (h *Handler) ResolveWorkspace(domain string) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        slug := c.GetHeader(WorkspaceHeader)
+        if slug == "" && domain != "" {
+            slug = subdomain(c.Request.Host, domain)
+        }
+        if slug == "" {
+            c.Next()
+            return
+        }
+
+        workspace, err := h.store.WorkspaceBySlug(c.Request.Context(), slug)
+        if errors.Is(err, database.ErrWorkspaceNotFound) {
+            abortProblem(c, http.StatusNotFound, "workspace_not_found", "Workspace "+slug+" not found")
+            return
+        }
+        if err != nil {
+            storeError(c, err, "querying workspace")
+            c.Abort()
+            return
+        }
+
+        c.Set(workspaceKey, workspace.ID)
+        setWorkspace(c, workspace.ID)
+        c.Next()
+    }
+}
+
+// subdomain returns the label of host in front of domain, or "" if host isn't a direct
+// subdomain of it
+func subdomain(host, domain string) string {
+    if h, _, err := net.SplitHostPort(host); err == nil {
+        host = h
+    }
+    label, ok := strings.CutSuffix(strings.ToLower(host), "."+strings.ToLower(domain))
+    if !ok || strings.Contains(label, ".") {
+        return ""
+    }
+    return label
+}
+
+// setWorkspace scopes the store calls of the request to a workspace
+func setWorkspace(c *gin.Context, id int) {
+    c.Request = c.Request.WithContext(database.WithWorkspace(c.Request.Context(), id))
+}
+
+// claimWorkspace scopes an authenticated request to the workspace of its credentials,
+// answering 403 if the request named another one
+func claimWorkspace(c *gin.Context, id int) bool {
+    if named, ok := c.Get(workspaceKey); ok && named.(int) != id {
+        forbidden(c, "Credentials belong to another workspace")
+        return false
+    }
+    setWorkspace(c, id)
+    return true
+}
+
+// loginWorkspace scopes a login to the workspace named by the request, falling back to the
+// default workspace
+func (h *Handler) loginWorkspace(c *gin.Context) bool {
+    if _, ok := c.Get(workspaceKey); ok {
+        return true
+    }
+
+    workspace, err := h.store.WorkspaceBySlug(c.Request.Context(), DefaultWorkspace)
+    if errors.Is(err, database.ErrWorkspaceNotFound) {
+        problem(c, http.StatusBadRequest, "invalid_header", "Name the workspace with the "+WorkspaceHeader+" header")
+        return false
+    }
+    if err != nil {
+        storeError(c, err, "querying workspace")
+        return false
+    }
+
+    setWorkspace(c, workspace.ID)
+    return true
+}
+
+// Signup guards POST /workspaces. Unless sign-up is open only the first workspace of a new
+// installation can be created through the API.
+func (h *Handler) Signup(open bool) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        if open {
+            c.Next()
+            return
+        }
+        workspaces, err := h.store.Workspaces(c.Request.Context())
+        if err != nil {
+            storeError(c, err, "querying workspaces")
+            c.Abort()
+            return
+        }
+        if len(workspaces) > 0 {
+            abortProblem(c, http.StatusForbidden, "signup_disabled", "Sign-up is closed; ask the operator to create your workspace")
+            return
+        }
+        c.Next()
+    }
+}
+
+// CreateWorkspaceHandler creates a workspace
+// @Summary Create a workspace
+// @Description Sign up a team: create a workspace together with its first user, who becomes its admin and can add further users. Slugs are 1 to 40 lowercase letters, digits and inner hyphens, unique across workspaces. New workspaces get default quotas of 50 users, 100 projects and 10000 tasks. Doesn't require authentication, but unless the server enables open sign-up only the first workspace can be created this way, and each client IP may only try a few times per hour.
+// @Tags workspaces
+// @Accept  json
+// @Produce  json
+// @Param workspace body CreateWorkspaceRequest true "Workspace"
+// @Success 201 {object} CreateWorkspaceResponse
+// @Failure 400 {object} Problem "Bad Request"
+// @Failure 403 {object} Problem "Sign-up is closed"
+// @Failure 409 {object} Problem "A workspace with this slug already exists"
+// @Failure 429 {object} Problem "Too many sign-ups from this client"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Router /workspaces [post]
+func (h *Handler) CreateWorkspaceHandler(c *gin.Context) {
+    var req CreateWorkspaceRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        bindError(c, err)
+        return
+    }
+    req.Slug = strings.ToLower(strings.TrimSpace(req.Slug))
+    if !ValidSlug(req.Slug) {
+        problem(c, http.StatusBadRequest, "validation_failed", "slug must be 1 to 40 lowercase letters, digits and inner hyphens")
+        return
+    }
+    if req.Admin.Password == "" {
+        problem(c, http.StatusBadRequest, "validation_failed", "admin.password is required")
+        return
+    }
+    if !hashPassword(c, &req.Admin) {
+        return
+    }
+
+    workspace, admin, err := h.store.CreateWorkspace(c.Request.Context(), models.Workspace{Name: req.Name, Slug: req.Slug}, req.Admin)
+    if err != nil {
+        storeError(c, err, "creating workspace")
+        return
+    }
+
+    c.JSON(http.StatusCreated, CreateWorkspaceResponse{Workspace: workspace, Admin: admin})
+}
+
+// GetWorkspaceHandler returns the workspace of the authenticated user
+// @Summary Get the current workspace
+// @Description Get the workspace of the authenticated user with its quotas and how much of them is used
+// @Tags workspaces
+// @Produce  json
+// @Success 200 {object} models.Workspace
+// @Failure 401 {object} Problem "Unauthorized"
+// @Failure 500 {object} Problem "Internal Server Error"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /workspace [get]
+func (h *Handler) GetWorkspaceHandler(c *gin.Context) {
+    workspace, err := h.store.GetWorkspace(c.Request.Context(), database.WorkspaceID(c.Request.Context()))
+    if err != nil {
+        storeError(c, err, "querying workspace")
+        return
+    }
+
+    c.JSON(http.StatusOK, workspace)
+}
@@ -1,19 +1,99 @@
 package main
 
 import (
+    "errors"
+    "flag"
+    "fmt"
     "log"
+    "os"
+    "github.com/maazxenon/task-api/config"
     "github.com/maazxenon/task-api/routes"
     "github.com/maazxenon/task-api/database"
+    "github.com/maazxenon/task-api/handlers"
+    "github.com/maazxenon/task-api/auth"
 )
 
+// @title Task API App
+// @version 1.0
+// @description Todo list application with Gin and SQLite or PostgreSQL
+// @host localhost:8080
+// @BasePath /
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
+// @description Access token from POST /auth/login, sent as "Bearer <token>"
+// @securityDefinitions.apikey ApiKeyAuth
+// @in header
+// @name X-API-Key
+// @description API key from POST /api-keys; "Authorization: ApiKey <key>" works too
 func main() {
+    // Settings come from the defaults, a config file, the environment and the flags before
+    // the command, e.g. task-api -config app.yaml migrate status
+    cfg, args, err := config.Load(os.Args[1:])
+    if errors.Is(err, flag.ErrHelp) {
+        fmt.Fprintf(os.Stderr, "%s\n\nflags:\n", commandUsage)
+        config.PrintFlags(os.Stderr)
+        return
+    }
+    if err != nil {
+        log.Fatal(err)
+    }
+
+    if len(args) > 0 {
+        if err := runCommand(cfg, args); err != nil {
+            log.Fatal(err)
+        }
+        return
+    }
+
     // Initialize the database
-    database.InitDB()
-    defer database.DB.Close()
+    db, dialect := database.InitDB(cfg.Database.URL)
+    defer db.Close()
+
+    authConfig, err := auth.Config{
+        Algorithm:  cfg.JWT.Algorithm,
+        Secret:     []byte(cfg.JWT.Secret),
+        Issuer:     cfg.JWT.Issuer,
+        AccessTTL:  cfg.JWT.AccessTTL.Std(),
+        RefreshTTL: cfg.JWT.RefreshTTL.Std(),
+    }.LoadKeys(cfg.JWT.PrivateKeyFile, cfg.JWT.PublicKeyFile)
+    if err != nil {
+        log.Fatalf("Invalid JWT configuration: %v", err)
+    }
+    tokens, err := auth.NewManager(authConfig)
+    if err != nil {
+        log.Fatalf("Invalid JWT configuration: %v", err)
+    }
+
+    store := database.NewSQLStore(db, dialect)
+    go runPurger(store, cfg.Retention)
 
     // Set up the router
-    r := routes.TaskRouter()
-    if err := r.Run(":8080"); err != nil {
+    h := handlers.NewHandler(store, tokens, cfg.Retention.IdempotencyKeys.Std())
+    r := routes.TaskRouter(h, cfg)
+    if err := r.Run(cfg.Server.Addr); err != nil {
         log.Fatalf("Failed to run server: %v", err)
     }
-}
\ No newline at end of file
+}
+
+const commandUsage = `usage: task-api [flags] [command]
+
+Without a command the server is started. Run task-api -h to list the flags.
+
+commands:
+  migrate      apply or roll back database migrations
+  workspace    create and list workspaces, add admins, reset passwords and change quotas
+  config       show the effective configuration`
+
+// runCommand runs the subcommand named by args[0]
+func runCommand(cfg config.Config, args []string) error {
+    switch args[0] {
+    case "migrate":
+        return runMigrate(cfg.Database.URL, args[1:])
+    case "workspace":
+        return runWorkspace(cfg.Database.URL, args[1:])
+    case "config":
+        return runConfig(cfg, args[1:])
+    }
+    return fmt.Errorf("unknown command %q\n%s", args[0], commandUsage)
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+    "context"
+    "fmt"
+    "os"
+    "strconv"
+    "text/tabwriter"
+    "github.com/maazxenon/task-api/database"
+)
+
+const migrateUsage = `usage: task-api migrate <command>
+
+commands:
+  status          list migrations and whether they are applied
+  up [version]    apply pending migrations, optionally stopping at version
+  down [steps]    roll back the last applied migration, or the last steps migrations`
+
+// runMigrate implements the migrate subcommand
+func runMigrate(dsn string, args []string) error {
+    if len(args) == 0 {
+        return fmt.Errorf("missing command\n%s", migrateUsage)
+    }
+
+    db, dialect, err := database.Open(dsn)
+    if err != nil {
+        return fmt.Errorf("connecting to %s database: %w", dialect, err)
+    }
+    defer db.Close()
+
+    migrator, err := database.NewMigrator(db, dialect)
+    if err != nil {
+        return err
+    }
+
+    ctx := context.Background()
+    switch args[0] {
+    case "status":
+        statuses, err := migrator.Status(ctx)
+        if err != nil {
+            return err
+        }
+        w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+        fmt.Fprintln(w, "VERSION\tNAME\tSTATUS")
+        for _, s := range statuses {
+            state := "pending"
+            if s.Applied {
+                state = "applied " + s.AppliedAt
+            }
+            if s.Modified {
+                state += " (checksum mismatch)"
+            }
+            fmt.Fprintf(w, "%04d\t%s\t%s\n", s.Version, s.Name, state)
+        }
+        return w.Flush()
+
+    case "up":
+        target, err := intArg(args, 0)
+        if err != nil {
+            return err
+        }
+        applied, err := migrator.Up(ctx, target)
+        for _, m := range applied {
+            fmt.Printf("applied %04d_%s\n", m.Version, m.Name)
+        }
+        if err == nil && len(applied) == 0 {
+            fmt.Println("database is up to date")
+        }
+        return err
+
+    case "down":
+        steps, err := intArg(args, 1)
+        if err != nil {
+            return err
+        }
+        rolledBack, err := migrator.Down(ctx, steps)
+        for _, m := range rolledBack {
+            fmt.Printf("rolled back %04d_%s\n", m.Version, m.Name)
+        }
+        return err
+    }
+
+    return fmt.Errorf("unknown command %q\n%s", args[0], migrateUsage)
+}
+
+// intArg parses the optional numeric argument following the command
+func intArg(args []string, def int) (int, error) {
+    if len(args) < 2 {
+        return def, nil
+    }
+    n, err := strconv.Atoi(args[1])
+    if err != nil || n < 0 {
+        return 0, fmt.Errorf("invalid number %q\n%s", args[1], migrateUsage)
+    }
+    return n, nil
+}
@@ -0,0 +1,21 @@
+package models
+
+// APIKey is a credential for machine clients acting on behalf of a user
+type APIKey struct {
+    ID   int    `json:"id" example:"1" readonly:"true"`
+    Name string `json:"name" example:"CI bot" binding:"required"`
+    // Prefix identifies the key in listings without revealing it
+    Prefix string `json:"prefix" example:"tk_3f9a1c2b" readonly:"true"`
+    // Scopes limit the key to reading (read), changing (write) or administering (admin) resources
+    Scopes []string `json:"scopes" example:"read,write" binding:"required,min=1,dive,oneof=read write admin"`
+    // ExpiresAt is when the key stops working; keys without one don't expire
+    ExpiresAt  string `json:"expires_at,omitempty" example:"2024-12-31T23:59:59Z" format:"date-time"`
+    CreatedAt  string `json:"created_at" example:"2024-01-01T09:00:00Z" format:"date-time" readonly:"true"`
+    LastUsedAt string `json:"last_used_at,omitempty" example:"2024-01-02T10:00:00Z" format:"date-time" readonly:"true"`
+    RevokedAt  string `json:"revoked_at,omitempty" format:"date-time" readonly:"true"`
+    // Key is the secret itself, only returned when the key is created
+    Key    string `json:"key,omitempty" example:"tk_3f9a1c2b_5JkQ0m9a..." readonly:"true"`
+    UserID int    `json:"user_id" example:"1" readonly:"true"`
+    // WorkspaceID is the workspace of the user, filled in when authenticating with the key
+    WorkspaceID int `json:"-"`
+}
@@ -0,0 +1,18 @@
+package models
+
+// AuditEntry records one change to a task. Entries are append-only and outlive the task.
+type AuditEntry struct {
+    ID     int `json:"id" example:"1"`
+    TaskID int `json:"task_id" example:"1"`
+    // Action is create, update, delete (moving to the trash), restore or purge
+    Action string `json:"action" example:"update" enums:"create,update,delete,restore,purge"`
+    // ActorID is the user who made the change, null for changes made without one such as purges
+    ActorID *int `json:"actor_id" example:"1" extensions:"x-nullable"`
+    // RequestID is the X-Request-ID of the request that made the change
+    RequestID string `json:"request_id" example:"9f86d081884c7d65"`
+    CreatedAt string `json:"created_at" example:"2024-01-02T10:00:00Z" format:"date-time"`
+    // Before and After are the task as it was before and after the change; Before is null
+    // for creations and After for purges
+    Before *Task `json:"before" extensions:"x-nullable"`
+    After  *Task `json:"after" extensions:"x-nullable"`
+}
@@ -0,0 +1,13 @@
+package models
+
+// Dependency records that a task cannot be completed before another one
+type Dependency struct {
+    TaskID      int `json:"task_id" example:"2"`
+    BlockedByID int `json:"blocked_by_id" example:"1"`
+}
+
+// PlannedTask is a task in dependency order together with the tasks blocking it
+type PlannedTask struct {
+    Task
+    BlockedBy []int `json:"blocked_by" example:"1"`
+}
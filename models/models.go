@@ -1,23 +1,35 @@
 package models
 
-
-var TaskTable = `
- CREATE TABLE IF NOT EXISTS tasks (
-        id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
-        title TEXT,
-        description TEXT,
-        due_date TEXT,
-        status TEXT
-    );`
-
-
-
 type Task struct {
 		ID          int    `json:"id" example:"1"`
 		Title       string `json:"title" example:"Buy groceries" binding:"required"`
 		Description string `json:"description" example:"Milk, Bread, Cheese"`
-		DueDate     string `json:"due_date" example:"2023-12-31"`
-		Status      string `json:"status" example:"pending" validate:"required,status"`
+		// DueDate is an RFC 3339 timestamp. Clients may also send a date, meaning midnight in
+		// their time zone, or a phrase such as "next friday 5pm".
+		DueDate     string `json:"due_date" example:"2023-12-31T17:00:00Z" format:"date-time" validate:"omitempty,duedate"`
+		// DueDatePhrase echoes the natural-language phrase DueDate was parsed from
+		DueDatePhrase string `json:"due_date_phrase,omitempty" example:"next friday 5pm" readonly:"true"`
+		Status      string `json:"status" example:"pending" validate:"required,status,unblocked"`
+		// Version is bumped on every write and served as the task's ETag
+		Version     int    `json:"version" example:"1"`
+		// ParentID makes the task a subtask of another task
+		ParentID    *int   `json:"parent_id" example:"1" extensions:"x-nullable"`
+		// ProjectID places the task in a project
+		ProjectID   *int   `json:"project_id" example:"1" extensions:"x-nullable"`
+		// AssigneeID is the user responsible for the task
+		AssigneeID  *int   `json:"assignee_id" example:"1" extensions:"x-nullable"`
+		// CreatedBy and UpdatedBy record the users who created and last changed the task
+		CreatedBy   *int   `json:"created_by" example:"1" extensions:"x-nullable" readonly:"true"`
+		UpdatedBy   *int   `json:"updated_by" example:"1" extensions:"x-nullable" readonly:"true"`
+		// Recurrence is an RFC 5545 RRULE; completing the task creates the next occurrence
+		Recurrence  string `json:"recurrence" example:"FREQ=WEEKLY;BYDAY=MO" validate:"omitempty,rrule"`
+		// Tags are the names of the tags the task carries. Unknown tags are created on write and
+		// omitting the field on PUT keeps the current tags.
+		Tags        []string `json:"tags" example:"errands,home" validate:"omitempty,max=20,dive,tagname"`
+		// NextOccurrenceID points at the task created when this recurring task was completed
+		NextOccurrenceID *int `json:"next_occurrence_id" example:"2" extensions:"x-nullable" readonly:"true"`
+		// DeletedAt is when the task was moved to the trash, empty for live tasks
+		DeletedAt string `json:"deleted_at,omitempty" example:"2024-01-05T09:00:00Z" format:"date-time" readonly:"true"`
 }
 	
 
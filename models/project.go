@@ -0,0 +1,16 @@
+package models
+
+// Project groups tasks. Archiving a project archives its tasks with it: they drop out of
+// listings and can't be changed until the project is unarchived.
+type Project struct {
+    ID          int    `json:"id" example:"1"`
+    Name        string `json:"name" example:"Home" binding:"required"`
+    Description string `json:"description" example:"Chores and errands"`
+    // DefaultStatus is given to tasks created in the project without a status
+    DefaultStatus string `json:"default_status" example:"pending" validate:"omitempty,status"`
+    // ArchivedAt is when the project was archived, empty while it is active
+    ArchivedAt string `json:"archived_at,omitempty" example:"2024-01-05T09:00:00Z" format:"date-time" readonly:"true"`
+    // TaskCount is the number of tasks in the project
+    TaskCount int `json:"task_count" example:"12" readonly:"true"`
+}
+
@@ -0,0 +1,13 @@
+package models
+
+// TaskMatch is a task returned by full-text search
+type TaskMatch struct {
+    Task Task `json:"task"`
+    // Rank orders results; higher is a better match
+    Rank float64 `json:"rank" example:"4.2"`
+    // TitleHighlight is the HTML-escaped title with matched words wrapped in <mark> tags
+    TitleHighlight string `json:"title_highlight" example:"Buy <mark>groceries</mark>"`
+    // Snippet is the best matching fragment of the description, HTML-escaped, with matched
+    // words wrapped in <mark> tags
+    Snippet string `json:"snippet" example:"Milk, <mark>Bread</mark>, Cheese"`
+}
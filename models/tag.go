@@ -0,0 +1,9 @@
+package models
+
+// Tag is a label used to categorize tasks
+type Tag struct {
+    ID   int    `json:"id" example:"1"`
+    Name string `json:"name" example:"errands" binding:"required" validate:"tagname"`
+    // TaskCount is the number of tasks carrying the tag
+    TaskCount int `json:"task_count" example:"3" readonly:"true"`
+}
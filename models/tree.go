@@ -0,0 +1,10 @@
+package models
+
+// TaskNode is a task together with its subtasks
+type TaskNode struct {
+    Task
+    // Progress is the completion percentage: 100 or 0 for a task without subtasks depending
+    // on whether it is completed, otherwise the average progress of its direct subtasks
+    Progress float64     `json:"progress" example:"50"`
+    Children []*TaskNode `json:"children"`
+}
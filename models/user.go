@@ -0,0 +1,17 @@
+package models
+
+// User is a person tasks can be created by and assigned to
+type User struct {
+    ID    int    `json:"id" example:"1"`
+    Name  string `json:"name" example:"Ada Lovelace" binding:"required"`
+    Email string `json:"email" example:"ada@example.com" binding:"required,email"`
+    // Role decides what the user may change. The first user becomes an admin and later ones
+    // members; admins change roles through PUT /users/{id}/role.
+    Role string `json:"role" example:"member" enums:"viewer,member,admin" readonly:"true"`
+    // Password is write-only; it is required to log in and kept unchanged when omitted on update
+    Password string `json:"password,omitempty" example:"correct horse battery staple" binding:"omitempty,min=8"`
+    // CurrentPassword is write-only; users changing their own email or password confirm it
+    CurrentPassword string `json:"current_password,omitempty" example:"old horse battery staple"`
+    // PasswordHash is the bcrypt hash of the password
+    PasswordHash string `json:"-"`
+}
@@ -0,0 +1,26 @@
+package models
+
+// Workspace is a tenant: a team whose users, tasks, projects and tags are invisible to
+// every other workspace
+type Workspace struct {
+    ID   int    `json:"id" example:"1" readonly:"true"`
+    Name string `json:"name" example:"Acme" binding:"required"`
+    // Slug selects the workspace in the X-Workspace header or as a subdomain
+    Slug   string          `json:"slug" example:"acme" binding:"required"`
+    Quotas WorkspaceQuotas `json:"quotas" readonly:"true"`
+    Usage  WorkspaceUsage  `json:"usage" readonly:"true"`
+}
+
+// WorkspaceQuotas are the most users, projects and tasks a workspace may have; 0 is unlimited
+type WorkspaceQuotas struct {
+    MaxUsers    int `json:"max_users" example:"50"`
+    MaxProjects int `json:"max_projects" example:"100"`
+    MaxTasks    int `json:"max_tasks" example:"10000"`
+}
+
+// WorkspaceUsage counts what a workspace currently holds
+type WorkspaceUsage struct {
+    Users    int `json:"users" example:"3"`
+    Projects int `json:"projects" example:"2"`
+    Tasks    int `json:"tasks" example:"42"`
+}
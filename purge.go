@@ -0,0 +1,31 @@
+package main
+
+import (
+    "context"
+    "log"
+    "time"
+    "github.com/maazxenon/task-api/config"
+    "github.com/maazxenon/task-api/database"
+)
+
+// runPurger permanently removes the tasks that have been in the trash for longer than the
+// retention and the expired idempotency keys, once at startup and then every interval
+func runPurger(store database.Store, retention config.Retention) {
+    ticker := time.NewTicker(retention.PurgeInterval.Std())
+    defer ticker.Stop()
+    for {
+        ctx := context.Background()
+        if retention.Trash > 0 {
+            purged, err := store.PurgeTrash(ctx, time.Now().Add(-retention.Trash.Std()))
+            if err != nil {
+                log.Printf("Error purging trash: %v", err)
+            } else if purged > 0 {
+                log.Printf("Purged %d tasks from the trash", purged)
+            }
+        }
+        if _, err := store.PurgeIdempotencyKeys(ctx, time.Now().Add(-retention.IdempotencyKeys.Std())); err != nil {
+            log.Printf("Error purging idempotency keys: %v", err)
+        }
+        <-ticker.C
+    }
+}
@@ -0,0 +1,91 @@
+package recurrence
+
+import (
+    "errors"
+    "strings"
+    "time"
+    "github.com/maazxenon/task-api/dates"
+    "github.com/teambition/rrule-go"
+)
+
+// ErrInvalidDueDate is returned by Next when the due date of a recurring task can't be parsed
+var ErrInvalidDueDate = errors.New("due date of a recurring task must be a date or an RFC 3339 timestamp")
+
+// Occurrence is the next task of a recurring series
+type Occurrence struct {
+    DueDate string
+    // Rule is the recurrence the next task carries; COUNT is decremented so it keeps
+    // counting the occurrences left in the series
+    Rule string
+}
+
+// Parse reads an RFC 5545 RRULE value such as FREQ=WEEKLY;BYDAY=MO,TH;COUNT=4, with or
+// without the RRULE: prefix. DTSTART isn't accepted; the task's due date serves as the start.
+func Parse(rule string) (*rrule.ROption, error) {
+    return parseInLocation(rule, time.UTC)
+}
+
+func parseInLocation(rule string, loc *time.Location) (*rrule.ROption, error) {
+    rule = strings.TrimSpace(rule)
+    if strings.Contains(rule, "\n") || strings.Contains(rule, "DTSTART") {
+        return nil, errors.New("DTSTART is not supported, the due date starts the series")
+    }
+
+    opt, err := rrule.StrToROptionInLocation(rule, loc)
+    if err != nil {
+        return nil, err
+    }
+    if opt.Count < 0 || opt.Interval < 0 {
+        return nil, errors.New("COUNT and INTERVAL must be positive")
+    }
+    if _, err := rrule.NewRRule(*opt); err != nil {
+        return nil, err
+    }
+    return opt, nil
+}
+
+// Next computes the occurrence following one due on due, a stored due date in UTC. The
+// rule is evaluated in loc so BYDAY and BYHOUR follow the client's calendar. Tasks without
+// a due date recur from the start of the current day. ok is false when the series is over
+// because COUNT is exhausted or UNTIL has passed.
+func Next(rule, due string, loc *time.Location, now time.Time) (next Occurrence, ok bool, err error) {
+    var start time.Time
+    if due == "" {
+        y, m, d := now.In(loc).Date()
+        start = time.Date(y, m, d, 0, 0, 0, 0, loc)
+    } else if start, err = dates.Parse(due, loc); err != nil {
+        return next, false, ErrInvalidDueDate
+    }
+    start = start.In(loc)
+
+    opt, err := parseInLocation(rule, start.Location())
+    if err != nil {
+        return next, false, err
+    }
+
+    // COUNT is tracked per task: this one is an occurrence, so COUNT=1 means it was the last
+    remaining := opt.Count
+    if remaining == 1 {
+        return next, false, nil
+    }
+
+    opt.Count = 0
+    opt.Dtstart = start
+    r, err := rrule.NewRRule(*opt)
+    if err != nil {
+        return next, false, err
+    }
+
+    after := r.After(start, false)
+    if after.IsZero() {
+        return next, false, nil
+    }
+
+    next.DueDate = dates.Format(after)
+    next.Rule = rule
+    if remaining > 1 {
+        opt.Count = remaining - 1
+        next.Rule = opt.RRuleString()
+    }
+    return next, true, nil
+}
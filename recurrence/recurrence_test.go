@@ -0,0 +1,145 @@
+package recurrence
+
+import (
+    "errors"
+    "testing"
+    "time"
+)
+
+func TestParse(t *testing.T) {
+    tests := []struct {
+        rule    string
+        wantErr bool
+    }{
+        {"FREQ=DAILY", false},
+        {"RRULE:FREQ=WEEKLY;BYDAY=MO,TH;COUNT=4", false},
+        {"  FREQ=MONTHLY;BYMONTHDAY=-1;INTERVAL=2 ", false},
+        {"FREQ=YEARLY;UNTIL=20301231T000000Z", false},
+        {"", true},
+        {"FREQ=SOMETIMES", true},
+        {"BYDAY=MO", true},
+        {"FREQ=DAILY;COUNT=-1", true},
+        {"FREQ=DAILY;INTERVAL=-2", true},
+        {"DTSTART:20240101T000000Z\nRRULE:FREQ=DAILY", true},
+        {"FREQ=DAILY;DTSTART=20240101T000000Z", true},
+    }
+    for _, tt := range tests {
+        t.Run(tt.rule, func(t *testing.T) {
+            _, err := Parse(tt.rule)
+            if (err != nil) != tt.wantErr {
+                t.Errorf("Parse(%q) error = %v, wantErr %v", tt.rule, err, tt.wantErr)
+            }
+        })
+    }
+}
+
+func TestNext(t *testing.T) {
+    now := time.Date(2024, time.May, 15, 10, 30, 0, 0, time.UTC)
+    // Thursday morning in UTC+10 is still Wednesday evening in UTC
+    brisbane := time.FixedZone("UTC+10", 10*60*60)
+
+    tests := []struct {
+        name    string
+        rule    string
+        due     string
+        loc     *time.Location
+        want    Occurrence
+        wantOK  bool
+        wantErr error
+    }{
+        {
+            name:   "daily",
+            rule:   "FREQ=DAILY",
+            due:    "2024-05-15T09:00:00Z",
+            want:   Occurrence{DueDate: "2024-05-16T09:00:00Z", Rule: "FREQ=DAILY"},
+            wantOK: true,
+        },
+        {
+            name:   "interval",
+            rule:   "FREQ=WEEKLY;INTERVAL=2",
+            due:    "2024-05-15T09:00:00Z",
+            want:   Occurrence{DueDate: "2024-05-29T09:00:00Z", Rule: "FREQ=WEEKLY;INTERVAL=2"},
+            wantOK: true,
+        },
+        {
+            name:   "next weekday in the list",
+            rule:   "FREQ=WEEKLY;BYDAY=MO,TH",
+            due:    "2024-05-16T09:00:00Z",
+            want:   Occurrence{DueDate: "2024-05-20T09:00:00Z", Rule: "FREQ=WEEKLY;BYDAY=MO,TH"},
+            wantOK: true,
+        },
+        {
+            name:   "weekdays follow the client's calendar",
+            rule:   "FREQ=WEEKLY;BYDAY=TH",
+            due:    "2024-05-15T20:00:00Z",
+            loc:    brisbane,
+            want:   Occurrence{DueDate: "2024-05-22T20:00:00Z", Rule: "FREQ=WEEKLY;BYDAY=TH"},
+            wantOK: true,
+        },
+        {
+            name:   "end of month",
+            rule:   "FREQ=MONTHLY;BYMONTHDAY=-1",
+            due:    "2024-01-31",
+            want:   Occurrence{DueDate: "2024-02-29T00:00:00Z", Rule: "FREQ=MONTHLY;BYMONTHDAY=-1"},
+            wantOK: true,
+        },
+        {
+            name:   "count is decremented",
+            rule:   "FREQ=DAILY;COUNT=3",
+            due:    "2024-05-15T09:00:00Z",
+            want:   Occurrence{DueDate: "2024-05-16T09:00:00Z", Rule: "FREQ=DAILY;COUNT=2"},
+            wantOK: true,
+        },
+        {
+            name: "last of the count",
+            rule: "FREQ=DAILY;COUNT=1",
+            due:  "2024-05-15T09:00:00Z",
+        },
+        {
+            name: "until has passed",
+            rule: "FREQ=DAILY;UNTIL=20240515T235959Z",
+            due:  "2024-05-15T09:00:00Z",
+        },
+        {
+            name:   "without a due date",
+            rule:   "FREQ=DAILY",
+            want:   Occurrence{DueDate: "2024-05-16T00:00:00Z", Rule: "FREQ=DAILY"},
+            wantOK: true,
+        },
+        {
+            name:   "without a due date in the client's time zone",
+            rule:   "FREQ=DAILY",
+            loc:    brisbane,
+            want:   Occurrence{DueDate: "2024-05-15T14:00:00Z", Rule: "FREQ=DAILY"},
+            wantOK: true,
+        },
+        {
+            name:    "free-form due date",
+            rule:    "FREQ=DAILY",
+            due:     "someday",
+            wantErr: ErrInvalidDueDate,
+        },
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            loc := tt.loc
+            if loc == nil {
+                loc = time.UTC
+            }
+
+            got, ok, err := Next(tt.rule, tt.due, loc, now)
+            if !errors.Is(err, tt.wantErr) {
+                t.Fatalf("Next error = %v, want %v", err, tt.wantErr)
+            }
+            if ok != tt.wantOK || got != tt.want {
+                t.Errorf("Next = %+v, %v, want %+v, %v", got, ok, tt.want, tt.wantOK)
+            }
+        })
+    }
+}
+
+func TestNextInvalidRule(t *testing.T) {
+    if _, _, err := Next("FREQ=SOMETIMES", "2024-05-15", time.UTC, time.Now()); err == nil {
+        t.Error("Next accepted an invalid rule")
+    }
+}
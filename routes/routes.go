@@ -4,23 +4,39 @@ import (
     "github.com/gin-gonic/gin"
     httpSwagger "github.com/swaggo/http-swagger"
     "github.com/maazxenon/task-api/handlers"
-    "time"
+    "github.com/maazxenon/task-api/auth"
+    "github.com/maazxenon/task-api/config"
     "github.com/gin-contrib/cors"
+    "time"
 )
 
-// TaskRouter returns a new router
-func TaskRouter() *gin.Engine {
+// TaskRouter returns a new router serving the task endpoints through h. Requests to a
+// subdomain of the workspace domain are scoped to the workspace of that slug.
+func TaskRouter(h *handlers.Handler, cfg config.Config) *gin.Engine {
     r := gin.Default()
-    config := cors.DefaultConfig()
-    config.AllowAllOrigins = true
-    config.AllowMethods = []string{"POST", "GET", "PUT", "OPTIONS", "DELETE"}
-    config.AllowHeaders = []string{"Origin", "Content-Type", "Authorization", "Accept", "User-Agent", "Cache-Control", "Pragma"}
-    config.ExposeHeaders = []string{"Content-Length"}
-    config.AllowCredentials = true
-    config.MaxAge = 12 * time.Hour
+    // Validated at startup
+    r.SetTrustedProxies(cfg.Server.TrustedProxies)
+
+    // Without allowed origins browsers may only call the API from the page it serves
+    if len(cfg.CORS.AllowedOrigins) > 0 {
+        corsConfig := cors.DefaultConfig()
+        if cfg.CORS.AllowedOrigins[0] == "*" {
+            corsConfig.AllowAllOrigins = true
+        } else {
+            corsConfig.AllowOrigins = cfg.CORS.AllowedOrigins
+        }
+        corsConfig.AllowMethods = []string{"POST", "GET", "PUT", "PATCH", "OPTIONS", "DELETE"}
+        corsConfig.AllowHeaders = []string{"Origin", "Content-Type", "Authorization", "Accept", "User-Agent", "Cache-Control", "Pragma", "If-Match", "If-None-Match", handlers.TimezoneHeader, handlers.ReferenceTimeHeader, handlers.APIKeyHeader, handlers.WorkspaceHeader, handlers.RequestIDHeader, handlers.IdempotencyKeyHeader}
+        corsConfig.ExposeHeaders = []string{"Content-Length", "ETag", "Link", handlers.RequestIDHeader, handlers.IdempotentReplayedHeader}
+        corsConfig.AllowCredentials = cfg.CORS.AllowCredentials
+        corsConfig.MaxAge = cfg.CORS.MaxAge.Std()
+        r.Use(cors.New(corsConfig))
+    }
 
-    r.Use(cors.New(config))
-    r.Use(gin.Recovery()) // Add recovery middleware
+    r.Use(gin.CustomRecovery(handlers.Recovered)) // Add recovery middleware
+    r.Use(handlers.RequestID())
+    r.Use(handlers.Timezone())
+    r.Use(h.ResolveWorkspace(cfg.Server.WorkspaceDomain))
 
     // Serve static files
     r.Static("/static", "./static")
@@ -33,14 +49,74 @@ func TaskRouter() *gin.Engine {
 
 
 
+    // Unknown paths get a problem+json 404 like every other error
+    r.NoRoute(handlers.RouteNotFound)
+
     // Serve Swagger UI
     r.GET("/swagger/*any", gin.WrapH(httpSwagger.WrapHandler))
 
-    r.GET("/tasks", handlers.IndexHandler)
-    r.POST("/tasks", handlers.CreateHandler)
-    r.GET("/tasks/:id", handlers.GetTaskHandler)
-    r.PUT("/tasks/:id", handlers.UpdateTaskHandler)
-    r.DELETE("/tasks/:id", handlers.DeleteHandler)
+    // Public endpoints: health, login and sign-up of new workspaces
+    r.GET("/health", h.HealthHandler)
+    r.POST("/auth/login", h.LoginHandler)
+    r.POST("/auth/refresh", h.RefreshHandler)
+    r.POST("/workspaces", handlers.RateLimit(cfg.Server.SignupRateLimit, time.Hour), h.Signup(cfg.Server.OpenSignup), h.CreateWorkspaceHandler)
+
+    // Everything else requires an access token or API key. Reads are open to every role;
+    // changes are checked against the caller's role here and per task in the handlers.
+    // Every query only sees the workspace of the credentials.
+    api := r.Group("", h.Authenticate())
+
+    api.GET("/workspace", h.GetWorkspaceHandler)
+
+    api.GET("/tasks", h.IndexHandler)
+    api.POST("/tasks", handlers.Require(auth.CreateTasks), h.CreateHandler)
+    api.POST("/tasks/bulk", h.BulkHandler)
+    api.GET("/tasks/search", h.SearchHandler)
+    api.GET("/tasks/plan", h.PlanHandler)
+    api.GET("/tasks/:id", h.GetTaskHandler)
+    api.PUT("/tasks/:id", h.UpdateTaskHandler)
+    api.PATCH("/tasks/:id", h.PatchTaskHandler)
+    api.DELETE("/tasks/:id", h.DeleteHandler)
+    api.GET("/tasks/:id/children", h.ChildrenHandler)
+    api.GET("/tasks/:id/tree", h.TreeHandler)
+    api.GET("/tasks/:id/dependencies", h.DependenciesHandler)
+    api.POST("/tasks/:id/dependencies", h.AddDependencyHandler)
+    api.DELETE("/tasks/:id/dependencies/:blocker_id", h.RemoveDependencyHandler)
+    api.GET("/tasks/:id/history", h.TaskHistoryHandler)
+    api.POST("/tasks/:id/restore", h.RestoreHandler)
+    api.GET("/trash", h.TrashHandler)
+    api.GET("/audit", handlers.Require(auth.ReadAuditLog), h.AuditLogHandler)
+
+    api.GET("/tags", h.ListTagsHandler)
+    api.POST("/tags", handlers.Require(auth.WriteTags), h.CreateTagHandler)
+    api.GET("/tags/:id", h.GetTagHandler)
+    api.PUT("/tags/:id", handlers.Require(auth.WriteTags), h.UpdateTagHandler)
+    api.DELETE("/tags/:id", handlers.Require(auth.DeleteTags), h.DeleteTagHandler)
+
+    api.GET("/projects", h.ListProjectsHandler)
+    api.POST("/projects", handlers.Require(auth.WriteProjects), h.CreateProjectHandler)
+    api.GET("/projects/:id", h.GetProjectHandler)
+    api.PUT("/projects/:id", handlers.Require(auth.WriteProjects), h.UpdateProjectHandler)
+    api.DELETE("/projects/:id", handlers.Require(auth.DeleteProjects), h.DeleteProjectHandler)
+    api.POST("/projects/:id/archive", handlers.Require(auth.WriteProjects), h.ArchiveProjectHandler)
+    api.POST("/projects/:id/unarchive", handlers.Require(auth.WriteProjects), h.UnarchiveProjectHandler)
+    api.GET("/projects/:id/tasks", h.ProjectTasksHandler)
+    api.POST("/projects/:id/tasks", handlers.Require(auth.CreateTasks), h.CreateProjectTaskHandler)
+    api.POST("/projects/:id/tasks/move", handlers.Require(auth.WriteProjects), h.MoveTasksHandler)
+
+    api.GET("/users", h.ListUsersHandler)
+    api.POST("/users", handlers.RequireScope(auth.ScopeAdmin), handlers.Require(auth.ManageUsers), h.CreateUserHandler)
+    api.GET("/users/:id", h.GetUserHandler)
+    api.PUT("/users/:id", handlers.RequireScope(auth.ScopeAdmin), h.UpdateUserHandler)
+    api.DELETE("/users/:id", handlers.RequireScope(auth.ScopeAdmin), handlers.Require(auth.ManageUsers), h.DeleteUserHandler)
+    api.PUT("/users/:id/role", handlers.RequireScope(auth.ScopeAdmin), handlers.Require(auth.ManageUsers), h.SetUserRoleHandler)
+    api.GET("/users/:id/tasks", h.UserTasksHandler)
+
+    // Managing keys needs the admin scope, so a leaked read or write key can't mint new ones
+    keys := api.Group("/api-keys", handlers.RequireScope(auth.ScopeAdmin))
+    keys.GET("", h.ListAPIKeysHandler)
+    keys.POST("", h.CreateAPIKeyHandler)
+    keys.DELETE("/:id", h.RevokeAPIKeyHandler)
 
     return r
 }
\ No newline at end of file
@@ -0,0 +1,174 @@
+package main
+
+import (
+    "bufio"
+    "context"
+    "fmt"
+    "io"
+    "os"
+    "strconv"
+    "strings"
+    "text/tabwriter"
+    "github.com/maazxenon/task-api/auth"
+    "github.com/maazxenon/task-api/database"
+    "github.com/maazxenon/task-api/handlers"
+    "github.com/maazxenon/task-api/models"
+)
+
+const workspaceUsage = `usage: task-api workspace <command>
+
+commands:
+  list                                   list workspaces with their quotas and usage
+  create <slug> <name> <admin email> <admin name>
+                                         create a workspace and its admin, reading the
+                                         admin's password from the first line of stdin
+  add-admin <slug> <email> <name>        add an admin to an existing workspace, such as the
+                                         default workspace of an upgraded database, reading
+                                         the password from the first line of stdin
+  set-password <slug> <email>            reset the password of a user, such as one created
+                                         before passwords existed, reading it from the
+                                         first line of stdin
+  quota <slug> <users|projects|tasks> <limit>
+                                         change a quota of a workspace; 0 lifts it`
+
+// runWorkspace implements the workspace subcommand
+func runWorkspace(dsn string, args []string) error {
+    if len(args) == 0 {
+        return fmt.Errorf("missing command\n%s", workspaceUsage)
+    }
+
+    db, dialect, err := database.Open(dsn)
+    if err != nil {
+        return fmt.Errorf("connecting to %s database: %w", dialect, err)
+    }
+    defer db.Close()
+    store := database.NewSQLStore(db, dialect)
+
+    ctx := context.Background()
+    switch args[0] {
+    case "list":
+        workspaces, err := store.Workspaces(ctx)
+        if err != nil {
+            return err
+        }
+        w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+        fmt.Fprintln(w, "ID\tSLUG\tNAME\tUSERS\tPROJECTS\tTASKS")
+        for _, ws := range workspaces {
+            fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\n", ws.ID, ws.Slug, ws.Name,
+                usage(ws.Usage.Users, ws.Quotas.MaxUsers), usage(ws.Usage.Projects, ws.Quotas.MaxProjects), usage(ws.Usage.Tasks, ws.Quotas.MaxTasks))
+        }
+        return w.Flush()
+
+    case "create":
+        if len(args) != 5 {
+            return fmt.Errorf("create needs a slug, a name, and the email and name of the admin\n%s", workspaceUsage)
+        }
+        slug := strings.ToLower(args[1])
+        if !handlers.ValidSlug(slug) {
+            return fmt.Errorf("invalid slug %q: use 1 to 40 lowercase letters, digits and inner hyphens", args[1])
+        }
+        hash, err := readPassword()
+        if err != nil {
+            return err
+        }
+
+        ws, admin, err := store.CreateWorkspace(ctx, models.Workspace{Name: args[2], Slug: slug},
+            models.User{Email: args[3], Name: args[4], PasswordHash: hash})
+        if err != nil {
+            return err
+        }
+        fmt.Printf("created workspace %s (%d) with admin %s (%d)\n", ws.Slug, ws.ID, admin.Email, admin.ID)
+        return nil
+
+    case "add-admin":
+        if len(args) != 4 {
+            return fmt.Errorf("add-admin needs a slug and the email and name of the admin\n%s", workspaceUsage)
+        }
+        ws, err := store.WorkspaceBySlug(ctx, args[1])
+        if err != nil {
+            return err
+        }
+        hash, err := readPassword()
+        if err != nil {
+            return err
+        }
+
+        admin, err := store.CreateUser(database.WithWorkspace(ctx, ws.ID),
+            models.User{Email: args[2], Name: args[3], Role: auth.RoleAdmin, PasswordHash: hash})
+        if err != nil {
+            return err
+        }
+        fmt.Printf("added admin %s (%d) to workspace %s\n", admin.Email, admin.ID, ws.Slug)
+        return nil
+
+    case "set-password":
+        if len(args) != 3 {
+            return fmt.Errorf("set-password needs a slug and the email of the user\n%s", workspaceUsage)
+        }
+        ws, err := store.WorkspaceBySlug(ctx, args[1])
+        if err != nil {
+            return err
+        }
+        ctx = database.WithWorkspace(ctx, ws.ID)
+        user, err := store.UserByEmail(ctx, args[2])
+        if err != nil {
+            return err
+        }
+        if user.PasswordHash, err = readPassword(); err != nil {
+            return err
+        }
+
+        if _, err := store.UpdateUser(ctx, user); err != nil {
+            return err
+        }
+        fmt.Printf("set the password of %s (%d) in workspace %s\n", user.Email, user.ID, ws.Slug)
+        return nil
+
+    case "quota":
+        if len(args) != 4 {
+            return fmt.Errorf("quota needs a slug, a resource and a limit\n%s", workspaceUsage)
+        }
+        limit, err := strconv.Atoi(args[3])
+        if err != nil {
+            return fmt.Errorf("invalid number %q\n%s", args[3], workspaceUsage)
+        }
+        ws, err := store.WorkspaceBySlug(ctx, args[1])
+        if err != nil {
+            return err
+        }
+        if ws, err = store.SetQuota(ctx, ws.ID, args[2], limit); err != nil {
+            return err
+        }
+        printQuotas(ws)
+        return nil
+    }
+
+    return fmt.Errorf("unknown command %q\n%s", args[0], workspaceUsage)
+}
+
+// readPassword reads a password from the first line of stdin and hashes it
+func readPassword() (string, error) {
+    password, err := bufio.NewReader(os.Stdin).ReadString('\n')
+    if err != nil && err != io.EOF {
+        return "", err
+    }
+    password = strings.TrimRight(password, "\r\n")
+    if len(password) < 8 {
+        return "", fmt.Errorf("the password on stdin must have at least 8 characters")
+    }
+    return auth.HashPassword(password)
+}
+
+// usage renders how much of a quota is used
+func usage(used, limit int) string {
+    if limit == 0 {
+        return strconv.Itoa(used) + "/unlimited"
+    }
+    return strconv.Itoa(used) + "/" + strconv.Itoa(limit)
+}
+
+// printQuotas prints the quotas of ws after a change
+func printQuotas(ws models.Workspace) {
+    fmt.Printf("%s: users %s, projects %s, tasks %s\n", ws.Slug,
+        usage(ws.Usage.Users, ws.Quotas.MaxUsers), usage(ws.Usage.Projects, ws.Quotas.MaxProjects), usage(ws.Usage.Tasks, ws.Quotas.MaxTasks))
+}